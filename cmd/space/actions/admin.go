@@ -0,0 +1,41 @@
+package actions
+
+import (
+	"github.com/fox-gonic/fox/engine"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// CheckPageTreeArgs check page tree args
+type CheckPageTreeArgs struct {
+	SpaceKey string `json:"space_key"`
+	Repair   bool   `json:"repair"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// CheckPageTree check and repair the page tree nested set
+// POST /api/admin/fsck
+func (actions *Actions) CheckPageTree(c *engine.Context, args *CheckPageTreeArgs) (*models.TreeReport, error) {
+
+	var spaceID int64
+
+	if args.SpaceKey != "" {
+		space, err := actions.Spacer.DescribeSpace(c, &params.DescribeSpace{
+			Key:    args.SpaceKey,
+			Viewer: &params.Viewer{Unrestricted: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		spaceID = space.ID
+	}
+
+	var params = &params.CheckPageTree{
+		SpaceID: spaceID,
+		Repair:  args.Repair,
+		DryRun:  args.DryRun,
+	}
+
+	return actions.Spacer.CheckPageTree(c, params)
+}
@@ -0,0 +1,100 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/fox-gonic/fox/engine"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces"
+	"github.com/miclle/space/spaces/params"
+)
+
+// ----------------------------------------------------------------------------
+
+// CreateAttachment upload a page attachment, the multipart form file field is `file`
+// POST /api/spaces/:key/pages/:id/attachments
+func (actions *Actions) CreateAttachment(c *engine.Context) (*models.Attachment, error) {
+
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return nil, err
+	}
+
+	var (
+		account = c.MustGet("account").(*models.Account)
+		space   = c.MustGet("space").(*models.Space)
+		page    = c.MustGet("page").(*models.Page)
+	)
+
+	// leave room for the multipart boundaries and headers
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, spaces.AttachmentMaxSize+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, spaces.ErrAttachmentIsInvalid
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var params = &params.CreateAttachment{
+		SpaceID:     space.ID,
+		PageID:      page.ID,
+		CreatorID:   account.ID,
+		Name:        header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Reader:      file,
+	}
+
+	return actions.Spacer.CreateAttachment(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribeAttachments describe the attachments of the page
+// GET /api/spaces/:key/pages/:id/attachments
+func (actions *Actions) DescribeAttachments(c *engine.Context) ([]*models.Attachment, error) {
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		page   = c.MustGet("page").(*models.Page)
+		params = &params.DescribeAttachments{
+			SpaceID: space.ID,
+			PageID:  page.ID,
+		}
+	)
+
+	return actions.Spacer.DescribeAttachments(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DeleteAttachmentArgs delete attachment args
+type DeleteAttachmentArgs struct {
+	ID int64 `uri:"attachment_id"`
+}
+
+// DeleteAttachment delete a page attachment
+// DELETE /api/spaces/:key/pages/:id/attachments/:attachment_id
+func (actions *Actions) DeleteAttachment(c *engine.Context, args *DeleteAttachmentArgs) error {
+
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		page   = c.MustGet("page").(*models.Page)
+		params = &params.DeleteAttachment{
+			ID:      args.ID,
+			SpaceID: space.ID,
+			PageID:  page.ID,
+		}
+	)
+
+	return actions.Spacer.DeleteAttachment(c, params)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/samber/lo"
 
 	"github.com/miclle/space/accounts/params"
+	"github.com/miclle/space/models"
 )
 
 // SessionAccountKey session account context key
@@ -76,3 +77,20 @@ func (actions *Actions) AuthMiddleware(c *engine.Context) (res interface{}) {
 		Location: "/signin",
 	}
 }
+
+// AdminMiddleware administrator verification middleware
+func (actions *Actions) AdminMiddleware(c *engine.Context) error {
+
+	account := c.MustGet("account").(*models.Account)
+
+	if !actions.isAdmin(account) {
+		return httperrors.ErrForbidden
+	}
+
+	return nil
+}
+
+// isAdmin return the account is a configured administrator
+func (actions *Actions) isAdmin(account *models.Account) bool {
+	return lo.Contains(actions.Configuration.Admins, account.Login)
+}
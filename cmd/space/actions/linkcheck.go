@@ -0,0 +1,90 @@
+package actions
+
+import (
+	"github.com/fox-gonic/fox/database"
+	"github.com/fox-gonic/fox/engine"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// ----------------------------------------------------------------------------
+
+// CreateLinkCheckArgs create link check args
+type CreateLinkCheckArgs struct {
+	External bool `json:"external"` // check the external urls too
+}
+
+// CreateLinkCheck start a broken link check of the space, the result is described by the check id
+// POST /api/spaces/:key/linkcheck
+func (actions *Actions) CreateLinkCheck(c *engine.Context, args *CreateLinkCheckArgs) (*models.LinkCheck, error) {
+
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return nil, err
+	}
+
+	var (
+		space   = c.MustGet("space").(*models.Space)
+		account = c.MustGet("account").(*models.Account)
+		params  = &params.CreateLinkCheck{
+			SpaceID:   space.ID,
+			CreatorID: account.ID,
+			External:  args.External,
+		}
+	)
+
+	return actions.Spacer.CreateLinkCheck(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribeLinkChecksArgs describe link checks args
+type DescribeLinkChecksArgs struct {
+	database.Pagination[*models.LinkCheck]
+}
+
+// DescribeLinkChecks describe the link checks of the space, latest first
+// GET /api/spaces/:key/linkcheck
+func (actions *Actions) DescribeLinkChecks(c *engine.Context, args *DescribeLinkChecksArgs) (*database.Pagination[*models.LinkCheck], error) {
+
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return nil, err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DescribeLinkChecks{
+			Pagination: args.Pagination,
+			SpaceID:    space.ID,
+		}
+	)
+
+	return actions.Spacer.DescribeLinkChecks(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribeLinkCheckArgs describe link check args
+type DescribeLinkCheckArgs struct {
+	ID int64 `uri:"check_id"`
+}
+
+// DescribeLinkCheck describe the link check and its broken links
+// GET /api/spaces/:key/linkcheck/:check_id
+func (actions *Actions) DescribeLinkCheck(c *engine.Context, args *DescribeLinkCheckArgs) (*models.LinkCheck, error) {
+
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return nil, err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DescribeLinkCheck{
+			ID:      args.ID,
+			SpaceID: space.ID,
+			Viewer:  pageViewer(c),
+		}
+	)
+
+	return actions.Spacer.DescribeLinkCheck(c, params)
+}
@@ -4,32 +4,45 @@ import (
 	"html/template"
 
 	"github.com/fox-gonic/fox/engine"
-	"github.com/gin-gonic/gin/render"
 
-	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
 )
 
-// PreviewMarkdownArgs create space args
+// PreviewMarkdownArgs preview markdown args, the page or the parent of a new page
 type PreviewMarkdownArgs struct {
-	Content string `json:"content"`
+	Content  string `json:"content"`
+	PageID   int64  `json:"page_id"`
+	ParentID int64  `json:"parent_id"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
 }
 
-// PreviewMarkdown markdown to html
-func (actions *Actions) PreviewMarkdown(c *engine.Context, args *PreviewMarkdownArgs) (render.HTML, error) {
+// PreviewMarkdown markdown to html, the variables and the includes of the space resolve as on save
+// POST /api/spaces/:key/markdown/preview
+func (actions *Actions) PreviewMarkdown(c *engine.Context, args *PreviewMarkdownArgs) (template.HTML, error) {
 
-	result, err := markdown.Parse(args.Content)
-	if err != nil {
-		return render.HTML{}, err
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return "", err
 	}
 
-	tmpl, err := template.New("markdown").Parse(result)
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.PreviewMarkdown{
+			SpaceID:  space.ID,
+			PageID:   args.PageID,
+			ParentID: args.ParentID,
+			Lang:     args.Lang,
+			Version:  args.Version,
+			Body:     args.Content,
+		}
+	)
+
+	html, err := actions.Spacer.PreviewMarkdown(c, params)
 	if err != nil {
-		return render.HTML{}, err
-	}
-
-	var html = render.HTML{
-		Template: tmpl,
+		return "", err
 	}
 
-	return html, nil
+	// the html is sanitized by the markdown renderer
+	return template.HTML(html), nil
 }
@@ -0,0 +1,191 @@
+package actions
+
+import (
+	"errors"
+
+	"github.com/fox-gonic/fox/database"
+	"github.com/fox-gonic/fox/engine"
+	"github.com/fox-gonic/fox/httperrors"
+	"gorm.io/gorm"
+
+	accounts "github.com/miclle/space/accounts/params"
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// ----------------------------------------------------------------------------
+
+// CreateMemberArgs create member args
+type CreateMemberArgs struct {
+	Login string            `json:"login"`
+	Role  models.MemberRole `json:"role"`
+}
+
+// CreateMember add account to the space members, owners are granted by owners only
+// POST /api/spaces/:key/members
+func (actions *Actions) CreateMember(c *engine.Context, args *CreateMemberArgs) (*models.Member, error) {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	if err := actions.authorize(c, grantorRole(args.Role)); err != nil {
+		return nil, err
+	}
+
+	account, err := actions.Accounter.DescribeAccount(c, &accounts.DescribeAccount{
+		Login: args.Login,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var params = &params.CreateMember{
+		SpaceID:   space.ID,
+		AccountID: account.ID,
+		Role:      args.Role,
+	}
+
+	member, err := actions.Spacer.CreateMember(c, params)
+	if err != nil {
+		return nil, err
+	}
+
+	member.Account = account
+
+	return member, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribeMembersArgs describe members args
+type DescribeMembersArgs struct {
+	database.Pagination[*models.Member]
+	Role models.MemberRole `query:"role"`
+}
+
+// DescribeMembers describe space members
+// GET /api/spaces/:key/members
+func (actions *Actions) DescribeMembers(c *engine.Context, args *DescribeMembersArgs) (*database.Pagination[*models.Member], error) {
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DescribeMembers{
+			Pagination: args.Pagination,
+			SpaceID:    space.ID,
+			Role:       args.Role,
+		}
+	)
+
+	return actions.Spacer.DescribeMembers(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// UpdateMemberArgs update member args
+type UpdateMemberArgs struct {
+	AccountID int64             `uri:"account_id"`
+	Role      models.MemberRole `json:"role"`
+}
+
+// UpdateMember update space member role
+// PATCH /api/spaces/:key/members/:account_id
+func (actions *Actions) UpdateMember(c *engine.Context, args *UpdateMemberArgs) (*models.Member, error) {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	member, err := actions.Spacer.DescribeMember(c, &params.DescribeMember{
+		SpaceID:   space.ID,
+		AccountID: args.AccountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := actions.authorize(c, grantorRole(member.Role, args.Role)); err != nil {
+		return nil, err
+	}
+
+	var params = &params.UpdateMember{
+		SpaceID:   space.ID,
+		AccountID: args.AccountID,
+		Role:      args.Role,
+	}
+
+	return actions.Spacer.UpdateMember(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DeleteMemberArgs delete member args
+type DeleteMemberArgs struct {
+	AccountID int64 `uri:"account_id"`
+}
+
+// DeleteMember remove account from the space members
+// DELETE /api/spaces/:key/members/:account_id
+func (actions *Actions) DeleteMember(c *engine.Context, args *DeleteMemberArgs) error {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	member, err := actions.Spacer.DescribeMember(c, &params.DescribeMember{
+		SpaceID:   space.ID,
+		AccountID: args.AccountID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := actions.authorize(c, grantorRole(member.Role)); err != nil {
+		return err
+	}
+
+	var params = &params.DeleteMember{
+		SpaceID:   space.ID,
+		AccountID: args.AccountID,
+	}
+
+	return actions.Spacer.DeleteMember(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// grantorRole return the role required to grant or revoke the roles,
+// maintainers manage members, owners manage owners
+func grantorRole(roles ...models.MemberRole) models.MemberRole {
+	for _, role := range roles {
+		if role == models.MemberRoleOwner {
+			return models.MemberRoleOwner
+		}
+	}
+	return models.MemberRoleMaintainer
+}
+
+// spaceMember return the account membership of the space, administrators are owners of all spaces
+func (actions *Actions) spaceMember(c *engine.Context, account *models.Account, space *models.Space) (*models.Member, error) {
+
+	if actions.isAdmin(account) {
+		return &models.Member{SpaceID: space.ID, AccountID: account.ID, Role: models.MemberRoleOwner}, nil
+	}
+
+	member, err := actions.Spacer.DescribeMember(c, &params.DescribeMember{
+		SpaceID:   space.ID,
+		AccountID: account.ID,
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, httperrors.ErrForbidden
+	}
+
+	return member, err
+}
+
+// authorize return ErrForbidden unless the account role in the current space includes the role
+func (actions *Actions) authorize(c *engine.Context, role models.MemberRole) error {
+
+	member := c.MustGet("member").(*models.Member)
+
+	if !member.Role.Includes(role) {
+		return httperrors.ErrForbidden
+	}
+
+	return nil
+}
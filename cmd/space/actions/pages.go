@@ -2,6 +2,7 @@ package actions
 
 import (
 	"github.com/fox-gonic/fox/engine"
+	"github.com/fox-gonic/fox/httperrors"
 
 	"github.com/miclle/space/models"
 	"github.com/miclle/space/spaces/params"
@@ -11,33 +12,43 @@ import (
 
 // CreatePageArgs create page args
 type CreatePageArgs struct {
-	ParentID   int64             `json:"parent_id"`
-	PageID     int64             `json:"page_id"`
-	Lang       string            `json:"lang"`
-	Version    string            `json:"version"`
-	Status     models.PageStatus `json:"status"`
-	Title      string            `json:"title"`
-	ShortTitle string            `json:"short_title"`
-	Body       string            `json:"body"`
+	ParentID    int64             `json:"parent_id"`
+	PageID      int64             `json:"page_id"`
+	Lang        string            `json:"lang"`
+	Version     string            `json:"version"`
+	Status      models.PageStatus `json:"status"`
+	Title       string            `json:"title"`
+	ShortTitle  string            `json:"short_title"`
+	Slug        string            `json:"slug"`
+	Body        string            `json:"body"` // the front matter fills the empty fields
+	Tags        []string          `json:"tags"`
+	Description string            `json:"description"`
 }
 
 // CreatePage create page
 // POST /api/spaces/:key/pages
 func (actions *Actions) CreatePage(c *engine.Context, args *CreatePageArgs) (*models.Page, error) {
 
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return nil, err
+	}
+
 	var (
 		space   = c.MustGet("space").(*models.Space)
 		account = c.MustGet("account").(*models.Account)
 		params  = &params.CreatePage{
-			SpaceID:    space.ID,
-			CreatorID:  account.ID,
-			ParentID:   args.ParentID,
-			Lang:       args.Lang,
-			Version:    args.Version,
-			Status:     args.Status,
-			Title:      args.Title,
-			ShortTitle: args.ShortTitle,
-			Body:       args.Body,
+			SpaceID:     space.ID,
+			CreatorID:   account.ID,
+			ParentID:    args.ParentID,
+			Lang:        args.Lang,
+			Version:     args.Version,
+			Status:      args.Status,
+			Title:       args.Title,
+			ShortTitle:  args.ShortTitle,
+			Slug:        args.Slug,
+			Body:        args.Body,
+			Tags:        args.Tags,
+			Description: args.Description,
 		}
 	)
 
@@ -63,6 +74,7 @@ func (actions *Actions) DescribePages(c *engine.Context, args *DescribePagesArgs
 		params = &params.DescribePages{
 			SpaceID:  space.ID,
 			ParentID: args.ParentID,
+			Viewer:   pageViewer(c),
 		}
 	)
 
@@ -80,6 +92,39 @@ func (actions *Actions) DescribePages(c *engine.Context, args *DescribePagesArgs
 
 // ----------------------------------------------------------------------------
 
+// DescribePageTreeArgs describe page tree args
+type DescribePageTreeArgs struct {
+	Lang     string `query:"lang"`
+	Version  string `query:"version"`
+	ParentID int64  `query:"parent_id"`
+	After    int    `query:"after"`
+	Limit    int    `query:"limit"`
+	Expand   int64  `query:"expand"`
+}
+
+// DescribePageTree describe page tree nodes, the children of a page or the tree expanded to a page
+// GET /api/spaces/:key/tree
+func (actions *Actions) DescribePageTree(c *engine.Context, args *DescribePageTreeArgs) (*models.PageCursor, error) {
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DescribePageTree{
+			SpaceID:  space.ID,
+			Lang:     args.Lang,
+			Version:  args.Version,
+			ParentID: args.ParentID,
+			After:    args.After,
+			Limit:    args.Limit,
+			Expand:   args.Expand,
+			Viewer:   pageViewer(c),
+		}
+	)
+
+	return actions.Spacer.DescribePageTree(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
 // DescribePageArgs describe page detail args
 type DescribePageArgs struct {
 	PageID  string `uri:"id"`
@@ -100,51 +145,146 @@ func (actions *Actions) DescribePage(c *engine.Context, args *DescribePageArgs)
 
 // UpdatePageArgs update page args
 type UpdatePageArgs struct {
-	Lang       *string            `json:"lang"`
-	Version    *string            `json:"version"`
-	Status     *models.PageStatus `json:"status"`
-	Title      *string            `json:"title"`
-	ShortTitle *string            `json:"short_title"`
-	Body       *string            `json:"body"`
+	Lang        *string            `json:"lang"`
+	Version     *string            `json:"version"`
+	Status      *models.PageStatus `json:"status"`
+	Title       *string            `json:"title"`
+	ShortTitle  *string            `json:"short_title"`
+	Slug        *string            `json:"slug"`
+	ParentID    *int64             `json:"parent_id"`
+	Body        *string            `json:"body"` // the front matter fills the empty fields
+	Tags        *[]string          `json:"tags"`
+	Description *string            `json:"description"`
 }
 
 // UpdatePage update page
 // PATCH /api/spaces/:key/pages/:id
 func (actions *Actions) UpdatePage(c *engine.Context, args *UpdatePageArgs) (*models.Page, error) {
+
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return nil, err
+	}
+
 	var (
 		page   = c.MustGet("page").(*models.Page)
 		params = &params.UpdatePage{
-			ID:         page.ID,
-			Lang:       args.Lang,
-			Version:    args.Version,
-			Status:     args.Status,
-			Title:      args.Title,
-			ShortTitle: args.ShortTitle,
-			Body:       args.Body,
+			ID:          page.ID,
+			Lang:        args.Lang,
+			Version:     args.Version,
+			Status:      args.Status,
+			Title:       args.Title,
+			ShortTitle:  args.ShortTitle,
+			Slug:        args.Slug,
+			ParentID:    args.ParentID,
+			Body:        args.Body,
+			Tags:        args.Tags,
+			Description: args.Description,
 		}
 	)
 
 	return actions.Spacer.UpdatePage(c, params)
 }
 
+// ----------------------------------------------------------------------------
+
+// CopyPageArgs copy page args
+type CopyPageArgs struct {
+	SpaceKey string `json:"space_key"`
+	ParentID int64  `json:"parent_id"`
+}
+
+// CopyPage copy page and its descendants
+// POST /api/spaces/:key/pages/:id/copy
+func (actions *Actions) CopyPage(c *engine.Context, args *CopyPageArgs) (*models.Page, error) {
+	var (
+		space   = c.MustGet("space").(*models.Space)
+		page    = c.MustGet("page").(*models.Page)
+		account = c.MustGet("account").(*models.Account)
+		target  = space
+		err     error
+	)
+
+	if err := actions.authorize(c, models.MemberRoleEditor); err != nil {
+		return nil, err
+	}
+
+	// the account edits the target space too
+	if args.SpaceKey != "" && args.SpaceKey != space.Key {
+		target, err = actions.Spacer.DescribeSpace(c, &params.DescribeSpace{
+			Key:    args.SpaceKey,
+			Viewer: &params.Viewer{AccountID: account.ID, Unrestricted: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		member, err := actions.spaceMember(c, account, target)
+		if err != nil {
+			return nil, err
+		}
+
+		if !member.Role.Includes(models.MemberRoleEditor) {
+			return nil, httperrors.ErrForbidden
+		}
+	}
+
+	var params = &params.CopyPage{
+		SpaceID:        space.ID,
+		PageID:         page.ID,
+		TargetSpaceID:  target.ID,
+		TargetParentID: args.ParentID,
+		CreatorID:      account.ID,
+		Viewer:         pageViewer(c),
+	}
+
+	return actions.Spacer.CopyPage(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribeBacklinks describe the pages linking to the page
+// GET /api/spaces/:key/pages/:id/backlinks
+func (actions *Actions) DescribeBacklinks(c *engine.Context) ([]*models.Page, error) {
+
+	var (
+		page   = c.MustGet("page").(*models.Page)
+		member = c.MustGet("member").(*models.Member)
+		params = &params.DescribeBacklinks{
+			PageID: page.ID,
+			// the linking pages may be in other spaces, restricted by the viewer role of their spaces
+			Viewer: &params.Viewer{AccountID: member.AccountID},
+		}
+	)
+
+	return actions.Spacer.DescribeBacklinks(c, params)
+}
+
 // middleware
 // ----------------------------------------------------------------------------
 
 // SetPageArgs describe space detail args
 type SetPageArgs struct {
-	PageID  int64  `uri:"id"`
-	Lang    string `query:"lang"    json:"-"`
-	Version string `query:"version" json:"-"`
+	PageID     int64  `uri:"id"`
+	Lang       string `query:"lang"       json:"-"`
+	Version    string `query:"version"    json:"-"`
+	Navigation bool   `query:"navigation" json:"-"`
 }
 
 // SetPage describe space detail
 // MATCH route `/api/spaces/:key/pages/:id`
 func (actions *Actions) SetPage(c *engine.Context, args *SetPageArgs) error {
+
+	if err := actions.authorize(c, models.MemberRoleViewer); err != nil {
+		return err
+	}
+
 	var (
 		space  = c.MustGet("space").(*models.Space)
 		params = &params.DescribePage{
-			SpaceID: space.ID,
-			PageID:  args.PageID,
+			SpaceID:    space.ID,
+			PageID:     args.PageID,
+			Navigation: args.Navigation,
+			Viewer:     pageViewer(c),
 		}
 	)
 
@@ -0,0 +1,117 @@
+package actions
+
+import (
+	"github.com/fox-gonic/fox/database"
+	"github.com/fox-gonic/fox/engine"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// ----------------------------------------------------------------------------
+
+// CreateRedirectArgs create redirect args
+type CreateRedirectArgs struct {
+	Path   string `json:"path"`
+	PageID int64  `json:"page_id"`
+}
+
+// CreateRedirect create redirect
+// POST /api/spaces/:key/redirects
+func (actions *Actions) CreateRedirect(c *engine.Context, args *CreateRedirectArgs) (*models.Redirect, error) {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.CreateRedirect{
+			SpaceID: space.ID,
+			Path:    args.Path,
+			PageID:  args.PageID,
+		}
+	)
+
+	return actions.Spacer.CreateRedirect(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribeRedirectsArgs describe redirects args
+type DescribeRedirectsArgs struct {
+	database.Pagination[*models.Redirect]
+	Q string `query:"q"`
+}
+
+// DescribeRedirects describe redirects
+// GET /api/spaces/:key/redirects
+func (actions *Actions) DescribeRedirects(c *engine.Context, args *DescribeRedirectsArgs) (*database.Pagination[*models.Redirect], error) {
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DescribeRedirects{
+			Pagination: args.Pagination,
+			SpaceID:    space.ID,
+			Q:          args.Q,
+		}
+	)
+
+	return actions.Spacer.DescribeRedirects(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// UpdateRedirectArgs update redirect args
+type UpdateRedirectArgs struct {
+	ID     int64   `uri:"redirect_id"`
+	Path   *string `json:"path"`
+	PageID *int64  `json:"page_id"`
+}
+
+// UpdateRedirect update redirect
+// PATCH /api/spaces/:key/redirects/:redirect_id
+func (actions *Actions) UpdateRedirect(c *engine.Context, args *UpdateRedirectArgs) (*models.Redirect, error) {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.UpdateRedirect{
+			ID:      args.ID,
+			SpaceID: space.ID,
+			Path:    args.Path,
+			PageID:  args.PageID,
+		}
+	)
+
+	return actions.Spacer.UpdateRedirect(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DeleteRedirectArgs delete redirect args
+type DeleteRedirectArgs struct {
+	ID int64 `uri:"redirect_id"`
+}
+
+// DeleteRedirect delete redirect
+// DELETE /api/spaces/:key/redirects/:redirect_id
+func (actions *Actions) DeleteRedirect(c *engine.Context, args *DeleteRedirectArgs) error {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DeleteRedirect{
+			ID:      args.ID,
+			SpaceID: space.ID,
+		}
+	)
+
+	return actions.Spacer.DeleteRedirect(c, params)
+}
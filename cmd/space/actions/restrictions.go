@@ -0,0 +1,121 @@
+package actions
+
+import (
+	"github.com/fox-gonic/fox/engine"
+
+	accounts "github.com/miclle/space/accounts/params"
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// ----------------------------------------------------------------------------
+
+// CreatePageRestrictionArgs create page restriction args, either the account login or the role
+type CreatePageRestrictionArgs struct {
+	Login string            `json:"login"`
+	Role  models.MemberRole `json:"role"`
+}
+
+// CreatePageRestriction restrict the page and its descendants to the account or the members with the role
+// POST /api/spaces/:key/pages/:id/restrictions
+func (actions *Actions) CreatePageRestriction(c *engine.Context, args *CreatePageRestrictionArgs) (*models.PageRestriction, error) {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	var (
+		space   = c.MustGet("space").(*models.Space)
+		page    = c.MustGet("page").(*models.Page)
+		account *models.Account
+		err     error
+	)
+
+	if args.Login != "" {
+		account, err = actions.Accounter.DescribeAccount(c, &accounts.DescribeAccount{
+			Login: args.Login,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var params = &params.CreatePageRestriction{
+		SpaceID: space.ID,
+		PageID:  page.ID,
+		Role:    args.Role,
+	}
+
+	if account != nil {
+		params.AccountID = account.ID
+	}
+
+	restriction, err := actions.Spacer.CreatePageRestriction(c, params)
+	if err != nil {
+		return nil, err
+	}
+
+	restriction.Account = account
+
+	return restriction, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribePageRestrictions describe the restrictions of the page
+// GET /api/spaces/:key/pages/:id/restrictions
+func (actions *Actions) DescribePageRestrictions(c *engine.Context) ([]*models.PageRestriction, error) {
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		page   = c.MustGet("page").(*models.Page)
+		params = &params.DescribePageRestrictions{
+			SpaceID: space.ID,
+			PageID:  page.ID,
+		}
+	)
+
+	return actions.Spacer.DescribePageRestrictions(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// DeletePageRestrictionArgs delete page restriction args
+type DeletePageRestrictionArgs struct {
+	ID int64 `uri:"restriction_id"`
+}
+
+// DeletePageRestriction delete page restriction
+// DELETE /api/spaces/:key/pages/:id/restrictions/:restriction_id
+func (actions *Actions) DeletePageRestriction(c *engine.Context, args *DeletePageRestrictionArgs) error {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		page   = c.MustGet("page").(*models.Page)
+		params = &params.DeletePageRestriction{
+			ID:      args.ID,
+			SpaceID: space.ID,
+			PageID:  page.ID,
+		}
+	)
+
+	return actions.Spacer.DeletePageRestriction(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// pageViewer return the viewer of the restricted pages in the current space,
+// maintainers manage the restrictions and read all pages
+func pageViewer(c *engine.Context) *params.Viewer {
+
+	member := c.MustGet("member").(*models.Member)
+
+	return &params.Viewer{
+		AccountID:    member.AccountID,
+		Unrestricted: member.Role.Includes(models.MemberRoleMaintainer),
+	}
+}
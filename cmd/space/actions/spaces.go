@@ -2,12 +2,17 @@ package actions
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/fox-gonic/fox/database"
 	"github.com/fox-gonic/fox/engine"
+	"github.com/fox-gonic/fox/httperrors"
+	"github.com/gin-gonic/gin/render"
 	"gorm.io/gorm"
 
+	accounts "github.com/miclle/space/accounts/params"
 	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces"
 	"github.com/miclle/space/spaces/params"
 )
 
@@ -15,17 +20,19 @@ import (
 
 // CreateSpaceArgs create space args
 type CreateSpaceArgs struct {
-	Name         string             `json:"name"`
-	Key          string             `json:"key"`
-	Multilingual bool               `json:"multilingual"`
-	Lang         string             `json:"lang"`
-	FallbackLang string             `json:"fallback_lang"`
-	Description  string             `json:"description"`
-	Avatar       string             `json:"avatar"`
-	Status       models.SpaceStatus `json:"status"`
+	Name         string                 `json:"name"`
+	Key          string                 `json:"key"`
+	Multilingual bool                   `json:"multilingual"`
+	Lang         string                 `json:"lang"`
+	FallbackLang string                 `json:"fallback_lang"`
+	Description  string                 `json:"description"`
+	Avatar       string                 `json:"avatar"`
+	Status       models.SpaceStatus     `json:"status"`
+	Visibility   models.SpaceVisibility `json:"visibility"`
+	Template     string                 `json:"template"`
 }
 
-// CreateSpace create space
+// CreateSpace create space, optional from a packaged template
 // POST /api/spaces
 func (actions *Actions) CreateSpace(c *engine.Context, args *CreateSpaceArgs) (*models.Space, error) {
 
@@ -40,12 +47,20 @@ func (actions *Actions) CreateSpace(c *engine.Context, args *CreateSpaceArgs) (*
 		Description:  args.Description,
 		Avatar:       args.Avatar,
 		Status:       args.Status,
+		Visibility:   args.Visibility,
 		CreatorID:    account.ID,
+		Template:     args.Template,
 	}
 
 	return actions.Spacer.CreateSpace(c, params)
 }
 
+// DescribeSpaceTemplates describe the packaged space templates
+// GET /api/spaces/templates
+func (actions *Actions) DescribeSpaceTemplates(c *engine.Context) ([]*models.SpaceTemplate, error) {
+	return actions.Spacer.DescribeSpaceTemplates(c)
+}
+
 // DescribeSpacesArgs describe spaces args
 type DescribeSpacesArgs struct {
 	database.Pagination[*models.Space]
@@ -56,9 +71,17 @@ type DescribeSpacesArgs struct {
 // GET /api/spaces
 func (actions *Actions) DescribeSpaces(c *engine.Context, args *DescribeSpacesArgs) (*database.Pagination[*models.Space], error) {
 
+	var account = c.MustGet("account").(*models.Account)
+
 	var params = &params.DescribeSpaces{
 		Pagination: args.Pagination,
 		Q:          args.Q,
+		Viewer:     &params.Viewer{AccountID: account.ID, Unrestricted: true},
+	}
+
+	// members see their spaces only
+	if !actions.isAdmin(account) {
+		params.AccountID = account.ID
 	}
 
 	return actions.Spacer.DescribeSpaces(c, params)
@@ -84,21 +107,26 @@ func (actions *Actions) DescribeSpace(c *engine.Context, args *DescribeSpaceArgs
 
 // UpdateSpaceArgs update space args
 type UpdateSpaceArgs struct {
-	Key          string             `uri:"key"`
-	Name         *string            `json:"name"`
-	Multilingual *bool              `json:"multilingual"`
-	Lang         *string            `json:"lang"`
-	FallbackLang *string            `json:"fallback_lang"`
-	HomepageID   *int64             `json:"homepage_id"`
-	Description  *string            `json:"description"`
-	Avatar       *string            `json:"avatar"`
-	Status       models.SpaceStatus `json:"status"`
+	Key          string                 `uri:"key"`
+	Name         *string                `json:"name"`
+	Multilingual *bool                  `json:"multilingual"`
+	Lang         *string                `json:"lang"`
+	FallbackLang *string                `json:"fallback_lang"`
+	HomepageID   *int64                 `json:"homepage_id"`
+	Description  *string                `json:"description"`
+	Avatar       *string                `json:"avatar"`
+	Status       models.SpaceStatus     `json:"status"`
+	Visibility   models.SpaceVisibility `json:"visibility"`
 }
 
 // UpdateSpace update space
 // PATCH /api/spaces/:key
 func (actions *Actions) UpdateSpace(c *engine.Context, args *UpdateSpaceArgs) (*models.Space, error) {
 
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
 	var params = &params.UpdateSpace{
 		Key:          args.Key,
 		Name:         args.Name,
@@ -109,6 +137,7 @@ func (actions *Actions) UpdateSpace(c *engine.Context, args *UpdateSpaceArgs) (*
 		Description:  args.Description,
 		Avatar:       args.Avatar,
 		Status:       args.Status,
+		Visibility:   args.Visibility,
 	}
 
 	return actions.Spacer.UpdateSpace(c, params)
@@ -116,19 +145,267 @@ func (actions *Actions) UpdateSpace(c *engine.Context, args *UpdateSpaceArgs) (*
 
 // -----------------------------------------------------------------------------
 
+// UpdateSpaceSettingsArgs update space settings args
+type UpdateSpaceSettingsArgs struct {
+	NavLinks    *[]*models.SpaceNavLink `json:"nav_links"`
+	Footer      *string                 `json:"footer"`
+	AccentColor *string                 `json:"accent_color"`
+	Logo        *string                 `json:"logo"`
+	EditURL     *string                 `json:"edit_url"`
+}
+
+// UpdateSpaceSettings update space website navigation and branding
+// PATCH /api/spaces/:key/settings
+func (actions *Actions) UpdateSpaceSettings(c *engine.Context, args *UpdateSpaceSettingsArgs) (*models.Space, error) {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.UpdateSpaceSettings{
+			Key:         space.Key,
+			NavLinks:    args.NavLinks,
+			Footer:      args.Footer,
+			AccentColor: args.AccentColor,
+			Logo:        args.Logo,
+			EditURL:     args.EditURL,
+		}
+	)
+
+	return actions.Spacer.UpdateSpaceSettings(c, params)
+}
+
+// -----------------------------------------------------------------------------
+
+// DescribeSpaceStatsArgs describe space statistics args
+type DescribeSpaceStatsArgs struct {
+	Days      int `query:"days"`
+	StaleDays int `query:"stale_days"`
+	Limit     int `query:"limit"`
+}
+
+// DescribeSpaceStats describe space page counts, words, contributors and stale pages
+// GET /api/spaces/:key/stats
+func (actions *Actions) DescribeSpaceStats(c *engine.Context, args *DescribeSpaceStatsArgs) (*models.SpaceStats, error) {
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DescribeSpaceStats{
+			SpaceID:   space.ID,
+			Days:      args.Days,
+			StaleDays: args.StaleDays,
+			Limit:     args.Limit,
+			Viewer:    pageViewer(c),
+		}
+	)
+
+	return actions.Spacer.DescribeSpaceStats(c, params)
+}
+
+// -----------------------------------------------------------------------------
+
+// ArchiveSpaceArgs archive space args
+type ArchiveSpaceArgs struct {
+	Key string `uri:"key"`
+}
+
+// ArchiveSpace archive space, archived spaces are read-only and hidden from the website
+// POST /api/spaces/:key/archive
+func (actions *Actions) ArchiveSpace(c *engine.Context, args *ArchiveSpaceArgs) (*models.Space, error) {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	return actions.Spacer.ArchiveSpace(c, &params.ArchiveSpace{Key: space.Key})
+}
+
+// RestoreSpaceArgs restore space args
+type RestoreSpaceArgs struct {
+	Key string `uri:"key"`
+}
+
+// RestoreSpace restore archived space
+// POST /api/spaces/:key/restore
+func (actions *Actions) RestoreSpace(c *engine.Context, args *RestoreSpaceArgs) (*models.Space, error) {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	return actions.Spacer.RestoreSpace(c, &params.RestoreSpace{Key: space.Key})
+}
+
+// DeleteSpaceToken delete space confirmation token
+type DeleteSpaceToken struct {
+	Token string `json:"token"`
+}
+
+// CreateDeleteSpaceTokenArgs create delete space token args
+type CreateDeleteSpaceTokenArgs struct {
+	Key string `uri:"key"`
+}
+
+// CreateDeleteSpaceToken create delete space confirmation token
+// POST /api/spaces/:key/delete_token
+func (actions *Actions) CreateDeleteSpaceToken(c *engine.Context, args *CreateDeleteSpaceTokenArgs) (*DeleteSpaceToken, error) {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	if err := actions.authorize(c, models.MemberRoleOwner); err != nil {
+		return nil, err
+	}
+
+	token, err := actions.Spacer.CreateDeleteSpaceToken(c, &params.CreateDeleteSpaceToken{Key: space.Key})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeleteSpaceToken{Token: token}, nil
+}
+
+// DeleteSpaceArgs delete space args
+type DeleteSpaceArgs struct {
+	Key   string `uri:"key"`
+	Token string `query:"token"`
+}
+
+// DeleteSpace delete space with the confirmation token
+// DELETE /api/spaces/:key
+func (actions *Actions) DeleteSpace(c *engine.Context, args *DeleteSpaceArgs) error {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	if err := actions.authorize(c, models.MemberRoleOwner); err != nil {
+		return err
+	}
+
+	return actions.Spacer.DeleteSpace(c, &params.DeleteSpace{
+		Key:   space.Key,
+		Token: args.Token,
+	})
+}
+
+// CloneSpaceArgs clone space args
+type CloneSpaceArgs struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// CloneSpace create a space with the page tree, contents of all languages and settings of the space
+// POST /api/spaces/:key/clone
+func (actions *Actions) CloneSpace(c *engine.Context, args *CloneSpaceArgs) (*models.Space, error) {
+
+	var (
+		space   = c.MustGet("space").(*models.Space)
+		account = c.MustGet("account").(*models.Account)
+	)
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	return actions.Spacer.CreateSpace(c, &params.CreateSpace{
+		Name:        args.Name,
+		Key:         args.Key,
+		CreatorID:   account.ID,
+		FromSpaceID: space.ID,
+	})
+}
+
+// RenameSpaceKeyArgs rename space key args
+type RenameSpaceKeyArgs struct {
+	Key string `json:"key"`
+}
+
+// RenameSpaceKey change space key, the urls with the previous key redirect to the space
+// POST /api/spaces/:key/rename
+func (actions *Actions) RenameSpaceKey(c *engine.Context, args *RenameSpaceKeyArgs) (*models.Space, error) {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	if err := actions.authorize(c, models.MemberRoleOwner); err != nil {
+		return nil, err
+	}
+
+	return actions.Spacer.RenameSpaceKey(c, &params.RenameSpaceKey{
+		Key:    space.Key,
+		NewKey: args.Key,
+	})
+}
+
+// TransferSpaceArgs transfer space args
+type TransferSpaceArgs struct {
+	Login string `json:"login"`
+}
+
+// TransferSpace transfer space ownership to the account, the transferring owner stays as a maintainer
+// POST /api/spaces/:key/transfer
+func (actions *Actions) TransferSpace(c *engine.Context, args *TransferSpaceArgs) (*models.Space, error) {
+
+	var (
+		space = c.MustGet("space").(*models.Space)
+		owner = c.MustGet("account").(*models.Account)
+	)
+
+	if err := actions.authorize(c, models.MemberRoleOwner); err != nil {
+		return nil, err
+	}
+
+	account, err := actions.Accounter.DescribeAccount(c, &accounts.DescribeAccount{
+		Login: args.Login,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return actions.Spacer.TransferSpace(c, &params.TransferSpace{
+		Key:       space.Key,
+		AccountID: account.ID,
+		OwnerID:   owner.ID,
+	})
+}
+
+// -----------------------------------------------------------------------------
+
 // SetSpaceArgs describe space detail args
 type SetSpaceArgs struct {
 	Key string `uri:"key"`
 }
 
-// SetSpace describe space detail
+// SetSpace describe space detail, the previous keys of renamed spaces are redirected
 // MATCH route `/api/spaces/:key`
-func (actions *Actions) SetSpace(c *engine.Context, args *SetSpaceArgs) error {
+func (actions *Actions) SetSpace(c *engine.Context, args *SetSpaceArgs) (res interface{}) {
 
+	var account = c.MustGet("account").(*models.Account)
+
+	// the renamed spaces are redirected only if they are visible to the account
 	space, err := actions.Spacer.DescribeSpace(c, &params.DescribeSpace{
 		Key: args.Key,
+		Viewer: &params.Viewer{
+			AccountID:    account.ID,
+			Unrestricted: actions.isAdmin(account),
+		},
 	})
 
+	var moved *spaces.SpaceMovedError
+	if errors.As(err, &moved) {
+		return render.Redirect{
+			Code:     http.StatusPermanentRedirect,
+			Location: spaces.SpaceMovedLocation(c.Request.URL, args.Key, moved.Key),
+		}
+	}
+
+	if errors.Is(err, spaces.ErrSpaceAccessDenied) {
+		return httperrors.ErrForbidden
+	}
+
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		// TODO(m) custom errors.New
 		return gorm.ErrRecordNotFound
@@ -139,7 +416,14 @@ func (actions *Actions) SetSpace(c *engine.Context, args *SetSpaceArgs) error {
 		return err
 	}
 
+	// only the space members have access to the space
+	member, err := actions.spaceMember(c, account, space)
+	if err != nil {
+		return err
+	}
+
 	c.Set("space", space)
+	c.Set("member", member)
 
 	return nil
 }
@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fox-gonic/fox/engine"
+	"github.com/fox-gonic/fox/httperrors"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces"
+	"github.com/miclle/space/spaces/params"
+)
+
+// variableError return 409 if the variable name is taken in the space and version
+func variableError(variable *models.Variable, err error) (*models.Variable, error) {
+	if errors.Is(err, spaces.ErrVariableNameIsTaken) {
+		return nil, &httperrors.Error{HTTPCode: http.StatusConflict}
+	}
+	return variable, err
+}
+
+// ----------------------------------------------------------------------------
+
+// CreateVariableArgs create variable args
+type CreateVariableArgs struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+}
+
+// CreateVariable create variable
+// POST /api/spaces/:key/variables
+func (actions *Actions) CreateVariable(c *engine.Context, args *CreateVariableArgs) (*models.Variable, error) {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.CreateVariable{
+			SpaceID: space.ID,
+			Version: args.Version,
+			Name:    args.Name,
+			Value:   args.Value,
+		}
+	)
+
+	return variableError(actions.Spacer.CreateVariable(c, params))
+}
+
+// ----------------------------------------------------------------------------
+
+// DescribeVariables describe variables
+// GET /api/spaces/:key/variables
+func (actions *Actions) DescribeVariables(c *engine.Context) ([]*models.Variable, error) {
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DescribeVariables{
+			SpaceID: space.ID,
+		}
+	)
+
+	return actions.Spacer.DescribeVariables(c, params)
+}
+
+// ----------------------------------------------------------------------------
+
+// UpdateVariableArgs update variable args
+type UpdateVariableArgs struct {
+	ID    int64   `uri:"variable_id"`
+	Name  *string `json:"name"`
+	Value *string `json:"value"`
+}
+
+// UpdateVariable update variable
+// PATCH /api/spaces/:key/variables/:variable_id
+func (actions *Actions) UpdateVariable(c *engine.Context, args *UpdateVariableArgs) (*models.Variable, error) {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return nil, err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.UpdateVariable{
+			ID:      args.ID,
+			SpaceID: space.ID,
+			Name:    args.Name,
+			Value:   args.Value,
+		}
+	)
+
+	return variableError(actions.Spacer.UpdateVariable(c, params))
+}
+
+// ----------------------------------------------------------------------------
+
+// DeleteVariableArgs delete variable args
+type DeleteVariableArgs struct {
+	ID int64 `uri:"variable_id"`
+}
+
+// DeleteVariable delete variable
+// DELETE /api/spaces/:key/variables/:variable_id
+func (actions *Actions) DeleteVariable(c *engine.Context, args *DeleteVariableArgs) error {
+
+	if err := actions.authorize(c, models.MemberRoleMaintainer); err != nil {
+		return err
+	}
+
+	var (
+		space  = c.MustGet("space").(*models.Space)
+		params = &params.DeleteVariable{
+			ID:      args.ID,
+			SpaceID: space.ID,
+		}
+	)
+
+	return actions.Spacer.DeleteVariable(c, params)
+}
@@ -2,13 +2,19 @@ package website
 
 import (
 	"net/http"
+	"net/url"
 	"path"
 
 	"github.com/fox-gonic/fox/engine"
+	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin/render"
+	"github.com/samber/lo"
 
 	"github.com/miclle/space/accounts"
+	accountparams "github.com/miclle/space/accounts/params"
+	api "github.com/miclle/space/cmd/space/actions"
 	"github.com/miclle/space/config"
+	"github.com/miclle/space/pkg/diagram"
 	"github.com/miclle/space/spaces"
 	"github.com/miclle/space/spaces/params"
 )
@@ -18,6 +24,7 @@ type Actions struct {
 	Configuration config.Configuration
 	Accounter     accounts.Service
 	Spacer        spaces.Service
+	Diagrams      *diagram.Cache // pre-rendered diagram svgs
 }
 
 // SetLangArgs set lang middleware
@@ -45,13 +52,57 @@ func (actions *Actions) SetLang(c *engine.Context, args *SetLangArgs) (res inter
 	return
 }
 
+// SetViewer set the website reader middleware, the signed-in account is optional,
+// administrators are unrestricted
+func (actions *Actions) SetViewer(c *engine.Context) {
+
+	var (
+		viewer = &params.Viewer{}
+		login  = sessions.Default(c.Context).Get(api.SessionAccountKey)
+	)
+
+	if l, ok := login.(string); ok {
+		account, err := actions.Accounter.DescribeAccount(c, &accountparams.DescribeAccount{
+			Login: l,
+		})
+
+		if err != nil {
+			c.Logger.Errorf("get session account failed, err: %+v", err)
+		} else {
+			c.Set("account", account)
+			viewer.AccountID = account.ID
+
+			viewer.Unrestricted = lo.Contains(actions.Configuration.Admins, account.Login)
+		}
+	}
+
+	c.Set("viewer", viewer)
+}
+
+// signin redirect anonymous readers to sign in and come back, otherwise render 404 page
+func (actions *Actions) signin(c *engine.Context, data interface{}) {
+
+	if _, exists := c.Get("account"); exists {
+		c.HTML(404, "404.html", data)
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/signin?"+url.Values{"redirect": {c.Request.URL.RequestURI()}}.Encode())
+}
+
 // SetGlobal global middleware
 func (actions *Actions) SetGlobal(c *engine.Context) {
 
-	lang := c.MustGet("lang").(string)
+	var (
+		lang     = c.MustGet("lang").(string)
+		archived = false
+	)
 
+	// archived spaces and spaces invisible to the reader are hidden from the website
 	var params = &params.DescribeSpaces{
-		Lang: lang,
+		Lang:     lang,
+		Archived: &archived,
+		Viewer:   c.MustGet("viewer").(*params.Viewer),
 	}
 
 	params.PageSize = 1000
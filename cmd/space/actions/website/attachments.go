@@ -0,0 +1,121 @@
+package website
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fox-gonic/fox/engine"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/storage"
+	"github.com/miclle/space/spaces"
+	"github.com/miclle/space/spaces/params"
+)
+
+// inlineAttachment return the attachment content type is displayed in the browser,
+// the other files are downloaded
+func inlineAttachment(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		mediaType == "application/pdf",
+		mediaType == "text/plain":
+		return true
+	}
+
+	return false
+}
+
+// DescribeAttachmentArgs describe attachment args
+type DescribeAttachmentArgs struct {
+	ID      string `uri:"id"`
+	Variant string `query:"v"` // resized image variant, e.g. `320w` or `thumbnail`
+}
+
+// DescribeAttachment serve the attachment content or the image variant, the attachments are immutable
+// GET /attachments/:id/*name
+func (actions *Actions) DescribeAttachment(c *engine.Context, args *DescribeAttachmentArgs) {
+
+	id, err := strconv.ParseInt(args.ID, 10, 64)
+	if err != nil {
+		actions.NotFound(c)
+		return
+	}
+
+	attachment, reader, err := actions.Spacer.OpenAttachment(c, &params.OpenAttachment{
+		ID:      id,
+		Variant: args.Variant,
+		Viewer:  c.MustGet("viewer").(*params.Viewer),
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, storage.ErrNotFound):
+			actions.NotFound(c)
+		case errors.Is(err, spaces.ErrSpaceAccessDenied), errors.Is(err, spaces.ErrPageAccessDenied):
+			c.Status(http.StatusForbidden)
+		default:
+			c.Logger.Error("open attachment failed", err)
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+	defer reader.Close()
+
+	var (
+		header      = c.Writer.Header()
+		etag        = `"` + attachment.Checksum + `"`
+		contentType = attachment.ContentType
+		size        = attachment.Size
+	)
+
+	if variant := attachment.Variant(args.Variant); variant != nil {
+		etag = `"` + attachment.Checksum + "-" + variant.Name + `"`
+		contentType, size = variant.ContentType, variant.Size
+	}
+
+	// the public space attachments may be cached by shared caches
+	if visibility := attachment.Space.Visibility; visibility == "" || visibility == models.SpaceVisibilityPublic {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		header.Set("Cache-Control", "private, max-age=31536000, immutable")
+	}
+
+	header.Set("ETag", etag)
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	disposition := "attachment"
+	if inlineAttachment(contentType) {
+		disposition = "inline"
+	}
+
+	// the uploaded files must not run scripts in the website origin, e.g. svg images
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.FormatInt(size, 10))
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	header.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	c.Status(http.StatusOK)
+
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		c.Logger.Error(fmt.Sprintf("write attachment %d failed", attachment.ID), err)
+	}
+}
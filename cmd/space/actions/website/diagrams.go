@@ -0,0 +1,65 @@
+package website
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/fox-gonic/fox/engine"
+
+	"github.com/miclle/space/pkg/storage"
+)
+
+// DescribeDiagramArgs describe diagram args
+type DescribeDiagramArgs struct {
+	Name string `uri:"name"` // `<sha256>.svg`
+}
+
+// DescribeDiagram serve the pre-rendered diagram svg, the svgs are named by the content hash and immutable
+// GET /diagrams/:name
+func (actions *Actions) DescribeDiagram(c *engine.Context, args *DescribeDiagramArgs) {
+
+	if actions.Diagrams == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	reader, err := actions.Diagrams.Open(c, args.Name)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.Status(http.StatusNotFound)
+		} else {
+			c.Logger.Error("open diagram failed", err)
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+	defer reader.Close()
+
+	etag := `"` + args.Name + `"`
+
+	header := c.Writer.Header()
+	header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	header.Set("ETag", etag)
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	// the rendered svgs must not run scripts in the website origin
+	header.Set("Content-Type", "image/svg+xml")
+	header.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	c.Status(http.StatusOK)
+
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		c.Logger.Error(fmt.Sprintf("write diagram %s failed", args.Name), err)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/fox-gonic/fox/engine"
 	"gorm.io/gorm"
 
 	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces"
 	"github.com/miclle/space/spaces/params"
 	"github.com/miclle/space/ui"
 )
@@ -16,39 +19,60 @@ import (
 // DescribePageArgs describe page detail args
 type DescribePageArgs struct {
 	SpaceKey string `uri:"space_key"`
-	PageID   int64  `uri:"page_id"`
+	Path     string `uri:"path"`
 	Lang     string `uri:"lang"`
 	Version  string `query:"version"`
 }
 
 // DescribePage describe page detail
-// GET /docs/:id
+// GET /:lang/docs/:space_key/*path
 func (actions *Actions) DescribePage(c *engine.Context, args *DescribePageArgs) {
 
 	var (
-		spaces = c.MustGet("spaces").([]*models.Space)
-		space  = c.MustGet("space").(*models.Space)
-		pages  = c.MustGet("pages").([]*models.Page)
-		page   *models.Page
+		space = c.MustGet("space").(*models.Space)
+		page  *models.Page
+		path  = strings.Trim(args.Path, "/")
 	)
 
 	data := ui.PageData{
-		Lang:   args.Lang,
-		Spaces: spaces,
-		Space:  space,
-		Pages:  pages,
+		Lang:     args.Lang,
+		Spaces:   c.MustGet("spaces").([]*models.Space),
+		Space:    space,
+		Settings: &space.Settings,
 	}
 
-	page, err := actions.Spacer.DescribePage(c, &params.DescribePage{
-		SpaceID: space.ID,
-		PageID:  args.PageID,
-		Lang:    args.Lang,
-		Version: args.Version,
-	})
+	// `/:lang/docs/:space_key/` is the space homepage
+	if path == "" {
+		c.Redirect(http.StatusFound, fmt.Sprintf("/%s/docs/%s", args.Lang, space.Key))
+		return
+	}
+
+	var params = &params.DescribePage{
+		SpaceID:    space.ID,
+		Path:       path,
+		Lang:       args.Lang,
+		Version:    args.Version,
+		Navigation: true,
+		Viewer:     c.MustGet("viewer").(*params.Viewer),
+	}
+
+	// numeric path is the legacy page id url
+	id, numeric := strconv.ParseInt(path, 10, 64)
+	if numeric == nil {
+		params.PageID = id
+	}
+
+	page, err := actions.Spacer.DescribePage(c, params)
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.HTML(404, "404.html", data)
+			actions.redirect(c, args, data)
+			return
+		}
+
+		if errors.Is(err, spaces.ErrPageAccessDenied) {
+			data.Title = "Page not found"
+			actions.signin(c, data)
 			return
 		}
 
@@ -63,8 +87,59 @@ func (actions *Actions) DescribePage(c *engine.Context, args *DescribePageArgs)
 		return
 	}
 
+	// permanent redirect legacy page id url to the slug url
+	if numeric == nil && page.Path != "" {
+		location := fmt.Sprintf("/%s/docs/%s/%s", args.Lang, space.Key, page.Path)
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
+
+	tree, err := actions.pagetree(c, space, args.Lang, args.Version, page.ID)
+	if err != nil {
+		c.Logger.Error("get space page tree failed", err)
+		c.HTML(500, "500.html", data)
+		return
+	}
+
 	data.Title = page.Content.Title
 	data.Page = page
+	data.Pages, data.PagesNext = tree.Items, tree.Next
+	data.EditURL = space.Settings.EditLink(space, page)
 
 	c.HTML(200, "page.html", data)
 }
+
+// redirect permanent redirect historical page path, otherwise render 404 page
+func (actions *Actions) redirect(c *engine.Context, args *DescribePageArgs, data ui.PageData) {
+
+	redirect, err := actions.Spacer.DescribeRedirect(c, &params.DescribeRedirect{
+		SpaceID: data.Space.ID,
+		Path:    args.Path,
+	})
+
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Logger.Error("get redirect failed", err)
+		}
+		c.HTML(404, "404.html", data)
+		return
+	}
+
+	var (
+		page     = redirect.Page
+		location = fmt.Sprintf("/%s/docs/%s", args.Lang, page.Space.Key)
+	)
+
+	if page.ID != page.Space.HomepageID {
+		location = fmt.Sprintf("%s/%s", location, page.Pathname())
+	}
+
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+
+	c.Redirect(http.StatusMovedPermanently, location)
+}
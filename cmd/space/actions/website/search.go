@@ -25,6 +25,7 @@ func (actions *Actions) Search(c *engine.Context, args *SearchArgs) {
 		Pagination: args.Pagination,
 		Lang:       args.Lang,
 		Q:          args.Q,
+		Viewer:     c.MustGet("viewer").(*params.Viewer),
 	})
 
 	if err != nil {
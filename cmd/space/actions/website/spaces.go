@@ -2,11 +2,13 @@ package website
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/fox-gonic/fox/engine"
 	"gorm.io/gorm"
 
 	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces"
 	"github.com/miclle/space/spaces/params"
 	"github.com/miclle/space/ui"
 )
@@ -21,18 +23,48 @@ type DescribeSpaceArgs struct {
 // GET /:lang/docs/:space
 func (actions *Actions) DescribeSpace(c *engine.Context, args *DescribeSpaceArgs) {
 
-	var (
-		spaces = c.MustGet("spaces").([]*models.Space)
-		space  = c.MustGet("space").(*models.Space)
-		pages  = c.MustGet("pages").([]*models.Page)
-	)
+	var space = c.MustGet("space").(*models.Space)
+
+	// the homepage may be restricted
+	if space.HomepageID > 0 {
+		_, err := actions.Spacer.DescribePage(c, &params.DescribePage{
+			SpaceID: space.ID,
+			PageID:  space.HomepageID,
+			Lang:    args.Lang,
+			Viewer:  c.MustGet("viewer").(*params.Viewer),
+		})
+
+		if errors.Is(err, spaces.ErrPageAccessDenied) {
+			actions.signin(c, map[string]interface{}{
+				"Lang":  args.Lang,
+				"Title": "Page not found",
+			})
+			return
+		}
+
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Logger.Error("get space homepage failed", err)
+			c.HTML(500, "500.html", map[string]interface{}{})
+			return
+		}
+	}
+
+	tree, err := actions.pagetree(c, space, args.Lang, "", space.HomepageID)
+	if err != nil {
+		c.Logger.Error("get space page tree failed", err)
+		c.HTML(500, "500.html", map[string]interface{}{})
+		return
+	}
 
 	data := ui.PageData{
-		Lang:   args.Lang,
-		Title:  space.Name,
-		Spaces: spaces,
-		Space:  space,
-		Pages:  pages,
+		Lang:      args.Lang,
+		Title:     space.Name,
+		Spaces:    c.MustGet("spaces").([]*models.Space),
+		Space:     space,
+		Pages:     tree.Items,
+		PagesNext: tree.Next,
+		Settings:  &space.Settings,
+		EditURL:   space.Settings.EditLink(space, space.Homepage),
 	}
 
 	c.HTML(200, "space.html", data)
@@ -51,19 +83,31 @@ type SetSpaceArgs struct {
 // match route: `/:lang/docs/:space`
 func (actions *Actions) SetSpace(c *engine.Context, args *SetSpaceArgs) {
 
-	var (
-		space *models.Space
-		pages []*models.Page
-	)
+	var space *models.Space
 
 	// find space
 	space, err := actions.Spacer.DescribeSpace(c, &params.DescribeSpace{
 		Key:     args.SpaceKey,
 		Lang:    args.Lang,
 		Version: args.Version,
+		Viewer:  c.MustGet("viewer").(*params.Viewer),
 	})
 
 	if err != nil {
+		var moved *spaces.SpaceMovedError
+		if errors.As(err, &moved) {
+			c.Redirect(http.StatusMovedPermanently, spaces.SpaceMovedLocation(c.Request.URL, args.SpaceKey, moved.Key))
+			return
+		}
+
+		if errors.Is(err, spaces.ErrSpaceAccessDenied) {
+			actions.signin(c, map[string]interface{}{
+				"Lang":  args.Lang,
+				"Title": "Page not found",
+			})
+			return
+		}
+
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.HTML(404, "404.html", map[string]interface{}{
 				"Lang":  args.Lang,
@@ -77,18 +121,5 @@ func (actions *Actions) SetSpace(c *engine.Context, args *SetSpaceArgs) {
 		return
 	}
 
-	pages, err = actions.Spacer.DescribePages(c, &params.DescribePages{
-		SpaceID: space.ID,
-		Lang:    args.Lang,
-		Version: args.Version,
-	})
-
-	if err != nil {
-		c.Logger.Error("get space pages failed", err)
-		c.HTML(500, "500.html", map[string]interface{}{})
-		return
-	}
-
 	c.Set("space", space)
-	c.Set("pages", pages)
 }
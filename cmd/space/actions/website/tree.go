@@ -0,0 +1,58 @@
+package website
+
+import (
+	"github.com/fox-gonic/fox/engine"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// DescribePageTreeArgs describe page tree nodes args
+type DescribePageTreeArgs struct {
+	Lang     string `uri:"lang"`
+	SpaceKey string `uri:"space_key"`
+	Version  string `query:"version"`
+	ParentID int64  `query:"parent_id"`
+	After    int    `query:"after"`
+}
+
+// DescribePageTree render the children nodes of a page, loaded by the pagetree when a node is expanded
+// GET /:lang/pagetree/:space_key
+func (actions *Actions) DescribePageTree(c *engine.Context, args *DescribePageTreeArgs) {
+
+	var space = c.MustGet("space").(*models.Space)
+
+	tree, err := actions.Spacer.DescribePageTree(c, &params.DescribePageTree{
+		SpaceID:  space.ID,
+		Lang:     args.Lang,
+		Version:  args.Version,
+		ParentID: args.ParentID,
+		After:    args.After,
+		Viewer:   c.MustGet("viewer").(*params.Viewer),
+	})
+
+	if err != nil {
+		c.Logger.Error("get space page tree failed", err)
+		c.String(500, "")
+		return
+	}
+
+	c.HTML(200, "treenode", map[string]interface{}{
+		"Lang":     args.Lang,
+		"Space":    space,
+		"Pages":    tree.Items,
+		"ParentID": args.ParentID,
+		"Next":     tree.Next,
+	})
+}
+
+// pagetree describe the page tree expanded to the page
+func (actions *Actions) pagetree(c *engine.Context, space *models.Space, lang, version string, expand int64) (*models.PageCursor, error) {
+	return actions.Spacer.DescribePageTree(c, &params.DescribePageTree{
+		SpaceID: space.ID,
+		Lang:    lang,
+		Version: version,
+		Expand:  expand,
+		Viewer:  c.MustGet("viewer").(*params.Viewer),
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/miclle/space/spaces"
+	"github.com/miclle/space/spaces/params"
+)
+
+// fsck check and repair the page tree nested set
+//
+//	space -f config.yaml fsck [-space=key] [-repair] [-dry-run]
+func fsck(spacer spaces.Service, args []string, output io.Writer) error {
+
+	var (
+		flags    = flag.NewFlagSet("fsck", flag.ContinueOnError)
+		spaceKey = flags.String("space", "", "check the space with the key only")
+		repair   = flags.Bool("repair", false, "rebuild the nested set from parent_id")
+		dryRun   = flags.Bool("dry-run", false, "report the repair without writing")
+		ctx      = context.Background()
+		spaceID  int64
+	)
+
+	flags.SetOutput(output)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *spaceKey != "" {
+		// the operator repairs the spaces of all visibilities, the previous key of a renamed space is followed
+		var (
+			viewer = &params.Viewer{Unrestricted: true}
+			moved  *spaces.SpaceMovedError
+		)
+
+		space, err := spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: *spaceKey, Viewer: viewer})
+		if errors.As(err, &moved) {
+			space, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: moved.Key, Viewer: viewer})
+		}
+		if err != nil {
+			return fmt.Errorf("find space %s failed, err: %w", *spaceKey, err)
+		}
+		spaceID = space.ID
+	}
+
+	report, err := spacer.CheckPageTree(ctx, &params.CheckPageTree{
+		SpaceID: spaceID,
+		Repair:  *repair || *dryRun,
+		DryRun:  *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Fprintf(output, "space %d page %d: [%s] %s\n", issue.SpaceID, issue.PageID, issue.Kind, issue.Message)
+	}
+
+	fmt.Fprintf(output, "%d pages checked, %d issues found\n", report.Pages, len(report.Issues))
+
+	switch {
+	case report.DryRun:
+		fmt.Fprintf(output, "%d pages would be rebuilt\n", report.Repaired)
+	case report.Repaired > 0:
+		fmt.Fprintf(output, "%d pages rebuilt\n", report.Repaired)
+	case len(report.Issues) > 0:
+		return fmt.Errorf("%d issues found, run with -repair to rebuild the nested set", len(report.Issues))
+	}
+
+	return nil
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/fox-gonic/fox/configurations"
@@ -14,6 +16,9 @@ import (
 	"github.com/miclle/space/accounts"
 	"github.com/miclle/space/config"
 	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/diagram"
+	"github.com/miclle/space/pkg/linkcheck"
+	"github.com/miclle/space/pkg/storage"
 	"github.com/miclle/space/spaces"
 )
 
@@ -27,6 +32,10 @@ func main() {
 
 	var confPath string
 	flag.StringVar(&confPath, "f", "", "-f=/path/to/config")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-f=/path/to/config] [fsck [-space=key] [-repair] [-dry-run]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	var configuration config.Configuration
@@ -50,16 +59,37 @@ func main() {
 		log.Fatalf("new accounts service failed, err: %+v", err)
 	}
 
-	spacer, err := spaces.NewService(database)
+	store, err := storage.New(configuration.Storage)
+	if err != nil {
+		log.Fatalf("storage init failed, err: %+v", err)
+	}
+
+	diagrams := diagram.New(configuration.Diagram, store)
+
+	spacer, err := spaces.NewService(database,
+		spaces.WithStorage(store),
+		spaces.WithLinkChecker(linkcheck.New(configuration.LinkCheck)),
+		spaces.WithDiagramRenderer(diagrams),
+		spaces.WithSecret(configuration.Secret),
+	)
 	if err != nil {
 		log.Fatalf("new spaces service failed, err: %+v", err)
 	}
 
+	// subcommands
+	switch flag.Arg(0) {
+	case "fsck":
+		if err := fsck(spacer, flag.Args()[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	engine.SetMode(configuration.Env)
 
 	platformServer := &http.Server{
 		Addr:         configuration.Addr,
-		Handler:      router(configuration, accounter, spacer),
+		Handler:      router(configuration, accounter, spacer, diagrams),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/miclle/space/cmd/space/actions"
 	"github.com/miclle/space/cmd/space/actions/website"
 	"github.com/miclle/space/config"
+	"github.com/miclle/space/pkg/diagram"
 	"github.com/miclle/space/spaces"
 	"github.com/miclle/space/ui"
 )
@@ -19,6 +20,7 @@ func router(
 	configuration config.Configuration,
 	accounter accounts.Service,
 	spacer spaces.Service,
+	diagrams *diagram.Cache,
 ) http.Handler {
 
 	// --------------------------------------------------------------------------
@@ -55,23 +57,33 @@ func router(
 			Configuration: configuration,
 			Accounter:     accounter,
 			Spacer:        spacer,
+			Diagrams:      diagrams,
 		}
 
-		group := router.Group("", website.SetLang, website.SetGlobal)
+		// the diagram svgs are named by the content hash, without the lang and the viewer
+		router.GET("/diagrams/:name", website.DescribeDiagram)
+
+		group := router.Group("", website.SetLang, website.SetViewer, website.SetGlobal)
 		group.GET("/", website.Homepage)
 		group.GET("/:lang", website.Homepage)
 
 		group.GET("/search", website.Search)
 		group.GET("/:lang/search", website.Search)
 
+		group.GET("/attachments/:id", website.DescribeAttachment)
+		group.GET("/attachments/:id/*name", website.DescribeAttachment)
+
 		{
 			spaceGroup := group.Group("", website.SetSpace)
 
 			spaceGroup.GET("/docs/:space_key", website.DescribeSpace)
 			spaceGroup.GET("/:lang/docs/:space_key", website.DescribeSpace)
 
-			spaceGroup.GET("/docs/:space_key/:page_id", website.DescribePage)
-			spaceGroup.GET("/:lang/docs/:space_key/:page_id", website.DescribePage)
+			spaceGroup.GET("/docs/:space_key/*path", website.DescribePage)
+			spaceGroup.GET("/:lang/docs/:space_key/*path", website.DescribePage)
+
+			spaceGroup.GET("/pagetree/:space_key", website.DescribePageTree)
+			spaceGroup.GET("/:lang/pagetree/:space_key", website.DescribePageTree)
 		}
 	}
 
@@ -96,18 +108,58 @@ func router(
 
 		group.POST("/spaces", api.CreateSpace)
 		group.GET("/spaces", api.DescribeSpaces)
+		group.GET("/spaces/templates", api.DescribeSpaceTemplates)
 
 		space := group.Group("/spaces/:key", api.SetSpace)
 		space.GET("", api.DescribeSpace)
 		space.PATCH("", api.UpdateSpace)
+		space.PATCH("/settings", api.UpdateSpaceSettings)
+		space.GET("/stats", api.DescribeSpaceStats)
+		space.DELETE("", api.DeleteSpace)
+		space.POST("/archive", api.ArchiveSpace)
+		space.POST("/restore", api.RestoreSpace)
+		space.POST("/delete_token", api.CreateDeleteSpaceToken)
+		space.POST("/clone", api.CloneSpace)
+		space.POST("/rename", api.RenameSpaceKey)
+		space.POST("/transfer", api.TransferSpace)
 		space.POST("/pages", api.CreatePage)
 		space.GET("/pages", api.DescribePages)
+		space.GET("/tree", api.DescribePageTree)
+		space.POST("/markdown/preview", api.PreviewMarkdown)
+
+		space.POST("/linkcheck", api.CreateLinkCheck)
+		space.GET("/linkcheck", api.DescribeLinkChecks)
+		space.GET("/linkcheck/:check_id", api.DescribeLinkCheck)
+
+		space.GET("/redirects", api.DescribeRedirects)
+		space.POST("/redirects", api.CreateRedirect)
+		space.PATCH("/redirects/:redirect_id", api.UpdateRedirect)
+		space.DELETE("/redirects/:redirect_id", api.DeleteRedirect)
+
+		space.GET("/variables", api.DescribeVariables)
+		space.POST("/variables", api.CreateVariable)
+		space.PATCH("/variables/:variable_id", api.UpdateVariable)
+		space.DELETE("/variables/:variable_id", api.DeleteVariable)
+
+		space.GET("/members", api.DescribeMembers)
+		space.POST("/members", api.CreateMember)
+		space.PATCH("/members/:account_id", api.UpdateMember)
+		space.DELETE("/members/:account_id", api.DeleteMember)
 
 		page := space.Group("/pages/:id", api.SetPage)
 		page.GET("", api.DescribePage)
 		page.PATCH("", api.UpdatePage)
-
-		group.POST("/markdown/preview", api.PreviewMarkdown)
+		page.POST("/copy", api.CopyPage)
+		page.GET("/backlinks", api.DescribeBacklinks)
+		page.GET("/restrictions", api.DescribePageRestrictions)
+		page.POST("/restrictions", api.CreatePageRestriction)
+		page.DELETE("/restrictions/:restriction_id", api.DeletePageRestriction)
+		page.GET("/attachments", api.DescribeAttachments)
+		page.POST("/attachments", api.CreateAttachment)
+		page.DELETE("/attachments/:attachment_id", api.DeleteAttachment)
+
+		admin := group.Group("/admin", api.AdminMiddleware)
+		admin.POST("/fsck", api.CheckPageTree)
 	}
 
 	return router
@@ -3,13 +3,21 @@ package config
 import (
 	"github.com/fox-gonic/fox/database"
 	"github.com/fox-gonic/fox/logger"
+
+	"github.com/miclle/space/pkg/diagram"
+	"github.com/miclle/space/pkg/linkcheck"
+	"github.com/miclle/space/pkg/storage"
 )
 
 // Configuration type
 type Configuration struct {
-	Addr     string           `mapstructure:"addr"`
-	Secret   string           `mapstructure:"secret"`
-	Env      string           `mapstructure:"env"`
-	Logger   *logger.Config   `mapstructure:"logger"`
-	Database *database.Config `mapstructure:"database"`
+	Addr      string            `mapstructure:"addr"`
+	Secret    string            `mapstructure:"secret"`
+	Env       string            `mapstructure:"env"`
+	Logger    *logger.Config    `mapstructure:"logger"`
+	Database  *database.Config  `mapstructure:"database"`
+	Admins    []string          `mapstructure:"admins"`    // administrator account logins
+	Storage   *storage.Config   `mapstructure:"storage"`   // page attachments storage, default local `data/attachments`
+	LinkCheck *linkcheck.Config `mapstructure:"linkcheck"` // external urls checker of the broken link checks
+	Diagram   *diagram.Config   `mapstructure:"diagram"`   // server side renderers of the diagram code fences
 }
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/miclle/space/pkg/markdown"
+)
+
+// Attachment page attachment file, the content is kept in the storage backend
+type Attachment struct {
+	ID          int64  `json:"id"           gorm:"primaryKey"`
+	SpaceID     int64  `json:"-"            gorm:"index"`
+	PageID      int64  `json:"page_id"      gorm:"index"`
+	CreatorID   int64  `json:"creator_id"`
+	Name        string `json:"name"         gorm:"size:255"`
+	ContentType string `json:"content_type" gorm:"size:255"`
+	Size        int64  `json:"size"`
+	Checksum    string `json:"checksum"     gorm:"size:64"`              // sha256 hex of the content
+	Key         string `json:"-"            gorm:"uniqueIndex;size:255"` // storage object key
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+
+	// image size and resized variants
+	Width    int                  `json:"width,omitempty"`
+	Height   int                  `json:"height,omitempty"`
+	Variants []*AttachmentVariant `json:"variants,omitempty" gorm:"type:text;serializer:json"`
+
+	Space *Space `json:"-"`
+}
+
+// AttachmentVariant resized image of an image attachment
+type AttachmentVariant struct {
+	Name        string `json:"name"` // e.g. `320w` or `thumbnail`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// TableName attachment model table name
+func (Attachment) TableName() string {
+	return "space_attachments"
+}
+
+// Reference return the stable markdown url of the attachment, e.g. `attachment:42/diagram.png`
+func (attachment *Attachment) Reference() string {
+	return markdown.AttachmentScheme + strconv.FormatInt(attachment.ID, 10) + "/" + url.PathEscape(attachment.Name)
+}
+
+// URL return the website url of the attachment, e.g. `/attachments/42/diagram.png`
+func (attachment *Attachment) URL() string {
+	return markdown.AttachmentURL(attachment.Reference())
+}
+
+// Variant return the variant of the name, nil if not exists
+func (attachment *Attachment) Variant(name string) *AttachmentVariant {
+	for _, variant := range attachment.Variants {
+		if variant.Name == name {
+			return variant
+		}
+	}
+	return nil
+}
+
+// VariantKey return the storage object key of the variant, e.g. `spaces/1/01H2X-320w.png`
+func (attachment *Attachment) VariantKey(variant *AttachmentVariant) string {
+	ext := ".png"
+	if variant.ContentType == "image/jpeg" {
+		ext = ".jpg"
+	}
+	return strings.TrimSuffix(attachment.Key, path.Ext(attachment.Key)) + "-" + variant.Name + ext
+}
+
+// Image return the size and responsive variants for markdown, nil if the attachment is not an image
+func (attachment *Attachment) Image() *markdown.Image {
+	if attachment.Width == 0 || attachment.Height == 0 {
+		return nil
+	}
+
+	image := &markdown.Image{Width: attachment.Width, Height: attachment.Height}
+
+	for _, variant := range attachment.Variants {
+		if variant.Width < attachment.Width && strings.HasSuffix(variant.Name, "w") {
+			image.Variants = append(image.Variants, &markdown.ImageVariant{Name: variant.Name, Width: variant.Width})
+		}
+	}
+
+	return image
+}
+
+// MarshalJSON implement
+func (attachment *Attachment) MarshalJSON() ([]byte, error) {
+	type Alias Attachment
+	return json.Marshal(&struct {
+		*Alias
+		Reference string `json:"reference"`
+		URL       string `json:"url"`
+	}{
+		Alias:     (*Alias)(attachment),
+		Reference: attachment.Reference(),
+		URL:       attachment.URL(),
+	})
+}
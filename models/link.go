@@ -0,0 +1,33 @@
+package models
+
+// PageLink internal link of a page content to a page, the links of a page content are
+// rebuilt when the content is rendered, the backlinks of a page are the links to it
+type PageLink struct {
+	ID           int64 `json:"id"             gorm:"primaryKey"`
+	SpaceID      int64 `json:"space_id"       gorm:"index"`
+	PageID       int64 `json:"page_id"        gorm:"index"`
+	ContentID    int64 `json:"content_id"     gorm:"index"`
+	TargetPageID int64 `json:"target_page_id" gorm:"index"`
+	CreatedAt    int64 `json:"created_at"`
+}
+
+// TableName page link model table name
+func (PageLink) TableName() string {
+	return "space_page_links"
+}
+
+// PageInclude page included by a page content, the nested includes are recorded too, the contents including
+// a page are rendered again when the page is updated
+type PageInclude struct {
+	ID           int64 `json:"id"             gorm:"primaryKey"`
+	SpaceID      int64 `json:"space_id"       gorm:"index"`
+	PageID       int64 `json:"page_id"        gorm:"index"`
+	ContentID    int64 `json:"content_id"     gorm:"index"`
+	TargetPageID int64 `json:"target_page_id" gorm:"index"`
+	CreatedAt    int64 `json:"created_at"`
+}
+
+// TableName page include model table name
+func (PageInclude) TableName() string {
+	return "space_page_includes"
+}
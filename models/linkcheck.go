@@ -0,0 +1,63 @@
+package models
+
+// LinkCheckStatus link check job status
+type LinkCheckStatus string
+
+// LinkCheckStatus enum
+const (
+	LinkCheckStatusPending   LinkCheckStatus = "pending"
+	LinkCheckStatusRunning   LinkCheckStatus = "running"
+	LinkCheckStatusCompleted LinkCheckStatus = "completed"
+	LinkCheckStatusFailed    LinkCheckStatus = "failed"
+)
+
+// IsFinished return the link check is completed or failed
+func (t LinkCheckStatus) IsFinished() bool {
+	return t == LinkCheckStatusCompleted || t == LinkCheckStatusFailed
+}
+
+// LinkIssueKind broken link kind
+type LinkIssueKind string
+
+// LinkIssueKind enum
+const (
+	LinkIssuePage       LinkIssueKind = "page"       // the page does not exist
+	LinkIssueAnchor     LinkIssueKind = "anchor"     // the heading id does not exist in the page
+	LinkIssueAttachment LinkIssueKind = "attachment" // the attachment does not exist
+	LinkIssueExternal   LinkIssueKind = "external"   // the external url fails
+)
+
+// LinkIssue broken link in a page content
+type LinkIssue struct {
+	PageID    int64         `json:"page_id"`
+	ContentID int64         `json:"content_id"`
+	Lang      string        `json:"lang"`
+	Version   string        `json:"version"`
+	Title     string        `json:"title"`
+	Kind      LinkIssueKind `json:"kind"`
+	Link      string        `json:"link"`
+	Message   string        `json:"message,omitempty"`
+}
+
+// LinkCheck broken link check job of the rendered page contents of a space
+type LinkCheck struct {
+	ID         int64           `json:"id"          gorm:"primaryKey"`
+	SpaceID    int64           `json:"space_id"    gorm:"index"`
+	CreatorID  int64           `json:"creator_id"`
+	Status     LinkCheckStatus `json:"status"      gorm:"size:32"`
+	External   bool            `json:"external"` // check the external urls too
+	Contents   int64           `json:"contents"` // page contents checked
+	Links      int64           `json:"links"`    // links checked
+	Issues     []*LinkIssue    `json:"issues"      gorm:"type:text;serializer:json"`
+	Error      string          `json:"error,omitempty" gorm:"size:1024"`
+	StartedAt  int64           `json:"started_at"`
+	FinishedAt int64           `json:"finished_at"`
+	CreatedAt  int64           `json:"created_at"`
+	UpdatedAt  int64           `json:"updated_at"`
+	RunningID  *int64          `json:"-"           gorm:"uniqueIndex"` // the space id while pending or running
+}
+
+// TableName link check model table name
+func (LinkCheck) TableName() string {
+	return "space_link_checks"
+}
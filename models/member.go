@@ -0,0 +1,56 @@
+package models
+
+import "errors"
+
+var (
+	// ErrMemberRoleIsInvalid member role is invalid
+	ErrMemberRoleIsInvalid = errors.New("member role is invalid")
+)
+
+// MemberRole space member role
+type MemberRole string
+
+// MemberRole enum, ordered by permissions
+const (
+	MemberRoleViewer     MemberRole = "viewer"
+	MemberRoleEditor     MemberRole = "editor"
+	MemberRoleMaintainer MemberRole = "maintainer"
+	MemberRoleOwner      MemberRole = "owner"
+)
+
+var memberRoleLevels = map[MemberRole]int{
+	MemberRoleViewer:     1,
+	MemberRoleEditor:     2,
+	MemberRoleMaintainer: 3,
+	MemberRoleOwner:      4,
+}
+
+// IsValid return member role is valid
+func (role MemberRole) IsValid() error {
+	if _, exists := memberRoleLevels[role]; !exists {
+		return ErrMemberRoleIsInvalid
+	}
+	return nil
+}
+
+// Includes return the role has the permissions of the other role, e.g. maintainer includes editor
+func (role MemberRole) Includes(other MemberRole) bool {
+	return memberRoleLevels[other] > 0 && memberRoleLevels[role] >= memberRoleLevels[other]
+}
+
+// Member space member model
+type Member struct {
+	ID        int64      `json:"id"         gorm:"primaryKey"`
+	SpaceID   int64      `json:"-"          gorm:"uniqueIndex:space_member"`
+	AccountID int64      `json:"account_id" gorm:"uniqueIndex:space_member;index"`
+	Role      MemberRole `json:"role"       gorm:"size:32"`
+	CreatedAt int64      `json:"created_at"`
+	UpdatedAt int64      `json:"updated_at"`
+
+	Account *Account `json:"account,omitempty"`
+}
+
+// TableName member model table name
+func (Member) TableName() string {
+	return "space_members"
+}
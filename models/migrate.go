@@ -1,9 +1,14 @@
 package models
 
 import (
+	"strconv"
+
 	"github.com/fox-gonic/fox/database"
 )
 
+// pagePathIndex the unique index of the page paths in a space
+const pagePathIndex = "idx_space_page_path"
+
 // Migrate models
 func Migrate(db *database.Database) error {
 
@@ -11,12 +16,99 @@ func Migrate(db *database.Database) error {
 		&Account{},
 		&Authentication{},
 		&Space{},
+		&SpaceAlias{},
 		&Page{},
 		&PageContent{},
+		&Redirect{},
+		&Member{},
+		&PageRestriction{},
+		&Attachment{},
+		&PageLink{},
+		&PageInclude{},
+		&LinkCheck{},
+		&Variable{},
 	)
 	if err != nil {
 		return err
 	}
 
-	return err
+	if err := migratePagePaths(db); err != nil {
+		return err
+	}
+
+	return migrateSpaceCreators(db)
+}
+
+// migrateSpaceCreators add the creators as the owners of the spaces created before memberships,
+// then the permissions depend on the members only
+func migrateSpaceCreators(db *database.Database) error {
+
+	var spaces []*Space
+
+	err := db.Select("id", "creator_id").
+		Where("`creator_id` > 0 AND NOT EXISTS (?)", db.Model(&Member{}).Select("1").Where("`space_members`.`space_id` = `spaces`.`id`")).
+		Find(&spaces).Error
+	if err != nil {
+		return err
+	}
+
+	if len(spaces) == 0 {
+		return nil
+	}
+
+	members := make([]*Member, 0, len(spaces))
+	for _, space := range spaces {
+		members = append(members, &Member{SpaceID: space.ID, AccountID: space.CreatorID, Role: MemberRoleOwner})
+	}
+
+	return db.Create(&members).Error
+}
+
+// migratePagePaths give the pages created before slugs a slug, only the space homepages have the empty path,
+// then the paths are unique in the space
+func migratePagePaths(db *database.Database) error {
+
+	migrator := db.Migrator()
+
+	if migrator.HasIndex(&Page{}, pagePathIndex) {
+		return nil
+	}
+
+	var pages []*Page
+
+	err := db.Select("id", "space_id", "parent_id", "slug", "path").
+		Where("`slug` = '' AND `id` NOT IN (?)", db.Model(&Space{}).Select("homepage_id")).
+		Order("`space_id` ASC, `lft` ASC").
+		Find(&pages).Error
+	if err != nil {
+		return err
+	}
+
+	// the numeric paths are reserved for the page ids, so the slugs are `page-<id>`
+	for _, page := range pages {
+		var parent *Page
+		if page.ParentID.Int64 > 0 {
+			if err := db.Select("id", "path").Where("`id` = ?", page.ParentID.Int64).First(&parent).Error; err != nil {
+				return err
+			}
+		}
+
+		page.Slug = "page-" + strconv.FormatInt(page.ID, 10)
+		page.Path = page.Slug
+		if parent != nil && parent.Path != "" {
+			page.Path = parent.Path + "/" + page.Slug
+		}
+
+		if err := db.Model(page).UpdateColumns(map[string]interface{}{"slug": page.Slug, "path": page.Path}).Error; err != nil {
+			return err
+		}
+	}
+
+	if migrator.HasIndex(&Page{}, "space_page_path") {
+		if err := migrator.DropIndex(&Page{}, "space_page_path"); err != nil {
+			return err
+		}
+	}
+
+	return db.Exec("CREATE UNIQUE INDEX `" + pagePathIndex + "` ON `space_pages` (`space_id`, `path`)").Error
 }
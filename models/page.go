@@ -4,10 +4,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"strconv"
 
 	strip "github.com/grokify/html-strip-tags-go"
 	"gorm.io/gorm"
 	"gorm.io/plugin/soft_delete"
+
+	"github.com/miclle/space/pkg/markdown"
 )
 
 var (
@@ -52,13 +55,18 @@ type Page struct {
 	Depth         int           `json:"-"              nestedset:"depth"`
 	ChildrenCount int           `json:"children_count" nestedset:"children_count"`
 	SpaceID       int64         `json:"-"              nestedset:"scope"          gorm:"index"`
+	Slug          string        `json:"slug"                                      gorm:"size:255"`
+	Path          string        `json:"path"                                      gorm:"size:512"` // unique in the space, see migratePagePaths
 
 	Space           *Space       `json:"space,omitempty"`
 	Content         *PageContent `json:"-"`
 	FallbackContent *PageContent `json:"-"`
 
-	Children []*Page `json:"children,omitempty" gorm:"-"`
-	Parents  []*Page `json:"parents,omitempty"  gorm:"-"`
+	Children     []*Page `json:"children,omitempty"      gorm:"-"`
+	ChildrenNext int     `json:"children_next,omitempty" gorm:"-"` // cursor of the children not in the expanded tree
+	Parents      []*Page `json:"parents,omitempty"       gorm:"-"`
+	Previous     *Page   `json:"previous,omitempty"      gorm:"-"`
+	Next         *Page   `json:"next,omitempty"          gorm:"-"`
 }
 
 // TableName user model table name
@@ -96,6 +104,14 @@ func (page *Page) AfterFind(tx *gorm.DB) error {
 	return nil
 }
 
+// Pathname return page pathname in space website, fallback to page id
+func (page *Page) Pathname() string {
+	if page.Path != "" {
+		return page.Path
+	}
+	return strconv.FormatInt(page.ID, 10)
+}
+
 // MarshalJSON implement
 func (page *Page) MarshalJSON() ([]byte, error) {
 	type Alias Page
@@ -129,6 +145,13 @@ type PageContent struct {
 	Body       string     `json:"body"`
 	HTML       string     `json:"html"`
 
+	TOC []*markdown.Heading `json:"toc" gorm:"type:text;serializer:json"` // headings of the html
+
+	// front matter fields of the body
+	Tags        []string               `json:"tags"        gorm:"type:text;serializer:json"`
+	Description string                 `json:"description" gorm:"size:1024"`
+	Meta        map[string]interface{} `json:"meta"        gorm:"type:text;serializer:json"` // custom keys
+
 	CreatedAt int64                 `json:"created_at"`
 	UpdatedAt int64                 `json:"updated_at"`
 	DeletedAt soft_delete.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
@@ -147,6 +170,17 @@ func (page *PageContent) Text() string {
 	return strip.StripTags(page.HTML)
 }
 
+// Outline the h2 and h3 headings of the toc, the "On this page" navigation
+func (page *PageContent) Outline() []*markdown.Heading {
+	var headings []*markdown.Heading
+	for _, heading := range page.TOC {
+		if heading.Level == 2 || heading.Level == 3 {
+			headings = append(headings, heading)
+		}
+	}
+	return headings
+}
+
 // Pages pages type
 type Pages []*Page
 
@@ -160,16 +194,22 @@ func (nodes *Pages) Build() Pages {
 
 	for _, node := range *nodes {
 		nodeMap[node.ID] = node
-		if node.ParentID.Int64 == 0 {
-			root = append(root, node)
-		}
 	}
 
+	// nodes whose parent is not in the list are roots, e.g. filtered by parent or depth
 	for _, node := range *nodes {
 		if parent, exists := nodeMap[node.ParentID.Int64]; exists {
 			parent.Children = append(parent.Children, node)
+		} else {
+			root = append(root, node)
 		}
 	}
 
 	return root
 }
+
+// PageCursor cursor paginated pages
+type PageCursor struct {
+	Items Pages `json:"items"`
+	Next  int   `json:"next,omitempty"` // cursor of the next items, zero means no more items
+}
@@ -0,0 +1,18 @@
+package models
+
+// Redirect historical page path in space website
+type Redirect struct {
+	ID        int64  `json:"id"         gorm:"primaryKey"`
+	SpaceID   int64  `json:"-"          gorm:"uniqueIndex:space_redirect_path"`
+	Path      string `json:"path"       gorm:"uniqueIndex:space_redirect_path;size:512"`
+	PageID    int64  `json:"page_id"    gorm:"index"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+
+	Page *Page `json:"page,omitempty"`
+}
+
+// TableName redirect model table name
+func (Redirect) TableName() string {
+	return "space_redirects"
+}
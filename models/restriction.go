@@ -0,0 +1,27 @@
+package models
+
+// PageRestriction page read restriction, the restricted page and its descendants are readable
+// only by the accounts matching one of the page restrictions
+type PageRestriction struct {
+	ID        int64      `json:"id"         gorm:"primaryKey"`
+	SpaceID   int64      `json:"-"          gorm:"index"`
+	PageID    int64      `json:"page_id"    gorm:"index"`
+	AccountID int64      `json:"account_id"`                // the account, or
+	Role      MemberRole `json:"role"       gorm:"size:32"` // the space members group with the role at least
+	CreatedAt int64      `json:"created_at"`
+
+	Account *Account `json:"account,omitempty"`
+}
+
+// TableName page restriction model table name
+func (PageRestriction) TableName() string {
+	return "space_page_restrictions"
+}
+
+// Allows return the restriction allows the account with the space member role
+func (restriction *PageRestriction) Allows(accountID int64, role MemberRole) bool {
+	if restriction.AccountID > 0 {
+		return accountID > 0 && restriction.AccountID == accountID
+	}
+	return role.Includes(restriction.Role)
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSpaceSettingsIsInvalid space settings is invalid
+	ErrSpaceSettingsIsInvalid = errors.New("space settings is invalid")
+)
+
+// accentColorRegexp css hex color, e.g. `#375EAB`
+var accentColorRegexp = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// SpaceNavLink space website header navigation link
+type SpaceNavLink struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// SpaceSettings space website navigation and branding, stored as json with the space
+type SpaceSettings struct {
+	NavLinks    []*SpaceNavLink `json:"nav_links"`
+	Footer      string          `json:"footer"`       // markdown or html
+	FooterHTML  string          `json:"footer_html"`  // rendered footer
+	AccentColor string          `json:"accent_color"` // css hex color
+	Logo        string          `json:"logo"`         // logo image url
+	EditURL     string          `json:"edit_url"`     // "edit on" link template, e.g. `https://github.com/org/repo/edit/main/docs/{path}.md`
+}
+
+// IsValid return space settings is valid, urls are http(s) or absolute paths
+func (settings *SpaceSettings) IsValid() error {
+
+	if settings.AccentColor != "" && !accentColorRegexp.MatchString(settings.AccentColor) {
+		return ErrSpaceSettingsIsInvalid
+	}
+
+	urls := []string{settings.Logo, settings.EditURL}
+	for _, link := range settings.NavLinks {
+		if link == nil || strings.TrimSpace(link.Title) == "" || link.URL == "" {
+			return ErrSpaceSettingsIsInvalid
+		}
+		urls = append(urls, link.URL)
+	}
+
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return ErrSpaceSettingsIsInvalid
+		}
+
+		switch parsed.Scheme {
+		case "http", "https":
+		case "":
+			if !strings.HasPrefix(u, "/") {
+				return ErrSpaceSettingsIsInvalid
+			}
+		default:
+			return ErrSpaceSettingsIsInvalid
+		}
+	}
+
+	return nil
+}
+
+// EditLink return the "edit on" link of the page, the template placeholders are
+// `{space}` space key, `{lang}` content language, `{id}` page id and `{path}` page path
+func (settings *SpaceSettings) EditLink(space *Space, page *Page) string {
+
+	if settings == nil || settings.EditURL == "" || page == nil {
+		return ""
+	}
+
+	lang := space.Lang
+	if page.Content != nil && page.Content.Lang != "" {
+		lang = page.Content.Lang
+	}
+
+	return strings.NewReplacer(
+		"{space}", space.Key,
+		"{lang}", lang,
+		"{id}", strconv.FormatInt(page.ID, 10),
+		"{path}", page.Pathname(),
+	).Replace(settings.EditURL)
+}
@@ -12,6 +12,9 @@ import (
 var (
 	// ErrSpaceStatusIsInvalid space status is invalid
 	ErrSpaceStatusIsInvalid = errors.New("space status is invalid")
+
+	// ErrSpaceVisibilityIsInvalid space visibility is invalid
+	ErrSpaceVisibilityIsInvalid = errors.New("space visibility is invalid")
 )
 
 // SpaceStatus space status
@@ -34,19 +37,43 @@ func (t SpaceStatus) IsValid() error {
 	}
 }
 
+// SpaceVisibility space website audience
+type SpaceVisibility string
+
+// SpaceVisibility enum
+const (
+	SpaceVisibilityPublic   SpaceVisibility = "public"   // everyone
+	SpaceVisibilityInternal SpaceVisibility = "internal" // any signed-in account
+	SpaceVisibilityPrivate  SpaceVisibility = "private"  // space members only
+)
+
+// IsValid return space visibility is valid
+func (t SpaceVisibility) IsValid() error {
+	switch t {
+	case
+		SpaceVisibilityPublic, SpaceVisibilityInternal, SpaceVisibilityPrivate:
+		return nil
+	default:
+		return ErrSpaceVisibilityIsInvalid
+	}
+}
+
 // Space model
 type Space struct {
 	database.Model
-	Name         string      `json:"name"          gorm:"uniqueIndex;size:128"`
-	Key          string      `json:"key"           gorm:"uniqueIndex;size:128"`
-	Multilingual bool        `json:"multilingual"`                 // Enable multilingual
-	Lang         string      `json:"lang"          gorm:"size:32"` // default lang // TODO(m) enum type
-	FallbackLang string      `json:"fallback_lang" gorm:"size:32"` // fallback lang
-	HomepageID   int64       `json:"homepage_id"   gorm:"index"`
-	Description  string      `json:"description"`
-	Avatar       string      `json:"avatar"`
-	Status       SpaceStatus `json:"status"        gorm:"index;size:32"`
-	CreatorID    int64       `json:"-"`
+	Name         string          `json:"name"          gorm:"uniqueIndex;size:128"`
+	Key          string          `json:"key"           gorm:"uniqueIndex;size:128"`
+	Multilingual bool            `json:"multilingual"`                 // Enable multilingual
+	Lang         string          `json:"lang"          gorm:"size:32"` // default lang // TODO(m) enum type
+	FallbackLang string          `json:"fallback_lang" gorm:"size:32"` // fallback lang
+	HomepageID   int64           `json:"homepage_id"   gorm:"index"`
+	Description  string          `json:"description"`
+	Avatar       string          `json:"avatar"`
+	Status       SpaceStatus     `json:"status"        gorm:"index;size:32"`
+	Visibility   SpaceVisibility `json:"visibility"    gorm:"index;size:32;default:public"`
+	ArchivedAt   int64           `json:"archived_at"   gorm:"index"` // archived spaces are read-only
+	Settings     SpaceSettings   `json:"settings"      gorm:"type:text;serializer:json"`
+	CreatorID    int64           `json:"-"`
 
 	Homepage *Page `json:"homepage,omitempty" gorm:"foreignKey:HomepageID"`
 
@@ -68,13 +95,62 @@ func (space *Space) AfterFind(tx *gorm.DB) error {
 	return nil
 }
 
-// BeforeDelete gorm before delete callback
+// IsPublic return the space is visible to everyone, the spaces created before visibility are public
+func (space *Space) IsPublic() bool {
+	return space.Visibility == "" || space.Visibility == SpaceVisibilityPublic
+}
+
+// IsArchived return space is archived
+func (space *Space) IsArchived() bool {
+	return space.ArchivedAt > 0
+}
+
+// BeforeDelete gorm before delete callback, renames the name and key to free the unique indexes,
+// the new values are computed here instead of SQL functions to work on every database dialect
 func (space *Space) BeforeDelete(tx *gorm.DB) (err error) {
+	db := tx.Session(&gorm.Session{NewDB: true})
+
+	if space.Key == "" {
+		if err = db.First(space, space.ID).Error; err != nil {
+			return
+		}
+	}
+
 	now := time.Now().Unix()
 	updates := map[string]interface{}{
-		"`name`": gorm.Expr("concat(`name`, ?)", fmt.Sprintf(" [deleted-%d]", now)),
-		"`key`":  gorm.Expr("concat(`key`, ?)", fmt.Sprintf(" [deleted-%d]", now)),
+		"name": deletedValue(space.Name, now),
+		"key":  deletedValue(space.Key, now),
 	}
-	err = tx.Model(space).Where("`id` = ?", space.ID).UpdateColumns(updates).Error
+	if err = db.Model(&Space{}).Where("id = ?", space.ID).UpdateColumns(updates).Error; err != nil {
+		return
+	}
+
+	// the previous keys are freed too
+	err = db.Where("space_id = ?", space.ID).Delete(&SpaceAlias{}).Error
 	return
 }
+
+// deletedValue return the value with a deleted suffix, e.g. `docs [deleted-1672531200]`,
+// truncated to the 128 characters column size
+func deletedValue(value string, now int64) string {
+	suffix := fmt.Sprintf(" [deleted-%d]", now)
+
+	if runes := []rune(value); len(runes)+len(suffix) > 128 {
+		value = string(runes[:128-len(suffix)])
+	}
+
+	return value + suffix
+}
+
+// SpaceAlias previous key of a renamed space, the website and API urls with the key redirect to the space
+type SpaceAlias struct {
+	ID        int64  `json:"id"         gorm:"primaryKey"`
+	SpaceID   int64  `json:"space_id"   gorm:"index"`
+	Key       string `json:"key"        gorm:"uniqueIndex;size:128"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// TableName space alias model table name
+func (SpaceAlias) TableName() string {
+	return "space_aliases"
+}
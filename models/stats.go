@@ -0,0 +1,34 @@
+package models
+
+// StatsCount contents count of a status, language or version
+type StatsCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// SpaceContributor account contributions in the stats window
+type SpaceContributor struct {
+	AccountID     int64    `json:"account_id"`
+	Account       *Account `json:"account,omitempty"`
+	Contents      int64    `json:"contents"`        // page contents created or updated in the window
+	LastUpdatedAt int64    `json:"last_updated_at"` // the latest update of the contents
+}
+
+// SpaceStats space statistics
+type SpaceStats struct {
+	SpaceID  int64 `json:"space_id"`
+	Pages    int64 `json:"pages"`
+	Contents int64 `json:"contents"`
+	Words    int64 `json:"words"`
+
+	Statuses []*StatsCount `json:"statuses"`
+	Langs    []*StatsCount `json:"langs"`
+	Versions []*StatsCount `json:"versions"`
+
+	RecentlyUpdated []*Page             `json:"recently_updated"`
+	Contributors    []*SpaceContributor `json:"contributors"`
+	StalePages      []*Page             `json:"stale_pages"` // pages not updated since StaleBefore
+
+	Since       int64 `json:"since"`        // start of the contributors window, unix seconds
+	StaleBefore int64 `json:"stale_before"` // unix seconds
+}
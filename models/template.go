@@ -0,0 +1,12 @@
+package models
+
+// SpaceTemplate packaged space template, a page tree skeleton embedded in the binary
+type SpaceTemplate struct {
+	Name         string          `json:"name"          yaml:"-"`
+	Title        string          `json:"title"         yaml:"title"`
+	Description  string          `json:"description"   yaml:"description"`
+	Multilingual bool            `json:"multilingual"  yaml:"multilingual"`
+	Lang         string          `json:"lang"          yaml:"lang"`
+	FallbackLang string          `json:"fallback_lang" yaml:"fallback_lang"`
+	Visibility   SpaceVisibility `json:"visibility"    yaml:"visibility"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// TreeIssueKind page tree issue kind
+type TreeIssueKind string
+
+// TreeIssueKind enum
+const (
+	TreeIssueInterval      TreeIssueKind = "interval"       // lft is not less than rgt
+	TreeIssueOverlap       TreeIssueKind = "overlap"        // intervals overlap without nesting
+	TreeIssueParent        TreeIssueKind = "parent"         // interval parent differs from parent_id
+	TreeIssueDepth         TreeIssueKind = "depth"          // depth differs from parent_id chain
+	TreeIssueChildrenCount TreeIssueKind = "children_count" // children_count differs from children
+	TreeIssueOrphan        TreeIssueKind = "orphan"         // parent_id page does not exist
+	TreeIssueCycle         TreeIssueKind = "cycle"          // parent_id chain is a cycle
+	TreeIssueSpace         TreeIssueKind = "space"          // space_id differs from the parent
+	TreeIssueCrossSpace    TreeIssueKind = "cross_space"    // parent is in another space out of the checked space
+)
+
+// TreeIssue page tree integrity issue
+type TreeIssue struct {
+	SpaceID int64         `json:"space_id"`
+	PageID  int64         `json:"page_id"`
+	Kind    TreeIssueKind `json:"kind"`
+	Message string        `json:"message"`
+}
+
+// TreeReport page tree integrity check report
+type TreeReport struct {
+	SpaceID  int64        `json:"space_id,omitempty"`
+	Pages    int          `json:"pages"`
+	Issues   []*TreeIssue `json:"issues"`
+	Repaired int          `json:"repaired"` // pages rebuilt, or would be rebuilt in dry run mode
+	DryRun   bool         `json:"dry_run"`
+}
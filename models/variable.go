@@ -0,0 +1,18 @@
+package models
+
+// Variable space variable substituted in the page bodies, e.g. `{{ var "latest_sdk_version" }}`,
+// the variable of a version overrides the variable of the space with the same name
+type Variable struct {
+	ID        int64  `json:"id"         gorm:"primaryKey"`
+	SpaceID   int64  `json:"-"          gorm:"uniqueIndex:space_variable_name"`
+	Version   string `json:"version"    gorm:"uniqueIndex:space_variable_name;size:64"` // empty for the whole space
+	Name      string `json:"name"       gorm:"uniqueIndex:space_variable_name;size:64"`
+	Value     string `json:"value"      gorm:"size:1024"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// TableName variable model table name
+func (Variable) TableName() string {
+	return "space_variables"
+}
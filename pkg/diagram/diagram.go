@@ -0,0 +1,244 @@
+package diagram
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/miclle/space/pkg/storage"
+)
+
+// URLPath the website path of the cached svgs, e.g. `/diagrams/<sha256>.svg`
+const URLPath = "/diagrams/"
+
+// ErrKindIsNotSupported no renderer of the diagram kind is configured
+var ErrKindIsNotSupported = errors.New("diagram kind is not supported")
+
+// ErrSourceIsNotAllowed the diagram source references the local files
+var ErrSourceIsNotAllowed = errors.New("diagram source is not allowed")
+
+// ErrTooManyRenders the renders are busy until the timeout
+var ErrTooManyRenders = errors.New("too many diagram renders")
+
+// dotFileRegexp the graphviz attributes of the local files, e.g. `image="/etc/passwd"`
+var dotFileRegexp = regexp.MustCompile(`(?i)\b(image|imagepath|shapefile|fontpath)\s*=`)
+
+// nameRegexp the cached svg names
+var nameRegexp = regexp.MustCompile(`^[0-9a-f]{64}\.svg$`)
+
+// Renderer render the diagram source to svg
+type Renderer interface {
+	Render(ctx context.Context, source []byte) ([]byte, error)
+}
+
+// Command render the diagram with a local executable, the source is written to stdin and the svg is read
+// from stdout, e.g. `dot -Tsvg`
+type Command struct {
+	Name   string
+	Args   []string
+	Env    []string       // appended to the process environment, e.g. `PLANTUML_SECURITY_PROFILE=SANDBOX`
+	Unset  []string       // removed from the process environment, e.g. `GV_FILE_PATH`
+	Reject *regexp.Regexp // the sources matched are rejected with ErrSourceIsNotAllowed
+}
+
+// Render run the command
+func (command *Command) Render(ctx context.Context, source []byte) ([]byte, error) {
+
+	if command.Reject != nil && command.Reject.Match(source) {
+		return nil, ErrSourceIsNotAllowed
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, command.Name, command.Args...)
+	cmd.Env = command.environ()
+	cmd.Stdin = bytes.NewReader(source)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed, err: %w, stderr: %s", command.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// environ return the process environment of the command
+func (command *Command) environ() []string {
+
+	var env []string
+	for _, value := range os.Environ() {
+		name, _, _ := strings.Cut(value, "=")
+		if !contains(command.Unset, name) {
+			env = append(env, value)
+		}
+	}
+
+	return append(env, command.Env...)
+}
+
+// Config executables of the diagram kinds, the kinds without executable are rendered by the website script
+type Config struct {
+	Dot         string        `mapstructure:"dot"`         // graphviz, e.g. `dot`
+	PlantUML    string        `mapstructure:"plantuml"`    // e.g. `plantuml`
+	Mermaid     string        `mapstructure:"mermaid"`     // mermaid-cli, e.g. `mmdc`
+	Timeout     time.Duration `mapstructure:"timeout"`     // render timeout, default 10s
+	Concurrency int           `mapstructure:"concurrency"` // concurrent renders, default 2
+}
+
+// Cache render the diagrams with the renderers of the kinds, the svgs are stored by the sha256 of the kind
+// and the source, so a diagram is rendered once
+type Cache struct {
+	Storage   storage.Storage
+	Renderers map[string]Renderer // the markdown diagram kinds, e.g. `dot`
+	Timeout   time.Duration
+	renders   chan struct{}
+}
+
+// New return the cache of the configured executables
+func New(config *Config, store storage.Storage) *Cache {
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	cache := &Cache{
+		Storage:   store,
+		Renderers: map[string]Renderer{},
+		Timeout:   config.Timeout,
+	}
+
+	if cache.Timeout <= 0 {
+		cache.Timeout = 10 * time.Second
+	}
+
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 2
+	}
+	cache.renders = make(chan struct{}, concurrency)
+
+	// graphviz loads no files when SERVER_NAME is set and GV_FILE_PATH is not
+	if config.Dot != "" {
+		cache.Renderers["dot"] = &Command{
+			Name:   config.Dot,
+			Args:   []string{"-Tsvg"},
+			Env:    []string{"SERVER_NAME=space"},
+			Unset:  []string{"GV_FILE_PATH", "SERVER_NAME"},
+			Reject: dotFileRegexp,
+		}
+	}
+	if config.PlantUML != "" {
+		cache.Renderers["plantuml"] = &Command{
+			Name:  config.PlantUML,
+			Args:  []string{"-tsvg", "-pipe"},
+			Env:   []string{"PLANTUML_SECURITY_PROFILE=SANDBOX"},
+			Unset: []string{"PLANTUML_SECURITY_PROFILE", "PLANTUML_ALLOWLIST_URL", "PLANTUML_LIMIT_SIZE"},
+		}
+	}
+	if config.Mermaid != "" {
+		cache.Renderers["mermaid"] = &Command{Name: config.Mermaid, Args: []string{"--input", "-", "--output", "-", "--outputFormat", "svg"}}
+	}
+
+	return cache
+}
+
+// Cached return the url of the cached svg, storage.ErrNotFound if the diagram is not rendered yet,
+// the renderers are not run, implement markdown.DiagramRenderer
+func (cache *Cache) Cached(kind, source string) (string, error) {
+
+	if _, ok := cache.Renderers[kind]; !ok || cache.Storage == nil {
+		return "", ErrKindIsNotSupported
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cache.Timeout)
+	defer cancel()
+
+	name := svgName(kind, source)
+
+	reader, err := cache.Storage.Get(ctx, key(name))
+	if err != nil {
+		return "", err
+	}
+	reader.Close()
+
+	return URLPath + name, nil
+}
+
+// Render return the url of the cached svg, the diagram is rendered if it is not cached,
+// implement markdown.DiagramRenderer
+func (cache *Cache) Render(kind, source string) (string, error) {
+
+	if url, err := cache.Cached(kind, source); err == nil || !errors.Is(err, storage.ErrNotFound) {
+		return url, err
+	}
+
+	var (
+		renderer = cache.Renderers[kind]
+		name     = svgName(kind, source)
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cache.Timeout)
+	defer cancel()
+
+	// the concurrent renders wait for a free slot until the timeout
+	if cache.renders != nil {
+		select {
+		case cache.renders <- struct{}{}:
+			defer func() { <-cache.renders }()
+		case <-ctx.Done():
+			return "", ErrTooManyRenders
+		}
+	}
+
+	svg, err := renderer.Render(ctx, []byte(source))
+	if err != nil {
+		return "", err
+	}
+
+	if err := cache.Storage.Put(ctx, key(name), bytes.NewReader(svg), "image/svg+xml"); err != nil {
+		return "", err
+	}
+
+	return URLPath + name, nil
+}
+
+// Open return the cached svg of the name, e.g. `<sha256>.svg`
+func (cache *Cache) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+
+	if !nameRegexp.MatchString(name) || cache.Storage == nil {
+		return nil, storage.ErrNotFound
+	}
+
+	return cache.Storage.Get(ctx, key(name))
+}
+
+// contains report whether the names contain the name
+func contains(names []string, name string) bool {
+	for _, item := range names {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+// svgName return the cached svg name of the diagram, the sha256 of the kind and the source
+func svgName(kind, source string) string {
+	sum := sha256.Sum256([]byte(kind + "\n" + source))
+	return hex.EncodeToString(sum[:]) + ".svg"
+}
+
+// key return the storage key of the svg name
+func key(name string) string {
+	return "diagrams/" + name
+}
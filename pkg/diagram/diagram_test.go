@@ -0,0 +1,133 @@
+package diagram
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/miclle/space/pkg/storage"
+)
+
+type renderFunc func(ctx context.Context, source []byte) ([]byte, error)
+
+func (fn renderFunc) Render(ctx context.Context, source []byte) ([]byte, error) {
+	return fn(ctx, source)
+}
+
+func TestCache(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := storage.NewLocal(&storage.LocalConfig{Root: t.TempDir()})
+	assert.Nil(err)
+
+	var renders int
+
+	cache := New(nil, store)
+	cache.Renderers["dot"] = renderFunc(func(ctx context.Context, source []byte) ([]byte, error) {
+		renders++
+		return []byte("<svg>" + string(source) + "</svg>"), nil
+	})
+
+	// the kinds without renderer are rendered in the browser
+	_, err = cache.Render("mermaid", "graph TD")
+	assert.ErrorIs(err, ErrKindIsNotSupported)
+
+	// the cache lookups do not render
+	_, err = cache.Cached("dot", "digraph { a -> b }")
+	assert.ErrorIs(err, storage.ErrNotFound)
+	assert.Equal(0, renders)
+
+	url, err := cache.Render("dot", "digraph { a -> b }")
+	assert.Nil(err)
+	assert.Regexp(`^/diagrams/[0-9a-f]{64}\.svg$`, url)
+
+	cached, err := cache.Cached("dot", "digraph { a -> b }")
+	assert.Nil(err)
+	assert.Equal(url, cached)
+
+	// the same source is rendered once
+	cached, err = cache.Render("dot", "digraph { a -> b }")
+	assert.Nil(err)
+	assert.Equal(url, cached)
+	assert.Equal(1, renders)
+
+	reader, err := cache.Open(context.Background(), strings.TrimPrefix(url, URLPath))
+	assert.Nil(err)
+	svg, err := io.ReadAll(reader)
+	assert.Nil(err)
+	assert.Nil(reader.Close())
+	assert.Equal("<svg>digraph { a -> b }</svg>", string(svg))
+
+	// the names are validated, no other storage objects are served
+	_, err = cache.Open(context.Background(), "../spaces/1/secret.png")
+	assert.ErrorIs(err, storage.ErrNotFound)
+}
+
+func TestCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("GV_FILE_PATH", "/")
+
+	cache := New(&Config{Dot: "env", PlantUML: "env"}, nil)
+
+	// the executables are sandboxed by the environment
+	dot := cache.Renderers["dot"].(*Command)
+	dot.Args = nil
+	env, err := dot.Render(context.Background(), []byte("digraph { a -> b }"))
+	assert.Nil(err)
+	assert.Contains(string(env), "SERVER_NAME=space\n")
+	assert.NotContains(string(env), "GV_FILE_PATH=")
+
+	plantuml := cache.Renderers["plantuml"].(*Command)
+	plantuml.Args = nil
+	env, err = plantuml.Render(context.Background(), []byte("@startuml\n@enduml"))
+	assert.Nil(err)
+	assert.Contains(string(env), "PLANTUML_SECURITY_PROFILE=SANDBOX\n")
+
+	// the graphviz sources of the local files are rejected
+	for _, source := range []string{
+		`digraph { a [image="/etc/passwd"] }`,
+		`digraph { imagepath="/etc"; a [IMAGE = "passwd"] }`,
+		`digraph { a [shape=custom, shapefile="/etc/passwd"] }`,
+	} {
+		_, err = dot.Render(context.Background(), []byte(source))
+		assert.ErrorIs(err, ErrSourceIsNotAllowed, source)
+	}
+}
+
+func TestCacheConcurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	store, err := storage.NewLocal(&storage.LocalConfig{Root: t.TempDir()})
+	assert.Nil(err)
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	cache := New(&Config{Concurrency: 1, Timeout: 100 * time.Millisecond}, store)
+	cache.Renderers["dot"] = renderFunc(func(ctx context.Context, source []byte) ([]byte, error) {
+		close(started)
+		<-release
+		return []byte("<svg/>"), nil
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := cache.Render("dot", "digraph { a }")
+		done <- err
+	}()
+	<-started
+
+	// the render waits for the busy slot until the timeout
+	_, err = cache.Render("dot", "digraph { b }")
+	assert.ErrorIs(err, ErrTooManyRenders)
+
+	close(release)
+	assert.Nil(<-done)
+}
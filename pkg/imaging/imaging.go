@@ -0,0 +1,145 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+
+	// decoders
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+)
+
+// image processing limits
+const (
+	ThumbnailSize = 240         // thumbnail bounding box
+	ThumbnailName = "thumbnail" // thumbnail variant name
+	MaxPixels     = 64 << 20    // larger images are not decoded, e.g. decompression bombs
+
+	jpegQuality = 82
+)
+
+var (
+	// Widths responsive variant widths
+	Widths = []int{320, 768, 1280}
+
+	// resizedFormats formats with variants, animated gif images keep the size only
+	resizedFormats = map[string]bool{"jpeg": true, "png": true, "webp": true}
+)
+
+// ErrImageTooLarge image pixels exceed MaxPixels
+var ErrImageTooLarge = errors.New("image is too large")
+
+// Variant resized image
+type Variant struct {
+	Name        string // `320w`, `768w`, `1280w` or `thumbnail`
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Image decoded image info and the resized variants
+type Image struct {
+	Format   string // jpeg, png, gif or webp
+	Width    int
+	Height   int
+	Variants []*Variant
+}
+
+// Process decode the image size and create the responsive variants narrower than the image
+// and the thumbnail, animated gif images keep the size only
+func Process(content []byte) (*Image, error) {
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Image{Format: format, Width: config.Width, Height: config.Height}
+
+	if !resizedFormats[format] {
+		return info, nil
+	}
+
+	if config.Width*config.Height > MaxPixels {
+		return info, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, width := range Widths {
+		if width >= info.Width {
+			break
+		}
+
+		variant, err := resize(src, fmt.Sprintf("%dw", width), width, info.Height*width/info.Width, format)
+		if err != nil {
+			return nil, err
+		}
+		info.Variants = append(info.Variants, variant)
+	}
+
+	// the thumbnail fits in the bounding box
+	width, height := info.Width, info.Height
+	if width > ThumbnailSize || height > ThumbnailSize {
+		if width >= height {
+			width, height = ThumbnailSize, height*ThumbnailSize/width
+		} else {
+			width, height = width*ThumbnailSize/height, ThumbnailSize
+		}
+	}
+
+	thumbnail, err := resize(src, ThumbnailName, width, height, "png")
+	if err != nil {
+		return nil, err
+	}
+	info.Variants = append(info.Variants, thumbnail)
+
+	return info, nil
+}
+
+// resize scale the image and encode as jpeg for jpeg images, otherwise as png
+func resize(src image.Image, name string, width, height int, format string) (*Variant, error) {
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	var (
+		buf     = new(bytes.Buffer)
+		variant = &Variant{Name: name, Width: width, Height: height}
+		err     error
+	)
+
+	if format == "jpeg" {
+		variant.ContentType = "image/jpeg"
+		err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		variant.ContentType = "image/png"
+		err = png.Encode(buf, dst)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	variant.Data = buf.Bytes()
+
+	return variant, nil
+}
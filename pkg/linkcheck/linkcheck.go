@@ -0,0 +1,213 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Document links and anchors of the html document
+type Document struct {
+	Links   []string        // href of the links and src of the images, in document order
+	Anchors map[string]bool // element ids, e.g. the auto heading ids
+}
+
+// Parse return the links and anchors of the html fragment
+func Parse(content string) (*Document, error) {
+
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		Anchors: map[string]bool{},
+	}
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			for _, attr := range node.Attr {
+				switch {
+				case attr.Key == "id" || (attr.Key == "name" && node.Data == "a"):
+					doc.Anchors[attr.Val] = true
+				case attr.Key == "href" && node.Data == "a", attr.Key == "src" && node.Data == "img":
+					if link := strings.TrimSpace(attr.Val); link != "" {
+						doc.Links = append(doc.Links, link)
+					}
+				}
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+
+	for _, node := range nodes {
+		walk(node)
+	}
+
+	return doc, nil
+}
+
+// ----------------------------------------------------------------------------
+
+// Checker check the external url is reachable
+type Checker interface {
+	Check(ctx context.Context, url string) error
+}
+
+// StatusError the url responds with an error status
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+var (
+	// ErrAddressNotAllowed the url resolves to a private, loopback, link-local or unspecified address
+	ErrAddressNotAllowed = errors.New("address is not allowed")
+
+	// ErrTooManyRedirects the url redirects more than the max redirects
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// Config http checker config
+type Config struct {
+	Timeout      time.Duration `mapstructure:"timeout"`       // request timeout, default 10s
+	UserAgent    string        `mapstructure:"user_agent"`    // default `space-linkcheck/1.0`
+	MaxRedirects int           `mapstructure:"max_redirects"` // default 5
+	AllowPrivate bool          `mapstructure:"allow_private"` // check the urls of the private networks too
+}
+
+// HTTPChecker check the urls with HEAD requests, falls back to GET if HEAD is not allowed
+type HTTPChecker struct {
+	Client    *http.Client
+	UserAgent string
+}
+
+// allowedAddress reject the connections to the private, loopback, link-local and unspecified addresses,
+// the address is resolved by the dialer, so the dns names and the redirects are checked too
+func allowedAddress(network, address string, _ syscall.RawConn) error {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+	}
+
+	return nil
+}
+
+// New return the http checker of the config
+func New(config *Config) *HTTPChecker {
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	var (
+		dialer       = &net.Dialer{Timeout: 10 * time.Second}
+		maxRedirects = config.MaxRedirects
+	)
+
+	if !config.AllowPrivate {
+		dialer.Control = allowedAddress
+	}
+
+	if maxRedirects <= 0 {
+		maxRedirects = 5
+	}
+
+	checker := &HTTPChecker{
+		Client: &http.Client{
+			Timeout: config.Timeout,
+			// the proxy would be dialed instead of the url host
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 10 * time.Second,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return ErrTooManyRedirects
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("%w: %s", ErrAddressNotAllowed, req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+		UserAgent: config.UserAgent,
+	}
+
+	if checker.Client.Timeout <= 0 {
+		checker.Client.Timeout = 10 * time.Second
+	}
+	if checker.UserAgent == "" {
+		checker.UserAgent = "space-linkcheck/1.0"
+	}
+
+	return checker
+}
+
+// Check return StatusError if the url responds with 4xx or 5xx
+func (checker *HTTPChecker) Check(ctx context.Context, url string) error {
+
+	status, err := checker.request(ctx, http.MethodHead, url)
+	if err != nil {
+		return err
+	}
+
+	// some servers do not implement HEAD
+	if status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden {
+		if status, err = checker.request(ctx, http.MethodGet, url); err != nil {
+			return err
+		}
+	}
+
+	if status >= http.StatusBadRequest {
+		return &StatusError{StatusCode: status}
+	}
+
+	return nil
+}
+
+func (checker *HTTPChecker) request(ctx context.Context, method, url string) (int, error) {
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("User-Agent", checker.UserAgent)
+
+	resp, err := checker.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain a little of the body to reuse the connection
+	_, _ = io.CopyN(io.Discard, resp.Body, 4<<10)
+
+	return resp.StatusCode, nil
+}
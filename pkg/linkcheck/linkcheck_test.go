@@ -0,0 +1,49 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPChecker(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	// the loopback server is not checked by default
+	err := New(nil).Check(ctx, server.URL+"/ok")
+	assert.ErrorIs(err, ErrAddressNotAllowed)
+
+	checker := New(&Config{AllowPrivate: true, MaxRedirects: 3})
+
+	assert.Nil(checker.Check(ctx, server.URL+"/ok"))
+
+	var status *StatusError
+	assert.ErrorAs(checker.Check(ctx, server.URL+"/gone"), &status)
+	assert.Equal(http.StatusNotFound, status.StatusCode)
+
+	assert.ErrorIs(checker.Check(ctx, server.URL+"/loop"), ErrTooManyRedirects)
+}
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	doc, err := Parse(`<h2 id="usage">Usage</h2><a href="#usage">usage</a> <img src="/attachments/1/logo.png">`)
+	assert.Nil(err)
+	assert.Equal([]string{"#usage", "/attachments/1/logo.png"}, doc.Links)
+	assert.True(doc.Anchors["usage"])
+}
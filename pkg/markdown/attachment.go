@@ -0,0 +1,137 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// attachment url scheme, e.g. `![diagram](attachment:42/diagram.png)` is rendered as `/attachments/42/diagram.png`
+const (
+	AttachmentScheme = "attachment:"
+	AttachmentPath   = "/attachments/"
+)
+
+// imageSizes the rendered width of the responsive images
+const imageSizes = "(max-width: 1280px) 100vw, 1280px"
+
+// Image size and responsive variants of an attachment image
+type Image struct {
+	Width    int
+	Height   int
+	Variants []*ImageVariant
+}
+
+// ImageVariant resized attachment image, served with the `v` query, e.g. `/attachments/42/diagram.png?v=320w`
+type ImageVariant struct {
+	Name  string
+	Width int
+}
+
+// AttachmentImage return the image of the attachment id, nil if the attachment is not an image
+type AttachmentImage func(id int64) *Image
+
+// attachmentImageKey parser context key of the AttachmentImage option
+var attachmentImageKey = parser.NewContextKey()
+
+// AttachmentURL return the website url of the attachment reference, e.g. `attachment:42/diagram.png`
+func AttachmentURL(reference string) string {
+	if !strings.HasPrefix(reference, AttachmentScheme) {
+		return reference
+	}
+	return AttachmentPath + strings.TrimPrefix(reference, AttachmentScheme)
+}
+
+// AttachmentID return the attachment id of the reference, zero if the reference is not an attachment url
+func AttachmentID(reference string) int64 {
+	if !strings.HasPrefix(reference, AttachmentScheme) {
+		return 0
+	}
+
+	id, _, _ := strings.Cut(strings.TrimPrefix(reference, AttachmentScheme), "/")
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// attachmentTransformer rewrite the `attachment:` destinations of links and images,
+// the known attachment images get the size and srcset attributes
+type attachmentTransformer struct{}
+
+func (attachmentTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	scheme := []byte(AttachmentScheme)
+
+	resolve, _ := pc.Get(attachmentImageKey).(AttachmentImage)
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := node.(type) {
+		case *ast.Link:
+			if bytes.HasPrefix(n.Destination, scheme) {
+				n.Destination = []byte(AttachmentURL(string(n.Destination)))
+			}
+		case *ast.Image:
+			if !bytes.HasPrefix(n.Destination, scheme) {
+				break
+			}
+
+			var (
+				reference = string(n.Destination)
+				image     *Image
+			)
+
+			n.Destination = []byte(AttachmentURL(reference))
+
+			if resolve != nil {
+				image = resolve(AttachmentID(reference))
+			}
+
+			if image != nil && image.Width > 0 && image.Height > 0 {
+				setImageAttributes(n, image)
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+}
+
+// setImageAttributes set the size attributes to reserve the layout space, and the srcset of the variants
+func setImageAttributes(n *ast.Image, image *Image) {
+
+	n.SetAttributeString("width", []byte(strconv.Itoa(image.Width)))
+	n.SetAttributeString("height", []byte(strconv.Itoa(image.Height)))
+	n.SetAttributeString("loading", []byte("lazy"))
+
+	if len(image.Variants) == 0 {
+		return
+	}
+
+	var (
+		src     = string(n.Destination)
+		sources = make([]string, 0, len(image.Variants)+1)
+	)
+
+	for _, variant := range image.Variants {
+		sources = append(sources, fmt.Sprintf("%s?v=%s %dw", src, variant.Name, variant.Width))
+	}
+	sources = append(sources, fmt.Sprintf("%s %dw", src, image.Width))
+
+	n.SetAttributeString("srcset", []byte(strings.Join(sources, ", ")))
+	n.SetAttributeString("sizes", []byte(imageSizes))
+}
+
+// attachmentTransformerPriority run after the inline parsers
+var attachmentTransformerPriority = util.Prioritized(attachmentTransformer{}, 100)
@@ -0,0 +1,246 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// container names
+const (
+	ContainerNote    = "note"
+	ContainerWarning = "warning"
+	ContainerDanger  = "danger"
+	ContainerTabs    = "tabs"
+
+	containerTab = "tab" // a tab panel of the tabs, created from the code blocks
+)
+
+// admonitions the callout containers and their default titles
+var admonitions = map[string]string{
+	ContainerNote:    "Note",
+	ContainerWarning: "Warning",
+	ContainerDanger:  "Danger",
+}
+
+// tabTitles display names of the common code languages, other languages are displayed as is
+var tabTitles = map[string]string{
+	"bash":       "Shell",
+	"c":          "C",
+	"cpp":        "C++",
+	"csharp":     "C#",
+	"curl":       "cURL",
+	"go":         "Go",
+	"java":       "Java",
+	"javascript": "JavaScript",
+	"js":         "JavaScript",
+	"kotlin":     "Kotlin",
+	"php":        "PHP",
+	"python":     "Python",
+	"ruby":       "Ruby",
+	"rust":       "Rust",
+	"sh":         "Shell",
+	"shell":      "Shell",
+	"swift":      "Swift",
+	"ts":         "TypeScript",
+	"typescript": "TypeScript",
+}
+
+// KindContainer node kind of the `:::` containers
+var KindContainer = ast.NewNodeKind("Container")
+
+// Container block of the `:::name title` and `:::` lines, the closing line has as many colons as the opening
+type Container struct {
+	ast.BaseBlock
+	Name  string
+	Title string
+
+	fence int
+}
+
+// Kind implement ast.Node
+func (n *Container) Kind() ast.NodeKind {
+	return KindContainer
+}
+
+// Dump implement ast.Node
+func (n *Container) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.Name, "Title": n.Title}, nil)
+}
+
+// containerFence return the colons count and the rest of the line, zero if the line is not a fence
+func containerFence(line []byte) (int, []byte) {
+	line = util.TrimLeftSpace(line)
+
+	n := 0
+	for n < len(line) && line[n] == ':' {
+		n++
+	}
+
+	if n < 3 {
+		return 0, nil
+	}
+
+	return n, util.TrimRightSpace(line[n:])
+}
+
+// skipLine advance to the end of the fence line, the newline is left to the block parser
+func skipLine(reader text.Reader, line []byte, segment text.Segment) {
+	newline := 0
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		newline = 1
+	}
+	reader.Advance(segment.Stop - segment.Start - newline + segment.Padding)
+}
+
+// containerParser parse the admonitions and the tabs
+type containerParser struct{}
+
+func (containerParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (containerParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+
+	if pc.BlockOffset() < 0 {
+		return nil, parser.NoChildren
+	}
+
+	fence, rest := containerFence(line)
+	if fence == 0 {
+		return nil, parser.NoChildren
+	}
+
+	name, title, _ := strings.Cut(strings.TrimSpace(string(rest)), " ")
+	name = strings.ToLower(name)
+
+	if _, ok := admonitions[name]; !ok && name != ContainerTabs {
+		return nil, parser.NoChildren
+	}
+
+	node := &Container{Name: name, Title: strings.TrimSpace(title), fence: fence}
+	if node.Title == "" {
+		node.Title = admonitions[name]
+	}
+
+	skipLine(reader, line, segment)
+
+	return node, parser.HasChildren
+}
+
+func (containerParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+
+	if fence, rest := containerFence(line); fence == node.(*Container).fence && len(rest) == 0 {
+		skipLine(reader, line, segment)
+		return parser.Close
+	}
+
+	return parser.Continue | parser.HasChildren
+}
+
+func (containerParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {
+	n := node.(*Container)
+	if n.Name != ContainerTabs {
+		return
+	}
+
+	// one tab per code block, the other blocks belong to the tab before them
+	var (
+		source = reader.Source()
+		tab    *Container
+	)
+
+	for child := n.FirstChild(); child != nil; {
+		next := child.NextSibling()
+
+		if code, ok := child.(*ast.FencedCodeBlock); ok {
+			lang := string(code.Language(source))
+
+			title, exists := tabTitles[strings.ToLower(lang)]
+			if !exists {
+				title = lang
+			}
+			if title == "" {
+				title = "Text"
+			}
+
+			tab = &Container{Name: containerTab, Title: title}
+			n.InsertBefore(n, child, tab)
+		}
+
+		if tab != nil {
+			n.RemoveChild(n, child)
+			tab.AppendChild(tab, child)
+		}
+
+		child = next
+	}
+}
+
+func (containerParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (containerParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// containerRenderer render the containers as classed divs, the tabs are switched by the website script
+type containerRenderer struct{}
+
+func (r containerRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindContainer, r.render)
+}
+
+func (containerRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*Container)
+
+	var class, titleClass string
+
+	switch n.Name {
+	case ContainerTabs:
+		class = "tabs"
+	case containerTab:
+		class, titleClass = "tab-panel", "tab-title"
+	default:
+		class, titleClass = "admonition admonition-"+n.Name, "admonition-title"
+	}
+
+	_, _ = w.WriteString(`<div class="` + class + `">` + "\n")
+
+	if titleClass != "" {
+		_, _ = w.WriteString(`<p class="` + titleClass + `">`)
+		_, _ = w.Write(util.EscapeHTML([]byte(n.Title)))
+		_, _ = w.WriteString("</p>\n")
+	}
+
+	return ast.WalkContinue, nil
+}
+
+// containers goldmark extension of the `:::` blocks
+type containers struct{}
+
+// Containers extension of the `:::note`, `:::warning` and `:::danger` admonitions and the `:::tabs` groups
+var Containers goldmark.Extender = &containers{}
+
+func (e *containers) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// before the definition list parsers
+		parser.WithBlockParsers(util.Prioritized(containerParser{}, 100)),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(util.Prioritized(containerRenderer{}, 500)),
+	)
+}
@@ -0,0 +1,142 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// diagram kinds
+const (
+	DiagramMermaid  = "mermaid"
+	DiagramGraphviz = "dot"
+	DiagramPlantUML = "plantuml"
+)
+
+// diagramLanguages the fence languages of the diagram kinds
+var diagramLanguages = map[string]string{
+	"mermaid":  DiagramMermaid,
+	"dot":      DiagramGraphviz,
+	"graphviz": DiagramGraphviz,
+	"plantuml": DiagramPlantUML,
+	"puml":     DiagramPlantUML,
+}
+
+// DiagramRenderer return the url of the pre-rendered svg of the diagram source, the diagram is rendered
+// by the website script if the renderer returns an error
+type DiagramRenderer func(kind, source string) (url string, err error)
+
+// diagramRendererKey parser context key of the DiagramRenderer option
+var diagramRendererKey = parser.NewContextKey()
+
+// KindDiagram node kind of the diagram code fences
+var KindDiagram = ast.NewNodeKind("Diagram")
+
+// Diagram block of the ```mermaid, ```dot and ```plantuml code fences
+type Diagram struct {
+	ast.BaseBlock
+	Language string // the diagram kind, e.g. `dot`
+	Source   []byte
+	URL      string // the pre-rendered svg, empty if the diagram is rendered by the browser
+}
+
+// Kind implement ast.Node
+func (n *Diagram) Kind() ast.NodeKind {
+	return KindDiagram
+}
+
+// Dump implement ast.Node
+func (n *Diagram) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Language": n.Language, "URL": n.URL}, nil)
+}
+
+// diagramTransformer replace the diagram code fences with the diagram nodes
+type diagramTransformer struct{}
+
+func (diagramTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var (
+		source    = reader.Source()
+		render, _ = pc.Get(diagramRendererKey).(DiagramRenderer)
+		codes     []*ast.FencedCodeBlock
+	)
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if code, ok := node.(*ast.FencedCodeBlock); ok && entering {
+			if _, exists := diagramLanguages[string(code.Language(source))]; exists {
+				codes = append(codes, code)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, code := range codes {
+		diagram := &Diagram{Language: diagramLanguages[string(code.Language(source))]}
+
+		var buf bytes.Buffer
+		for i := 0; i < code.Lines().Len(); i++ {
+			line := code.Lines().At(i)
+			buf.Write(line.Value(source))
+		}
+		diagram.Source = buf.Bytes()
+
+		if render != nil {
+			if url, err := render(diagram.Language, buf.String()); err == nil {
+				diagram.URL = url
+			}
+		}
+
+		code.Parent().ReplaceChild(code.Parent(), code, diagram)
+	}
+}
+
+// diagramRenderer render the pre-rendered diagrams as images, the others as the source for the website script
+type diagramRenderer struct{}
+
+func (r diagramRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindDiagram, r.render)
+}
+
+func (diagramRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*Diagram)
+
+	_, _ = w.WriteString(`<div class="diagram diagram-` + n.Language + `">`)
+
+	if n.URL != "" {
+		_, _ = w.WriteString(`<img src="`)
+		_, _ = w.Write(util.EscapeHTML(util.URLEscape([]byte(n.URL), true)))
+		_, _ = w.WriteString(`" alt="` + n.Language + ` diagram">`)
+	} else {
+		_, _ = w.WriteString(`<pre class="diagram-source">`)
+		_, _ = w.Write(util.EscapeHTML(n.Source))
+		_, _ = w.WriteString(`</pre>`)
+	}
+
+	_, _ = w.WriteString("</div>\n")
+
+	return ast.WalkContinue, nil
+}
+
+// diagrams goldmark extension of the diagram code fences
+type diagrams struct{}
+
+// Diagrams extension of the ```mermaid, ```dot and ```plantuml code fences, pre-rendered by the
+// DiagramRenderer option
+var Diagrams goldmark.Extender = &diagrams{}
+
+func (e *diagrams) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithASTTransformers(util.Prioritized(diagramTransformer{}, 200)),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(util.Prioritized(diagramRenderer{}, 500)),
+	)
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrFrontMatterIsInvalid the front matter block is not a yaml mapping
+var ErrFrontMatterIsInvalid = errors.New("front matter is invalid")
+
+// FrontMatter the yaml block at the start of the page body, delimited by `---` lines, e.g.
+//
+//	---
+//	title: Install
+//	tags: [setup, linux]
+//	---
+type FrontMatter struct {
+	Title       string   `yaml:"title"       json:"title,omitempty"`
+	ShortTitle  string   `yaml:"short_title" json:"short_title,omitempty"`
+	Status      string   `yaml:"status"      json:"status,omitempty"`
+	Tags        []string `yaml:"tags"        json:"tags,omitempty"`
+	Description string   `yaml:"description" json:"description,omitempty"`
+	Slug        string   `yaml:"slug"        json:"slug,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline" json:"extra,omitempty"` // custom keys
+}
+
+// SplitFrontMatter return the front matter and the content after it, nil front matter if the content
+// does not start with a `---` block
+func SplitFrontMatter(content string) (*FrontMatter, string, error) {
+
+	rest, ok := cutDelimiter(strings.TrimPrefix(content, "\ufeff"))
+	if !ok {
+		return nil, content, nil
+	}
+
+	var block []string
+
+	for {
+		// a thematic break at the start of the content without the closing line
+		if rest == "" {
+			return nil, content, nil
+		}
+
+		line, next, _ := strings.Cut(rest, "\n")
+		rest = next
+
+		if trimmed := strings.TrimRight(line, " \t\r"); trimmed == "---" || trimmed == "..." {
+			break
+		}
+
+		block = append(block, line)
+	}
+
+	var matter = &FrontMatter{}
+
+	if err := yaml.Unmarshal([]byte(strings.Join(block, "\n")), matter); err != nil {
+		return nil, content, fmt.Errorf("%w: %s", ErrFrontMatterIsInvalid, err.Error())
+	}
+
+	return matter, rest, nil
+}
+
+// cutDelimiter return the content after the opening `---` line
+func cutDelimiter(content string) (string, bool) {
+	line, rest, _ := strings.Cut(content, "\n")
+	if strings.TrimRight(line, " \t\r") != "---" {
+		return content, false
+	}
+	return rest, true
+}
@@ -0,0 +1,163 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	codeLinesRegexp = regexp.MustCompile(`\{([\d\s,-]*)\}`)
+	codeTitleRegexp = regexp.MustCompile(`(?:title|filename)=(?:"([^"]*)"|(\S+))`)
+)
+
+// CodeInfo the fence info string of the code block, e.g. ```go {1,3-5} title="main.go"
+type CodeInfo struct {
+	Language string
+	Lines    [][2]int // the highlighted lines, 1-based and inclusive
+	Title    string   // the file name shown above the code
+}
+
+// ParseCodeInfo return the language, the highlighted lines and the title of the fence info string
+func ParseCodeInfo(info string) *CodeInfo {
+
+	code := &CodeInfo{}
+
+	info = strings.TrimSpace(info)
+	if info == "" {
+		return code
+	}
+
+	if lang, _, _ := strings.Cut(info, " "); !strings.HasPrefix(lang, "{") && !strings.Contains(lang, "=") {
+		code.Language = lang
+	}
+
+	if match := codeTitleRegexp.FindStringSubmatch(info); match != nil {
+		code.Title = match[1] + match[2]
+	}
+
+	if match := codeLinesRegexp.FindStringSubmatch(info); match != nil {
+		for _, field := range strings.Split(match[1], ",") {
+			start, end, isRange := strings.Cut(strings.TrimSpace(field), "-")
+
+			from, err := strconv.Atoi(strings.TrimSpace(start))
+			if err != nil || from < 1 {
+				continue
+			}
+
+			to := from
+			if isRange {
+				if to, err = strconv.Atoi(strings.TrimSpace(end)); err != nil || to < from {
+					continue
+				}
+			}
+
+			code.Lines = append(code.Lines, [2]int{from, to})
+		}
+	}
+
+	return code
+}
+
+// highlightRenderer render the fenced code blocks with the chroma css classes, the inline styles are
+// removed by the sanitizer, the colors are defined in `/static/css/chroma.css`
+type highlightRenderer struct{}
+
+func (r highlightRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (highlightRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*ast.FencedCodeBlock)
+
+	info := &CodeInfo{}
+	if n.Info != nil {
+		info = ParseCodeInfo(string(n.Info.Segment.Value(source)))
+	}
+
+	var code bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		code.Write(line.Value(source))
+	}
+
+	lexer := lexers.Get(info.Language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	if info.Title != "" {
+		_, _ = w.WriteString(`<div class="code-block">` + "\n" + `<div class="code-title">`)
+		_, _ = w.Write(util.EscapeHTML([]byte(info.Title)))
+		_, _ = w.WriteString("</div>\n")
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code.String())
+	if err == nil {
+		formatter := chromahtml.New(
+			chromahtml.WithClasses(true),
+			chromahtml.WithLineNumbers(true),
+			chromahtml.HighlightLines(info.Lines),
+		)
+		err = formatter.Format(w, styles.Fallback, iterator)
+	}
+
+	// the plain code block if the lexer fails
+	if err != nil {
+		_, _ = w.WriteString("<pre><code>")
+		_, _ = w.Write(util.EscapeHTML(code.Bytes()))
+		_, _ = w.WriteString("</code></pre>")
+	}
+
+	_, _ = w.WriteString("\n")
+
+	if info.Title != "" {
+		_, _ = w.WriteString("</div>\n")
+	}
+
+	return ast.WalkContinue, nil
+}
+
+// highlightClasses the span classes of the chroma tokens and lines and the extra classes, allowed by the
+// sanitizer
+func highlightClasses(extra ...string) *regexp.Regexp {
+
+	classes := append([]string{"line", "line hl", "ln", "cl"}, extra...)
+	for _, class := range chroma.StandardTypes {
+		if class != "" {
+			classes = append(classes, regexp.QuoteMeta(class))
+		}
+	}
+
+	sort.Strings(classes)
+
+	return regexp.MustCompile(`^(` + strings.Join(classes, "|") + `)$`)
+}
+
+// highlighting goldmark extension of the syntax highlighting
+type highlighting struct{}
+
+// Highlighting extension of the server side syntax highlighting of the fenced code blocks, with the line
+// numbers, the `{1,3-5}` highlighted lines and the `title="main.go"` title of the fence info string
+var Highlighting goldmark.Extender = &highlighting{}
+
+func (e *highlighting) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(util.Prioritized(highlightRenderer{}, 200)),
+	)
+}
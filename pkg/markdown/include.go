@@ -0,0 +1,249 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	MaxIncludeDepth = 5       // the nested includes limit, the page including itself is a cycle
+	MaxIncludes     = 100     // the includes limit of a rendering, the nested includes too
+	MaxIncludeBytes = 1 << 20 // the included bytes limit of a rendering
+)
+
+var (
+	// ErrIncludeIsInvalid the include directive can not be expanded, the error is rendered in place of the directive
+	ErrIncludeIsInvalid = errors.New("include is invalid")
+
+	// ErrIncludesTooLarge the includes of a rendering are over MaxIncludes or MaxIncludeBytes, the rendering fails
+	ErrIncludesTooLarge = errors.New("includes are too large")
+)
+
+var (
+	includeRegexp    = regexp.MustCompile(`^ {0,3}\{\{<\s*include\s+(.*?)\s*>\}\}\s*$`)
+	includeArgRegexp = regexp.MustCompile(`(\w+)=(?:"([^"]*)"|(\S+))`)
+	fenceRegexp      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// Include the include directive on its own line, e.g. `{{< include page=123 section="prerequisites" >}}`,
+// the section is the auto heading id of the included page heading
+type Include struct {
+	PageID  int64
+	Section string
+}
+
+// IncludeResolver return the markdown body of the included page, the errors wrapping ErrIncludeIsInvalid are
+// rendered in place of the directive, the others fail the rendering
+type IncludeResolver func(include *Include) (string, error)
+
+// parseInclude return the include of the directive line, nil if the line is not a directive
+func parseInclude(line string) (*Include, error) {
+
+	match := includeRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return nil, nil
+	}
+
+	include := &Include{}
+
+	for _, arg := range includeArgRegexp.FindAllStringSubmatch(match[1], -1) {
+		value := arg[2] + arg[3]
+
+		switch arg[1] {
+		case "page":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				return include, fmt.Errorf("%w: page %q is not a page id", ErrIncludeIsInvalid, value)
+			}
+			include.PageID = id
+		case "section":
+			include.Section = value
+		}
+	}
+
+	if include.PageID == 0 {
+		return include, fmt.Errorf("%w: page is missing", ErrIncludeIsInvalid)
+	}
+
+	return include, nil
+}
+
+// includeBudget the includes and the included bytes left of a rendering, the repeated includes fan out
+type includeBudget struct {
+	includes int
+	bytes    int
+}
+
+// spend the budget of an included body
+func (budget *includeBudget) spend(body string) error {
+
+	budget.includes--
+	budget.bytes -= len(body)
+
+	if budget.includes < 0 {
+		return fmt.Errorf("%w: more than %d includes", ErrIncludesTooLarge, MaxIncludes)
+	}
+	if budget.bytes < 0 {
+		return fmt.Errorf("%w: more than %d included bytes", ErrIncludesTooLarge, MaxIncludeBytes)
+	}
+
+	return nil
+}
+
+// expandIncludes replace the include directives out of the code blocks with the included markdown,
+// the stack is the ids of the including pages
+func expandIncludes(content string, resolve IncludeResolver, stack []int64, budget *includeBudget) (string, error) {
+
+	var (
+		lines = strings.SplitAfter(content, "\n")
+		buf   strings.Builder
+		fence string // the opening fence of the code block
+	)
+
+	for _, line := range lines {
+
+		if match := fenceRegexp.FindStringSubmatch(line); match != nil {
+			switch {
+			case fence == "":
+				fence = match[1]
+			case match[1][0] == fence[0] && len(match[1]) >= len(fence):
+				fence = ""
+			}
+		}
+
+		include, err := parseInclude(line)
+		if fence != "" || (include == nil && err == nil) {
+			buf.WriteString(line)
+			continue
+		}
+
+		var included string
+		if err == nil {
+			included, err = expandInclude(include, resolve, stack, budget)
+		}
+
+		if err != nil {
+			if !errors.Is(err, ErrIncludeIsInvalid) {
+				return "", err
+			}
+			included = fmt.Sprintf(":::warning Include failed\n%s\n:::", strings.TrimPrefix(err.Error(), ErrIncludeIsInvalid.Error()+": "))
+		}
+
+		// the included blocks are separated from the blocks around the directive
+		buf.WriteString("\n" + strings.TrimSpace(included) + "\n\n")
+	}
+
+	return buf.String(), nil
+}
+
+// expandInclude return the markdown of the include, the nested includes are expanded
+func expandInclude(include *Include, resolve IncludeResolver, stack []int64, budget *includeBudget) (string, error) {
+
+	for _, id := range stack {
+		if id == include.PageID {
+			return "", fmt.Errorf("%w: page %d is included recursively", ErrIncludeIsInvalid, include.PageID)
+		}
+	}
+
+	if len(stack) > MaxIncludeDepth {
+		return "", fmt.Errorf("%w: includes are nested deeper than %d levels", ErrIncludeIsInvalid, MaxIncludeDepth)
+	}
+
+	if resolve == nil {
+		return "", fmt.Errorf("%w: includes are not supported", ErrIncludeIsInvalid)
+	}
+
+	body, err := resolve(include)
+	if err != nil {
+		return "", err
+	}
+
+	if err := budget.spend(body); err != nil {
+		return "", err
+	}
+
+	_, body, err = SplitFrontMatter(body)
+	if err != nil {
+		return "", fmt.Errorf("%w: page %d %s", ErrIncludeIsInvalid, include.PageID, err.Error())
+	}
+
+	if include.Section != "" {
+		section, ok := sectionOf(body, include.Section)
+		if !ok {
+			return "", fmt.Errorf("%w: section %q is not found in page %d", ErrIncludeIsInvalid, include.Section, include.PageID)
+		}
+		body = section
+	}
+
+	return expandIncludes(body, resolve, append(stack[:len(stack):len(stack)], include.PageID), budget)
+}
+
+// sectionOf return the content under the top level heading with the anchor, until the next heading of the
+// same or a higher level, the heading itself is excluded
+func sectionOf(content, anchor string) (string, bool) {
+
+	var (
+		source = []byte(content)
+		doc    = md.Parser().Parse(text.NewReader(source))
+		start  = -1
+		level  int
+	)
+
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		heading, ok := node.(*ast.Heading)
+		if !ok || heading.Lines().Len() == 0 {
+			continue
+		}
+
+		if start >= 0 {
+			if heading.Level <= level {
+				return content[start:lineStart(source, heading.Lines().At(0).Start)], true
+			}
+			continue
+		}
+
+		id, _ := heading.AttributeString("id")
+		if value, ok := id.([]byte); !ok || string(value) != anchor {
+			continue
+		}
+
+		// after the heading line, or the underline of the setext heading
+		last := heading.Lines().At(heading.Lines().Len() - 1)
+		start = lineEnd(source, last.Stop)
+
+		if !bytes.HasPrefix(bytes.TrimLeft(source[lineStart(source, last.Start):], " "), []byte("#")) {
+			start = lineEnd(source, start)
+		}
+
+		level = heading.Level
+	}
+
+	if start < 0 {
+		return "", false
+	}
+
+	return content[start:], true
+}
+
+// lineStart return the start offset of the line containing the offset
+func lineStart(source []byte, offset int) int {
+	return bytes.LastIndexByte(source[:offset], '\n') + 1
+}
+
+// lineEnd return the start offset of the next line
+func lineEnd(source []byte, offset int) int {
+	if offset >= len(source) {
+		return len(source)
+	}
+	if i := bytes.IndexByte(source[offset:], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+	return len(source)
+}
@@ -3,39 +3,91 @@ package markdown
 import (
 	"bytes"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/longbridgeapp/autocorrect"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
 )
 
-var md goldmark.Markdown
+var (
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+)
 
 func init() {
 	md = goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithExtensions(extension.GFM, extension.Footnote, extension.DefinitionList, extension.Typographer, WikiLinks, Containers, Highlighting, Diagrams),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 			parser.WithAttribute(),
+			parser.WithASTTransformers(attachmentTransformerPriority),
 		),
 		goldmark.WithRendererOptions(
 			html.WithUnsafe(),
 			// don’t using html.WithHardWraps
 		),
 	)
+
+	// user generated content, the responsive attachment images, the footnotes, the `:::` containers,
+	// the highlighted code and the diagrams
+	policy = bluemonday.UGCPolicy()
+	policy.AllowAttrs("srcset").Matching(regexp.MustCompile(`^/attachments/\S+ \d+w(, /attachments/\S+ \d+w)*$`)).OnElements("img")
+	policy.AllowAttrs("sizes").Matching(regexp.MustCompile(`^[\w\s(),:.-]+$`)).OnElements("img")
+	policy.AllowAttrs("loading").Matching(regexp.MustCompile(`^(lazy|eager)$`)).OnElements("img")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(wiki-link|footnote-ref|footnote-backref)$`)).OnElements("a")
+	policy.AllowAttrs("role").Matching(regexp.MustCompile(`^(doc-noteref|doc-backlink|doc-endnotes)$`)).OnElements("a", "div")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(admonition admonition-(note|warning|danger)|tabs|tab-panel|footnotes|code-block|code-title|diagram diagram-(mermaid|dot|plantuml))$`)).OnElements("div")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(admonition-title|tab-title)$`)).OnElements("p")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(chroma|diagram-source)$`)).OnElements("pre")
+	policy.AllowAttrs("class").Matching(highlightClasses("wiki-link-missing")).OnElements("span")
 }
 
 // Options with markdown parser
 type Options struct {
-	Format bool
+	Format          bool
+	AttachmentImage AttachmentImage   // size and variants of the `attachment:` images
+	ResolveLink     LinkResolver      // url of the wiki links and `page:` links
+	RenderDiagram   DiagramRenderer   // pre-rendered svg of the diagram code fences
+	ResolveInclude  IncludeResolver   // markdown of the included pages
+	PageID          int64             // the rendered page, zero for a new page, including it is a cycle
+	Variables       map[string]string // values of the `{{ var "name" }}` references
+}
+
+// Heading table of contents entry
+type Heading struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"` // the auto heading id
+}
+
+// Result rendered markdown
+type Result struct {
+	HTML        string
+	TOC         []*Heading   // headings in document order
+	FrontMatter *FrontMatter // nil if the content has no front matter, the block is not rendered
 }
 
 // Parse markdown convert to html
 func Parse(content string, option ...Options) (string, error) {
 
+	result, err := Render(content, option...)
+	if err != nil {
+		return "", err
+	}
+
+	return result.HTML, nil
+}
+
+// Render markdown convert to html and the table of contents
+func Render(content string, option ...Options) (*Result, error) {
+
 	opt := Options{
 		Format: true,
 	}
@@ -44,11 +96,42 @@ func Parse(content string, option ...Options) (string, error) {
 		opt = option[0]
 	}
 
-	buf := new(bytes.Buffer)
+	matter, content, err := SplitFrontMatter(content)
+	if err != nil {
+		return nil, err
+	}
 
-	err := md.Convert([]byte(content), buf)
+	content, err = expandIncludes(content, opt.ResolveInclude, []int64{opt.PageID}, &includeBudget{includes: MaxIncludes, bytes: MaxIncludeBytes})
 	if err != nil {
-		return "", fmt.Errorf("markdown convert failed, err: %+v", err)
+		return nil, err
+	}
+
+	content = substituteVariables(content, opt.Variables)
+
+	pc := parser.NewContext()
+	if opt.AttachmentImage != nil {
+		pc.Set(attachmentImageKey, opt.AttachmentImage)
+	}
+	if opt.ResolveLink != nil {
+		pc.Set(linkResolverKey, opt.ResolveLink)
+	}
+	if opt.RenderDiagram != nil {
+		pc.Set(diagramRendererKey, opt.RenderDiagram)
+	}
+
+	var (
+		source = []byte(content)
+		doc    = md.Parser().Parse(text.NewReader(source), parser.WithContext(pc))
+		buf    = new(bytes.Buffer)
+		result = &Result{
+			TOC:         headings(doc, source),
+			FrontMatter: matter,
+		}
+	)
+
+	err = md.Renderer().Render(buf, source, doc)
+	if err != nil {
+		return nil, fmt.Errorf("markdown convert failed, err: %+v", err)
 	}
 
 	html := buf.String()
@@ -56,12 +139,53 @@ func Parse(content string, option ...Options) (string, error) {
 	if opt.Format {
 		html, err = autocorrect.FormatHTML(html)
 		if err != nil {
-			return "", fmt.Errorf("format markdown html failed, err: %+v", err)
+			return nil, fmt.Errorf("format markdown html failed, err: %+v", err)
+		}
+
+		for _, heading := range result.TOC {
+			heading.Text = autocorrect.Format(heading.Text)
 		}
 	}
 
 	// scrub content of XSS
-	html = bluemonday.UGCPolicy().Sanitize(html)
+	result.HTML = policy.Sanitize(html)
+
+	return result, nil
+}
+
+// headings return the headings with the auto heading ids
+func headings(doc ast.Node, source []byte) []*Heading {
+
+	var toc []*Heading
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		heading, ok := node.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		id, exists := heading.AttributeString("id")
+		if !exists {
+			return ast.WalkSkipChildren, nil
+		}
+
+		anchor, ok := id.([]byte)
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+
+		toc = append(toc, &Heading{
+			Level:  heading.Level,
+			Text:   strings.TrimSpace(string(heading.Text(source))),
+			Anchor: string(anchor),
+		})
+
+		return ast.WalkSkipChildren, nil
+	})
 
-	return html, nil
+	return toc
 }
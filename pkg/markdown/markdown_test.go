@@ -0,0 +1,156 @@
+package markdown
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the testdata")
+
+// TestGolden render the testdata/*.md and compare with the testdata/*.html
+func TestGolden(t *testing.T) {
+	assert := assert.New(t)
+
+	files, err := filepath.Glob("testdata/*.md")
+	assert.Nil(err)
+	assert.NotEmpty(files)
+
+	for _, file := range files {
+		file := file
+
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			source, err := os.ReadFile(file)
+			assert.Nil(err)
+
+			html, err := Parse(string(source))
+			assert.Nil(err)
+
+			golden := strings.TrimSuffix(file, ".md") + ".html"
+
+			if *update {
+				assert.Nil(os.WriteFile(golden, []byte(html), 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.Nil(err)
+			assert.Equal(string(expected), html)
+		})
+	}
+}
+
+func TestDiagramRenderer(t *testing.T) {
+	assert := assert.New(t)
+
+	content := "```dot\ndigraph { a -> b }\n```\n\n```mermaid\ngraph TD\n```\n"
+
+	var sources []string
+
+	html, err := Parse(content, Options{
+		RenderDiagram: func(kind, source string) (string, error) {
+			sources = append(sources, kind+":"+source)
+			if kind != DiagramGraphviz {
+				return "", errors.New("not supported")
+			}
+			return "/diagrams/abc.svg", nil
+		},
+	})
+	assert.Nil(err)
+	assert.Equal([]string{"dot:digraph { a -> b }\n", "mermaid:graph TD\n"}, sources)
+
+	// the failed diagrams fall back to the source
+	assert.Contains(html, `<div class="diagram diagram-dot"><img src="/diagrams/abc.svg" alt="dot diagram"></div>`)
+	assert.Contains(html, `<div class="diagram diagram-mermaid"><pre class="diagram-source">graph TD`)
+}
+
+func TestIncludes(t *testing.T) {
+	assert := assert.New(t)
+
+	pages := map[int64]string{
+		2: "---\ntitle: Shared\n---\n# Shared\n\n## Prerequisites\n\n- Go\n\n### Database\n\nMySQL\n\n## Install\n\nRun it",
+		3: "Setext\n======\n\nUnder the setext heading",
+	}
+
+	// a chain of nested includes deeper than the limit
+	for id := int64(10); id < 20; id++ {
+		pages[id] = fmt.Sprintf("Level %d\n\n{{< include page=%d >}}", id, id+1)
+	}
+
+	resolve := func(include *Include) (string, error) {
+		body, exists := pages[include.PageID]
+		if !exists {
+			return "", fmt.Errorf("%w: page %d is not found", ErrIncludeIsInvalid, include.PageID)
+		}
+		return body, nil
+	}
+
+	html, err := Parse("{{< include page=2 section=\"prerequisites\" >}}", Options{ResolveInclude: resolve, PageID: 1})
+	assert.Nil(err)
+	assert.Equal("<ul>\n<li>Go</li>\n</ul>\n<h3 id=\"database\">Database</h3>\n<p>MySQL</p>\n", html)
+
+	html, err = Parse("{{< include page=3 section=\"setext\" >}}", Options{ResolveInclude: resolve, PageID: 1})
+	assert.Nil(err)
+	assert.Equal("<p>Under the setext heading</p>\n", html)
+
+	// the directives in the code blocks are not expanded
+	html, err = Parse("```\n{{< include page=2 >}}\n```", Options{ResolveInclude: resolve, PageID: 1})
+	assert.Nil(err)
+	assert.NotContains(html, "Prerequisites")
+
+	html, err = Parse("{{< include page=10 >}}", Options{ResolveInclude: resolve, PageID: 1})
+	assert.Nil(err)
+	assert.Contains(html, "Level 14")
+	assert.NotContains(html, "Level 15")
+	assert.Contains(html, fmt.Sprintf("includes are nested deeper than %d levels", MaxIncludeDepth))
+
+	// the resolver failures fail the rendering
+	_, err = Parse("{{< include page=2 >}}", Options{ResolveInclude: func(*Include) (string, error) { return "", io.ErrUnexpectedEOF }})
+	assert.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	// the fan out of the repeated includes fails the rendering
+	pages[20] = strings.Repeat("{{< include page=21 >}}\n", 10)
+	pages[21] = strings.Repeat("{{< include page=22 >}}\n", 10)
+	pages[22] = "Leaf"
+
+	_, err = Parse("{{< include page=20 >}}", Options{ResolveInclude: resolve, PageID: 1})
+	assert.ErrorIs(err, ErrIncludesTooLarge)
+
+	pages[23] = strings.Repeat("a", MaxIncludeBytes/2)
+	_, err = Parse("{{< include page=23 >}}\n{{< include page=23 >}}", Options{ResolveInclude: resolve, PageID: 1})
+	assert.Nil(err)
+	_, err = Parse("{{< include page=23 >}}\n{{< include page=23 >}}\n{{< include page=23 >}}", Options{ResolveInclude: resolve, PageID: 1})
+	assert.ErrorIs(err, ErrIncludesTooLarge)
+}
+
+func TestVariables(t *testing.T) {
+	assert := assert.New(t)
+
+	variables := map[string]string{
+		"product_name":       "Space",
+		"latest_sdk_version": "1.2.0",
+		"api_base_url":       "https://api.example.com",
+	}
+
+	html, err := Parse(`[{{ var "product_name" }} API]({{var "api_base_url"}}/users)`, Options{Variables: variables})
+	assert.Nil(err)
+	assert.Equal("<p><a href=\"https://api.example.com/users\" rel=\"nofollow\">Space API</a></p>\n", html)
+
+	// the code blocks are substituted, the unknown variables are kept
+	html, err = Parse("`{{ var \"latest_sdk_version\" }}` `{{ var \"unknown\" }}`", Options{Variables: variables})
+	assert.Nil(err)
+	assert.Equal("<p><code>1.2.0</code> <code>{{ var &#34;unknown&#34; }}</code></p>\n", html)
+
+	// the html of the values is sanitized
+	html, err = Parse(`{{ var "product_name" }}`, Options{Variables: map[string]string{"product_name": "<script>alert(1)</script>"}})
+	assert.Nil(err)
+	assert.NotContains(html, "<script>")
+
+	assert.Equal([]string{"product_name", "api_base_url"}, Variables(`{{ var "product_name" }} {{ var "api_base_url" }} {{ var "product_name" }}`))
+}
@@ -0,0 +1,37 @@
+package markdown
+
+import (
+	"regexp"
+
+	"github.com/samber/lo"
+)
+
+// variableRegexp the variable reference, e.g. `{{ var "latest_sdk_version" }}`
+var variableRegexp = regexp.MustCompile(`\{\{\s*var\s+"([A-Za-z_][A-Za-z0-9_]*)"\s*\}\}`)
+
+// VariableNameRegexp the name of the variables
+var VariableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Variables return the names of the variables referenced in the content
+func Variables(content string) []string {
+	names := lo.Map(variableRegexp.FindAllStringSubmatch(content, -1), func(match []string, _ int) string { return match[1] })
+	return lo.Uniq(names)
+}
+
+// substituteVariables replace the variable references with the values, no template is executed, the values are
+// inserted into the markdown as they are, so the references in the code blocks and the link urls are replaced too,
+// the unknown variables are kept as they are
+func substituteVariables(content string, variables map[string]string) string {
+
+	if len(variables) == 0 {
+		return content
+	}
+
+	return variableRegexp.ReplaceAllStringFunc(content, func(reference string) string {
+		name := variableRegexp.FindStringSubmatch(reference)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return reference
+	})
+}
@@ -0,0 +1,204 @@
+package markdown
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// PageScheme page id url scheme, e.g. `[Install](page:123)`
+const PageScheme = "page:"
+
+// PageLink internal page link, e.g. `[[Page Title]]`, `[[space-key:Page Title|label]]` or `page:123`
+type PageLink struct {
+	SpaceKey string // empty means the current space
+	Title    string
+	PageID   int64
+}
+
+// ParsePageLink parse the wiki link target or the `page:` url, nil if the target is empty
+func ParsePageLink(target string) *PageLink {
+	target = strings.TrimSpace(target)
+
+	if strings.HasPrefix(target, PageScheme) {
+		id, err := strconv.ParseInt(strings.TrimPrefix(target, PageScheme), 10, 64)
+		if err != nil || id <= 0 {
+			return nil
+		}
+		return &PageLink{PageID: id}
+	}
+
+	link := &PageLink{Title: target}
+
+	// the space key has no spaces and is followed by the title without a space,
+	// the titles may have colons, e.g. `[[FAQ: Billing]]`
+	key, title, found := strings.Cut(target, ":")
+	if found && key != "" && title != "" && !strings.ContainsAny(key, " \t") && title[0] != ' ' {
+		link.SpaceKey, link.Title = key, strings.TrimSpace(title)
+	}
+
+	if link.Title == "" {
+		return nil
+	}
+
+	return link
+}
+
+// LinkResolver return the website url and title of the linked page, empty url if the page does not exist
+type LinkResolver func(link *PageLink) (url, title string)
+
+// linkResolverKey parser context key of the LinkResolver option
+var linkResolverKey = parser.NewContextKey()
+
+// KindWikiLink wiki link node kind
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// WikiLink `[[Page Title|label]]` node
+type WikiLink struct {
+	ast.BaseInline
+	Link  *PageLink
+	Label string
+	URL   string // empty if the page does not exist
+}
+
+// Kind implement ast.Node
+func (n *WikiLink) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+// Text implement ast.Node, the label is the text of the link
+func (n *WikiLink) Text(source []byte) []byte {
+	return []byte(n.Label)
+}
+
+// Dump implement ast.Node
+func (n *WikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Label": n.Label, "URL": n.URL}, nil)
+}
+
+// wikiLinkParser parse `[[target]]` and `[[target|label]]`
+type wikiLinkParser struct{}
+
+func (wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+
+	end := bytes.Index(line, []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+
+	target, label, _ := strings.Cut(string(line[2:end]), "|")
+
+	link := ParsePageLink(target)
+	if link == nil {
+		return nil
+	}
+
+	block.Advance(end + 2)
+
+	node := &WikiLink{Link: link, Label: strings.TrimSpace(label)}
+
+	var title string
+	if resolve, ok := pc.Get(linkResolverKey).(LinkResolver); ok {
+		node.URL, title = resolve(link)
+	}
+
+	// the page id links are labeled with the page title
+	switch {
+	case node.Label != "":
+	case link.PageID > 0 && title != "":
+		node.Label = title
+	case link.PageID > 0:
+		node.Label = strings.TrimSpace(target)
+	default:
+		node.Label = link.Title
+	}
+
+	return node
+}
+
+// wikiLinkRenderer render the resolved wiki links as anchors, the missing pages as marked text
+type wikiLinkRenderer struct{}
+
+func (r wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, r.render)
+}
+
+func (wikiLinkRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*WikiLink)
+
+	if n.URL == "" {
+		_, _ = w.WriteString(`<span class="wiki-link-missing">`)
+		_, _ = w.Write(util.EscapeHTML([]byte(n.Label)))
+		_, _ = w.WriteString(`</span>`)
+		return ast.WalkSkipChildren, nil
+	}
+
+	_, _ = w.WriteString(`<a class="wiki-link" href="`)
+	_, _ = w.Write(util.EscapeHTML(util.URLEscape([]byte(n.URL), true)))
+	_, _ = w.WriteString(`">`)
+	_, _ = w.Write(util.EscapeHTML([]byte(n.Label)))
+	_, _ = w.WriteString(`</a>`)
+
+	return ast.WalkSkipChildren, nil
+}
+
+// pageLinkTransformer resolve the `page:` destinations of links
+type pageLinkTransformer struct{}
+
+func (pageLinkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	resolve, ok := pc.Get(linkResolverKey).(LinkResolver)
+	if !ok {
+		return
+	}
+
+	scheme := []byte(PageScheme)
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if n, ok := node.(*ast.Link); ok && entering && bytes.HasPrefix(n.Destination, scheme) {
+			if link := ParsePageLink(string(n.Destination)); link != nil {
+				if url, _ := resolve(link); url != "" {
+					n.Destination = []byte(url)
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// wikiLinks goldmark extension of the internal page links
+type wikiLinks struct{}
+
+// WikiLinks extension of `[[Page Title]]`, `[[space-key:Page Title]]` and `page:123` links,
+// resolved with the Options.ResolveLink
+var WikiLinks goldmark.Extender = &wikiLinks{}
+
+func (e *wikiLinks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// before the link parser
+		parser.WithInlineParsers(util.Prioritized(wikiLinkParser{}, 199)),
+		parser.WithASTTransformers(util.Prioritized(pageLinkTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(util.Prioritized(wikiLinkRenderer{}, 500)),
+	)
+}
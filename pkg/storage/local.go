@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalConfig local filesystem storage config
+type LocalConfig struct {
+	Root string `mapstructure:"root"` // default `data/attachments`
+}
+
+// Local filesystem storage
+type Local struct {
+	root string
+}
+
+var _ Storage = &Local{}
+
+// NewLocal return local filesystem storage, the root directory is created if not exists
+func NewLocal(config *LocalConfig) (*Local, error) {
+
+	root := config.Root
+	if root == "" {
+		root = filepath.Join("data", "attachments")
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Local{root: root}, nil
+}
+
+// filename return the file path of the object key
+func (local *Local) filename(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(local.root, filepath.FromSlash(key)), nil
+}
+
+// Put write the object to a temporary file, then rename to the key file
+func (local *Local) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+
+	filename, err := local.filename(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filename)
+}
+
+// Get open the object file
+func (local *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	filename, err := local.filename(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+// Delete remove the object file, deleting a missing object is not an error
+func (local *Local) Delete(ctx context.Context, key string) error {
+
+	filename, err := local.filename(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
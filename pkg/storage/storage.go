@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrNotFound object does not exist
+	ErrNotFound = errors.New("storage object not found")
+
+	// ErrKeyIsInvalid object key is invalid
+	ErrKeyIsInvalid = errors.New("storage object key is invalid")
+)
+
+// Storage object storage backend, keys are slash separated relative paths, e.g. `spaces/1/01H2X.png`
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Storage types
+const (
+	TypeLocal = "local"
+	TypeS3    = "s3"
+)
+
+// Config storage config
+type Config struct {
+	Type  string       `mapstructure:"type"` // local | s3, default local
+	Local *LocalConfig `mapstructure:"local"`
+	S3    *S3Config    `mapstructure:"s3"`
+}
+
+// New return the storage of the config, nil config is the local storage in `data/attachments`
+func New(config *Config) (Storage, error) {
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	switch config.Type {
+	case "", TypeLocal:
+		local := config.Local
+		if local == nil {
+			local = &LocalConfig{}
+		}
+		return NewLocal(local)
+	case TypeS3:
+		if config.S3 == nil {
+			return nil, errors.New("storage s3 config is missing")
+		}
+		return NewS3(config.S3)
+	default:
+		return nil, fmt.Errorf("storage type %q is not supported", config.Type)
+	}
+}
+
+// cleanKey return the clean object key, keys escaping the root are invalid
+func cleanKey(key string) (string, error) {
+	key = path.Clean("/" + key)[1:]
+
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrKeyIsInvalid
+	}
+
+	return key, nil
+}
@@ -0,0 +1,216 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+var (
+	// ErrSpaceKeyIsInvalid space key is empty or not a url path segment
+	ErrSpaceKeyIsInvalid = errors.New("space key is invalid")
+
+	// ErrSpaceKeyIsTaken space key is used by another space
+	ErrSpaceKeyIsTaken = errors.New("space key is taken")
+)
+
+// SpaceMovedError the space key is a previous key of the renamed space
+type SpaceMovedError struct {
+	Key string // the current key
+}
+
+// Error implements error
+func (e *SpaceMovedError) Error() string {
+	return fmt.Sprintf("space moved to %s", e.Key)
+}
+
+// SpaceMovedLocation return the request url with the previous space key replaced by the current key,
+// the key follows the `spaces`, `docs` or `pagetree` path segment
+func SpaceMovedLocation(u *url.URL, previous, key string) string {
+
+	segments := strings.Split(u.Path, "/")
+
+	for i := 1; i < len(segments); i++ {
+		if segments[i] == previous && lo.Contains([]string{"spaces", "docs", "pagetree"}, segments[i-1]) {
+			segments[i] = key
+			break
+		}
+	}
+
+	location := strings.Join(segments, "/")
+	if u.RawQuery != "" {
+		location += "?" + u.RawQuery
+	}
+
+	return location
+}
+
+// movedSpace return SpaceMovedError if the key is a previous key of a space, or gorm.ErrRecordNotFound
+func movedSpace(db *gorm.DB, key string) (*models.Space, error) {
+
+	var (
+		alias *models.SpaceAlias
+		space *models.Space
+	)
+
+	if err := db.Where("`key` = ?", key).First(&alias).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("`id` = ?", alias.SpaceID).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	return space, &SpaceMovedError{Key: space.Key}
+}
+
+// checkSpaceKey return error if the key is invalid, or used by another space or as its previous key
+func checkSpaceKey(tx *gorm.DB, spaceID int64, key string) error {
+
+	if key == "" || strings.ContainsAny(key, "/?#% ") {
+		return ErrSpaceKeyIsInvalid
+	}
+
+	var count int64
+
+	if err := tx.Model(&models.Space{}).Where("`key` = ? AND `id` <> ?", key, spaceID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrSpaceKeyIsTaken
+	}
+
+	if err := tx.Model(&models.SpaceAlias{}).Where("`key` = ? AND `space_id` <> ?", key, spaceID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrSpaceKeyIsTaken
+	}
+
+	return nil
+}
+
+func (s *service) RenameSpaceKey(ctx context.Context, params *params.RenameSpaceKey) (*models.Space, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	if err := database.Where("`key` = ?", params.Key).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	if space.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
+	if params.NewKey == space.Key {
+		return space, nil
+	}
+
+	err := database.Transaction(func(tx *gorm.DB) error {
+
+		if err := checkSpaceKey(tx, space.ID, params.NewKey); err != nil {
+			return err
+		}
+
+		// renamed back to a previous key
+		if err := tx.Where("`key` = ? AND `space_id` = ?", params.NewKey, space.ID).Delete(&models.SpaceAlias{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.SpaceAlias{SpaceID: space.ID, Key: space.Key}).Error; err != nil {
+			return err
+		}
+
+		space.Key = params.NewKey
+
+		return tx.Model(space).UpdateColumn("key", space.Key).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return space, nil
+}
+
+func (s *service) TransferSpace(ctx context.Context, params *params.TransferSpace) (*models.Space, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	if params.AccountID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if err := database.Where("`key` = ?", params.Key).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	if space.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
+	err := database.Transaction(func(tx *gorm.DB) error {
+
+		// the new owner
+		var member *models.Member
+
+		err := tx.Where("`space_id` = ? AND `account_id` = ?", space.ID, params.AccountID).First(&member).Error
+		switch {
+		case err == nil:
+			member.Role = models.MemberRoleOwner
+			err = tx.Save(member).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			err = tx.Create(&models.Member{SpaceID: space.ID, AccountID: params.AccountID, Role: models.MemberRoleOwner}).Error
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// the transferring owner stays as a maintainer, the new owner is another owner then
+		if params.OwnerID > 0 && params.OwnerID != params.AccountID {
+			var previous *models.Member
+
+			err := tx.Where("`space_id` = ? AND `account_id` = ? AND `role` = ?", space.ID, params.OwnerID, models.MemberRoleOwner).
+				First(&previous).Error
+			switch {
+			case err == nil:
+				if err := checkLastOwner(tx, previous); err != nil {
+					return err
+				}
+				previous.Role = models.MemberRoleMaintainer
+				if err := tx.Save(previous).Error; err != nil {
+					return err
+				}
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return err
+			}
+		}
+
+		space.CreatorID = params.AccountID
+
+		return tx.Model(space).UpdateColumn("creator_id", space.CreatorID).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return space, nil
+}
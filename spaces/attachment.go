@@ -0,0 +1,309 @@
+package spaces
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/imaging"
+	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/spaces/params"
+)
+
+// AttachmentMaxSize max bytes of an attachment file
+const AttachmentMaxSize = 32 << 20
+
+var (
+	// ErrAttachmentIsInvalid attachment name or content is invalid
+	ErrAttachmentIsInvalid = errors.New("attachment is invalid")
+
+	// ErrAttachmentTooLarge attachment exceeds AttachmentMaxSize
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+	// ErrStorageIsMissing the service has no storage backend, see WithStorage
+	ErrStorageIsMissing = errors.New("attachment storage is missing")
+)
+
+// attachmentName return the base name of the uploaded file name
+func attachmentName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = strings.TrimSpace(path.Base(name))
+
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[len(runes)-255:])
+	}
+
+	return name
+}
+
+// attachmentContentType return the content type of the file, sniffed from the content if the extension is unknown
+func attachmentContentType(name string, head []byte) string {
+	if contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(head)
+}
+
+// attachmentReferenceRegexp markdown attachment references, e.g. `attachment:42/diagram.png`
+var attachmentReferenceRegexp = regexp.MustCompile(regexp.QuoteMeta(markdown.AttachmentScheme) + `(\d+)`)
+
+// attachmentImages return the images of the attachments referenced in the markdown
+func attachmentImages(tx *gorm.DB, body string) (map[int64]*markdown.Image, error) {
+
+	var (
+		ids    []int64
+		images = map[int64]*markdown.Image{}
+	)
+
+	for _, match := range attachmentReferenceRegexp.FindAllStringSubmatch(body, -1) {
+		if id, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return images, nil
+	}
+
+	var attachments []*models.Attachment
+	if err := tx.Where("`id` IN ? AND `width` > 0", lo.Uniq(ids)).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		images[attachment.ID] = attachment.Image()
+	}
+
+	return images, nil
+}
+
+// storeImageVariants store the resized variants of the image attachment, and set the image size,
+// the content that is not a supported image is kept without variants
+func (s *service) storeImageVariants(ctx context.Context, attachment *models.Attachment, content []byte) error {
+
+	if !strings.HasPrefix(attachment.ContentType, "image/") {
+		return nil
+	}
+
+	// the images not decodable have no size, e.g. svg, and the too large images have no variants
+	image, _ := imaging.Process(content)
+	if image == nil {
+		return nil
+	}
+
+	attachment.Width, attachment.Height = image.Width, image.Height
+
+	for _, v := range image.Variants {
+		variant := &models.AttachmentVariant{
+			Name:        v.Name,
+			Width:       v.Width,
+			Height:      v.Height,
+			ContentType: v.ContentType,
+			Size:        int64(len(v.Data)),
+		}
+
+		if err := s.Storage.Put(ctx, attachment.VariantKey(variant), bytes.NewReader(v.Data), variant.ContentType); err != nil {
+			return err
+		}
+
+		attachment.Variants = append(attachment.Variants, variant)
+	}
+
+	return nil
+}
+
+// deleteAttachmentObjects delete the attachment and variant objects from the storage
+func (s *service) deleteAttachmentObjects(ctx context.Context, attachment *models.Attachment) error {
+	for _, variant := range attachment.Variants {
+		if err := s.Storage.Delete(ctx, attachment.VariantKey(variant)); err != nil {
+			return err
+		}
+	}
+	return s.Storage.Delete(ctx, attachment.Key)
+}
+
+func (s *service) CreateAttachment(ctx context.Context, params *params.CreateAttachment) (*models.Attachment, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		page     *models.Page
+		name     = attachmentName(params.Name)
+	)
+
+	if s.Storage == nil {
+		return nil, ErrStorageIsMissing
+	}
+
+	if name == "" || params.Reader == nil {
+		return nil, ErrAttachmentIsInvalid
+	}
+
+	if params.Size > AttachmentMaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return nil, err
+	}
+
+	if err := database.Where("`id` = ? AND `space_id` = ?", params.PageID, params.SpaceID).First(&page).Error; err != nil {
+		return nil, err
+	}
+
+	// the content is buffered to know the size and checksum before storing
+	content, err := io.ReadAll(io.LimitReader(params.Reader, AttachmentMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) > AttachmentMaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	checksum := sha256.Sum256(content)
+
+	attachment := &models.Attachment{
+		SpaceID:     params.SpaceID,
+		PageID:      page.ID,
+		CreatorID:   params.CreatorID,
+		Name:        name,
+		ContentType: params.ContentType,
+		Size:        int64(len(content)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		Key:         path.Join("spaces", strconv.FormatInt(params.SpaceID, 10), ulid.Make().String()+strings.ToLower(filepath.Ext(name))),
+	}
+
+	if attachment.ContentType == "" || attachment.ContentType == "application/octet-stream" {
+		attachment.ContentType = attachmentContentType(name, content)
+	}
+
+	if err := s.Storage.Put(ctx, attachment.Key, bytes.NewReader(content), attachment.ContentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.storeImageVariants(ctx, attachment, content); err != nil {
+		_ = s.deleteAttachmentObjects(ctx, attachment)
+		return nil, err
+	}
+
+	if err := database.Create(attachment).Error; err != nil {
+		_ = s.deleteAttachmentObjects(ctx, attachment)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (s *service) DescribeAttachments(ctx context.Context, params *params.DescribeAttachments) ([]*models.Attachment, error) {
+
+	var (
+		database    = s.Database.WithContext(ctx)
+		attachments = []*models.Attachment{}
+	)
+
+	err := database.
+		Where("`space_id` = ? AND `page_id` = ?", params.SpaceID, params.PageID).
+		Order("`id` ASC").
+		Find(&attachments).Error
+
+	return attachments, err
+}
+
+func (s *service) OpenAttachment(ctx context.Context, params *params.OpenAttachment) (*models.Attachment, io.ReadCloser, error) {
+
+	var (
+		database   = s.Database.WithContext(ctx)
+		attachment *models.Attachment
+		page       *models.Page
+	)
+
+	if s.Storage == nil {
+		return nil, nil, ErrStorageIsMissing
+	}
+
+	if err := database.Where("`id` = ?", params.ID).Preload("Space").First(&attachment).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// the attachments of deleted spaces are gone
+	if attachment.Space == nil {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	if err := s.checkSpaceVisible(ctx, attachment.Space, params.Viewer); err != nil {
+		return nil, nil, err
+	}
+
+	if err := database.Select("id", "space_id", "lft", "rgt").Where("`id` = ?", attachment.PageID).First(&page).Error; err != nil {
+		return nil, nil, err
+	}
+
+	denied, err := s.deniedPages(ctx, attachment.SpaceID, params.Viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if inSubtrees(page, denied) {
+		return nil, nil, ErrPageAccessDenied
+	}
+
+	key := attachment.Key
+	if variant := attachment.Variant(params.Variant); variant != nil {
+		key = attachment.VariantKey(variant)
+	}
+
+	reader, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, reader, nil
+}
+
+func (s *service) DeleteAttachment(ctx context.Context, params *params.DeleteAttachment) error {
+
+	var (
+		database   = s.Database.WithContext(ctx)
+		attachment *models.Attachment
+	)
+
+	if s.Storage == nil {
+		return ErrStorageIsMissing
+	}
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return err
+	}
+
+	err := database.
+		Where("`id` = ? AND `space_id` = ? AND `page_id` = ?", params.ID, params.SpaceID, params.PageID).
+		First(&attachment).Error
+	if err != nil {
+		return err
+	}
+
+	if err := database.Delete(attachment).Error; err != nil {
+		return err
+	}
+
+	return s.deleteAttachmentObjects(ctx, attachment)
+}
@@ -0,0 +1,168 @@
+package spaces
+
+import (
+	"database/sql"
+
+	"github.com/fox-gonic/fox/database/nestedset"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+)
+
+// copyPages copy the pages and their contents of all languages and versions into the target space,
+// the pages are in nested set order, parents always come before their children,
+// the pages whose parent is not copied become children of the parent, or roots without parent
+func (s *service) copyPages(tx *gorm.DB, from, to *models.Space, parent *models.Page, pages []*models.Page, contents []*models.PageContent, creatorID int64) (map[int64]*models.Page, error) {
+
+	var (
+		copies = make(map[int64]*models.Page, len(pages))
+		titles = make(map[int64]string, len(pages))
+		err    error
+	)
+
+	for _, c := range contents {
+		if _, exists := titles[c.PageID]; !exists || c.Lang == from.Lang {
+			titles[c.PageID] = c.Title
+		}
+	}
+
+	for _, node := range pages {
+
+		var (
+			page = &models.Page{
+				SpaceID:  to.ID,
+				ParentID: sql.NullInt64{Valid: true},
+			}
+			p = parent
+		)
+
+		if copied, exists := copies[node.ParentID.Int64]; exists {
+			p = copied
+		}
+
+		if p != nil {
+			page.ParentID.Int64 = p.ID
+		}
+
+		slug := node.Slug
+		if slug == "" {
+			slug = titles[node.ID]
+		}
+
+		if err := setSlug(tx, page, slug); err != nil {
+			return nil, err
+		}
+
+		if p != nil {
+			// nested set values of the parent are changed by the previous inserts
+			if err := tx.Where("`id` = ?", p.ID).First(p).Error; err != nil {
+				return nil, err
+			}
+			err = nestedset.Create(tx, page, p)
+		} else {
+			err = nestedset.Create(tx, page, nil)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		copies[node.ID] = page
+	}
+
+	created := make([]*models.PageContent, 0, len(contents))
+
+	for _, c := range contents {
+
+		page := copies[c.PageID]
+
+		content := &models.PageContent{
+			SpaceID:     to.ID,
+			CreatorID:   creatorID,
+			PageID:      page.ID,
+			Lang:        c.Lang,
+			Version:     c.Version,
+			Status:      c.Status,
+			Title:       c.Title,
+			ShortTitle:  c.ShortTitle,
+			Body:        rewritePageLinks(c.Body, from, to, pages, copies),
+			Tags:        c.Tags,
+			Description: c.Description,
+			Meta:        c.Meta,
+		}
+
+		if err := tx.Create(content).Error; err != nil {
+			return nil, err
+		}
+
+		created = append(created, content)
+
+		if page.Content == nil || content.Lang == to.Lang {
+			page.Content = content
+		}
+	}
+
+	// render after all contents are created, so the title links and the includes resolve to the copies
+	if err := s.renderContents(tx, created); err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+// copyRestrictions copy the restrictions of the pages to their copies,
+// the roles apply to the members of the target space
+func copyRestrictions(tx *gorm.DB, to *models.Space, ids []int64, copies map[int64]*models.Page) error {
+
+	var restrictions []*models.PageRestriction
+	if err := tx.Where("`page_id` IN ?", ids).Order("`id` ASC").Find(&restrictions).Error; err != nil {
+		return err
+	}
+
+	for _, r := range restrictions {
+		restriction := &models.PageRestriction{
+			SpaceID:   to.ID,
+			PageID:    copies[r.PageID].ID,
+			AccountID: r.AccountID,
+			Role:      r.Role,
+		}
+		if err := tx.Create(restriction).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// spacePages return the space, and its pages in nested set order with the contents of all languages and versions
+func spacePages(db *gorm.DB, spaceID int64) (*models.Space, []*models.Page, []*models.PageContent, error) {
+
+	var (
+		space    *models.Space
+		pages    []*models.Page
+		contents []*models.PageContent
+	)
+
+	if err := db.Where("`id` = ?", spaceID).First(&space).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := db.Where("`space_id` = ?", space.ID).Order("`lft` ASC").Find(&pages).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(pages) == 0 {
+		return space, pages, contents, nil
+	}
+
+	ids := make([]int64, 0, len(pages))
+	for _, page := range pages {
+		ids = append(ids, page.ID)
+	}
+
+	if err := db.Where("`page_id` IN ?", ids).Order("`id` ASC").Find(&contents).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	return space, pages, contents, nil
+}
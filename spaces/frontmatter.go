@@ -0,0 +1,67 @@
+package spaces
+
+import (
+	"strings"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/spaces/params"
+)
+
+// frontMatterStatus return the page status of the front matter, case insensitive
+func frontMatterStatus(matter *markdown.FrontMatter) models.PageStatus {
+	return models.PageStatus(strings.ToLower(strings.TrimSpace(matter.Status)))
+}
+
+// applyFrontMatter fill the empty create page params with the front matter of the body
+func applyFrontMatter(params *params.CreatePage, matter *markdown.FrontMatter) {
+	if matter == nil {
+		return
+	}
+
+	if params.Title == "" {
+		params.Title = matter.Title
+	}
+	if params.ShortTitle == "" {
+		params.ShortTitle = matter.ShortTitle
+	}
+	if params.Status == "" && matter.Status != "" {
+		params.Status = frontMatterStatus(matter)
+	}
+	if params.Slug == "" {
+		params.Slug = matter.Slug
+	}
+	if len(params.Tags) == 0 {
+		params.Tags = matter.Tags
+	}
+	if params.Description == "" {
+		params.Description = matter.Description
+	}
+}
+
+// applyUpdateFrontMatter fill the nil or empty update page params with the front matter of the body
+func applyUpdateFrontMatter(params *params.UpdatePage, matter *markdown.FrontMatter) {
+	if matter == nil {
+		return
+	}
+
+	fill := func(value **string, v string) {
+		if v != "" && (*value == nil || **value == "") {
+			*value = &v
+		}
+	}
+
+	fill(&params.Title, matter.Title)
+	fill(&params.ShortTitle, matter.ShortTitle)
+	fill(&params.Slug, matter.Slug)
+	fill(&params.Description, matter.Description)
+
+	if matter.Status != "" && (params.Status == nil || *params.Status == "") {
+		status := frontMatterStatus(matter)
+		params.Status = &status
+	}
+
+	if len(matter.Tags) > 0 && (params.Tags == nil || len(*params.Tags) == 0) {
+		params.Tags = &matter.Tags
+	}
+}
@@ -0,0 +1,343 @@
+package spaces
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// treeNode page tree node position
+type treeNode struct {
+	*models.Page
+	parent   *treeNode
+	children []*treeNode
+	foreign  bool // the parent is in another space out of the checked space, the page is kept under it
+}
+
+func (s *service) CheckPageTree(ctx context.Context, params *params.CheckPageTree) (*models.TreeReport, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		pages    []*models.Page
+		report   = &models.TreeReport{
+			SpaceID: params.SpaceID,
+			Issues:  []*models.TreeIssue{},
+			DryRun:  params.DryRun,
+		}
+	)
+
+	db := database.Select("id", "parent_id", "lft", "rgt", "depth", "children_count", "space_id", "slug", "path")
+	if params.SpaceID > 0 {
+		db = db.Where("`space_id` = ?", params.SpaceID)
+	}
+
+	if err := db.Order("`space_id` ASC, `lft` ASC, `id` ASC").Find(&pages).Error; err != nil {
+		return nil, err
+	}
+
+	report.Pages = len(pages)
+
+	var (
+		nodes = make(map[int64]*treeNode, len(pages))
+		issue = func(page *models.Page, kind models.TreeIssueKind, format string, args ...interface{}) {
+			report.Issues = append(report.Issues, &models.TreeIssue{
+				SpaceID: page.SpaceID,
+				PageID:  page.ID,
+				Kind:    kind,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+	)
+
+	for _, page := range pages {
+		nodes[page.ID] = &treeNode{Page: page}
+	}
+
+	// checking one space only, parents may be in other spaces
+	if params.SpaceID > 0 {
+		var ids []int64
+		for _, page := range pages {
+			if _, exists := nodes[page.ParentID.Int64]; page.ParentID.Int64 > 0 && !exists {
+				ids = append(ids, page.ParentID.Int64)
+			}
+		}
+
+		if len(ids) > 0 {
+			var parents []*models.Page
+			if err := database.Select("id", "space_id", "path").Where("`id` IN ?", ids).Find(&parents).Error; err != nil {
+				return nil, err
+			}
+			for _, parent := range parents {
+				nodes[parent.ID] = &treeNode{Page: parent}
+			}
+		}
+	}
+
+	// parent_id relations
+	for _, page := range pages {
+		node := nodes[page.ID]
+
+		if page.ParentID.Int64 == 0 {
+			continue
+		}
+
+		parent, exists := nodes[page.ParentID.Int64]
+		if !exists {
+			issue(page, models.TreeIssueOrphan, "parent page %d does not exist", page.ParentID.Int64)
+			continue
+		}
+
+		// checking one space only, the pages under the other spaces are reported and not changed
+		if params.SpaceID > 0 && parent.SpaceID != params.SpaceID {
+			issue(page, models.TreeIssueCrossSpace, "parent page %d is in space %d", parent.ID, parent.SpaceID)
+			node.foreign = true
+			continue
+		}
+
+		if parent.SpaceID != page.SpaceID {
+			issue(page, models.TreeIssueSpace, "space %d differs from the parent page %d space %d", page.SpaceID, parent.ID, parent.SpaceID)
+		}
+
+		node.parent = parent
+		parent.children = append(parent.children, node)
+	}
+
+	for _, page := range pages {
+		node := nodes[page.ID]
+
+		if page.ChildrenCount != len(node.children) {
+			issue(page, models.TreeIssueChildrenCount, "children_count is %d, but has %d children", page.ChildrenCount, len(node.children))
+		}
+
+		depth, cycle := 0, false
+		for p, visited := node.parent, map[int64]bool{page.ID: true}; p != nil; p = p.parent {
+			if visited[p.ID] {
+				cycle = true
+				break
+			}
+			visited[p.ID] = true
+			depth++
+		}
+
+		if cycle {
+			issue(page, models.TreeIssueCycle, "parent_id chain is a cycle")
+		} else if page.Depth != depth {
+			issue(page, models.TreeIssueDepth, "depth is %d, but %d by parent_id", page.Depth, depth)
+		}
+	}
+
+	// nested set intervals, the pages are ordered by space and lft
+	var (
+		spaceID int64 = -1
+		stack   []*models.Page
+		bounds  map[int]int64
+	)
+
+	for _, page := range pages {
+		if page.SpaceID != spaceID {
+			spaceID, stack, bounds = page.SpaceID, nil, map[int]int64{}
+		}
+
+		if page.Lft >= page.Rgt {
+			issue(page, models.TreeIssueInterval, "lft %d is not less than rgt %d", page.Lft, page.Rgt)
+			continue
+		}
+
+		for _, bound := range []int{page.Lft, page.Rgt} {
+			if id, exists := bounds[bound]; exists {
+				issue(page, models.TreeIssueOverlap, "bound %d is shared with page %d", bound, id)
+			}
+			bounds[bound] = page.ID
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Rgt < page.Lft {
+			stack = stack[:len(stack)-1]
+		}
+
+		var enclosing int64
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if page.Rgt > top.Rgt {
+				issue(page, models.TreeIssueOverlap, "interval [%d, %d] overlaps page %d [%d, %d]", page.Lft, page.Rgt, top.ID, top.Lft, top.Rgt)
+			}
+			enclosing = top.ID
+		}
+
+		if node := nodes[page.ID]; enclosing != page.ParentID.Int64 && !(node.foreign && enclosing == 0) {
+			issue(page, models.TreeIssueParent, "nested set parent is %d, but parent_id is %d", enclosing, page.ParentID.Int64)
+		}
+
+		stack = append(stack, page)
+	}
+
+	if !params.Repair || len(report.Issues) == 0 {
+		return report, nil
+	}
+
+	var rebuilt []*rebuiltPage
+
+	err := database.Transaction(func(tx *gorm.DB) (err error) {
+		rebuilt, err = rebuildPageTree(tx, pages, nodes, params.DryRun)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Repaired = len(rebuilt)
+
+	return report, nil
+}
+
+// rebuiltPage page with rebuilt nested set values
+type rebuiltPage struct {
+	page    *models.Page
+	updates map[string]interface{}
+}
+
+// rebuildPageTree rebuild nested set values from parent_id,
+// orphans and pages in a parent_id cycle become roots, pages follow the space of their parents
+func rebuildPageTree(tx *gorm.DB, pages []*models.Page, nodes map[int64]*treeNode, dryRun bool) ([]*rebuiltPage, error) {
+
+	var (
+		rebuilt  []*rebuiltPage
+		visited  = make(map[int64]bool, len(pages))
+		counters = map[int64]int{}
+		position = make(map[int64]int, len(pages))
+		walk     func(node *treeNode, parent *models.Page, spaceID int64, depth int, parentPath string)
+	)
+
+	for i, page := range pages {
+		position[page.ID] = i
+	}
+
+	for _, node := range nodes {
+		sort.SliceStable(node.children, func(i, j int) bool {
+			return position[node.children[i].ID] < position[node.children[j].ID]
+		})
+	}
+
+	walk = func(node *treeNode, parent *models.Page, spaceID int64, depth int, parentPath string) {
+		visited[node.ID] = true
+
+		counters[spaceID]++
+		lft := counters[spaceID]
+
+		path := ""
+		if node.Slug != "" {
+			path = joinPath(parentPath, node.Slug)
+		}
+
+		children := 0
+		for _, child := range node.children {
+			if visited[child.ID] {
+				continue
+			}
+			children++
+			walk(child, node.Page, spaceID, depth+1, path)
+		}
+
+		counters[spaceID]++
+		rgt := counters[spaceID]
+
+		var (
+			page    = node.Page
+			updates = map[string]interface{}{}
+		)
+
+		parentID := int64(0)
+		switch {
+		case parent != nil:
+			parentID = parent.ID
+		case node.foreign:
+			parentID = page.ParentID.Int64
+		}
+
+		if page.ParentID.Int64 != parentID {
+			updates["parent_id"] = parentID
+		}
+		if page.SpaceID != spaceID {
+			updates["space_id"] = spaceID
+		}
+		if page.Lft != lft {
+			updates["lft"] = lft
+		}
+		if page.Rgt != rgt {
+			updates["rgt"] = rgt
+		}
+		if page.Depth != depth {
+			updates["depth"] = depth
+		}
+		if page.ChildrenCount != children {
+			updates["children_count"] = children
+		}
+		if page.Path != path {
+			updates["path"] = path
+		}
+
+		if len(updates) > 0 {
+			rebuilt = append(rebuilt, &rebuiltPage{page: page, updates: updates})
+		}
+	}
+
+	// roots first
+	for _, page := range pages {
+		node := nodes[page.ID]
+		if node.parent == nil {
+			walk(node, nil, page.SpaceID, 0, "")
+		}
+	}
+
+	// then the pages in parent_id cycles or under parents out of the checked space,
+	// the topmost page of the chain becomes a root
+	for _, page := range pages {
+		if visited[page.ID] {
+			continue
+		}
+
+		var (
+			top  = nodes[page.ID]
+			seen = map[int64]bool{top.ID: true}
+		)
+
+		for top.parent != nil {
+			if _, checked := position[top.parent.ID]; !checked || seen[top.parent.ID] {
+				break
+			}
+			top = top.parent
+			seen[top.ID] = true
+		}
+
+		walk(top, nil, top.SpaceID, 0, "")
+	}
+
+	if dryRun {
+		return rebuilt, nil
+	}
+
+	for _, r := range rebuilt {
+		if err := tx.Model(&models.Page{}).Where("`id` = ?", r.page.ID).UpdateColumns(r.updates).Error; err != nil {
+			return nil, err
+		}
+
+		if spaceID, moved := r.updates["space_id"]; moved {
+			err := tx.Model(&models.PageContent{}).Where("`page_id` = ?", r.page.ID).UpdateColumn("space_id", spaceID).Error
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if _, changed := r.updates["path"]; changed {
+			if err := recordRedirect(tx, r.page.SpaceID, r.page.Path, r.page.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rebuilt, nil
+}
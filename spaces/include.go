@@ -0,0 +1,130 @@
+package spaces
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/markdown"
+)
+
+// includedContent return the content of the included page in the lang and version of the target, falls back to
+// the default version, then to the fallback lang and the lang of the space,
+// the pages of other spaces, the drafts and the restricted pages the target is out of are not included
+func includedContent(tx *gorm.DB, target *renderTarget, pageID int64) (*models.PageContent, error) {
+
+	var (
+		space    *models.Space
+		page     *models.Page
+		contents []*models.PageContent
+	)
+
+	err := tx.Where("`id` = ? AND `space_id` = ?", pageID, target.SpaceID).First(&page).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: page %d is not found in the space", markdown.ErrIncludeIsInvalid, pageID)
+		}
+		return nil, err
+	}
+
+	restricted, err := pageRestricted(tx, target, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if restricted {
+		return nil, fmt.Errorf("%w: page %d is restricted", markdown.ErrIncludeIsInvalid, pageID)
+	}
+
+	if err := tx.Where("`id` = ?", target.SpaceID).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	err = tx.Omit("html").Where("`page_id` = ? AND `status` = ?", page.ID, models.PageStatusPublished).Find(&contents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lang := range lo.Uniq([]string{target.Lang, space.FallbackLang, space.Lang}) {
+		for _, version := range lo.Uniq([]string{target.Version, ""}) {
+			for _, content := range contents {
+				if content.Lang == lang && content.Version == version {
+					return content, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: page %d has no content in %s", markdown.ErrIncludeIsInvalid, pageID, target.Lang)
+}
+
+// pageRestricted return the page is in a restricted subtree the target is out of,
+// the included or linked content is readable by all readers of the target
+func pageRestricted(tx *gorm.DB, target *renderTarget, page *models.Page) (bool, error) {
+
+	restricted, err := target.restrictedPages(tx, page.SpaceID)
+	if err != nil {
+		return false, err
+	}
+
+	if len(restricted) == 0 {
+		return false, nil
+	}
+
+	position, err := target.position(tx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range restricted {
+		if !inSubtrees(page, []*models.Page{r}) {
+			continue
+		}
+		if position == nil || !inSubtrees(position, []*models.Page{r}) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// saveIncludes replace the included pages of the page content
+func saveIncludes(tx *gorm.DB, content *models.PageContent, targets []int64) error {
+
+	if err := tx.Where("`content_id` = ?", content.ID).Delete(&models.PageInclude{}).Error; err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	includes := lo.Map(targets, func(target int64, _ int) *models.PageInclude {
+		return &models.PageInclude{
+			SpaceID:      content.SpaceID,
+			PageID:       content.PageID,
+			ContentID:    content.ID,
+			TargetPageID: target,
+		}
+	})
+
+	return tx.Create(&includes).Error
+}
+
+// renderIncluders render the contents including the pages again, the nested includes are recorded,
+// so the contents including the pages through other pages are rendered too
+func (s *service) renderIncluders(tx *gorm.DB, pageIDs ...int64) error {
+
+	var contents []*models.PageContent
+
+	err := tx.Where("`id` IN (?)", tx.Model(&models.PageInclude{}).Select("content_id").Where("`target_page_id` IN ?", pageIDs)).
+		Find(&contents).Error
+	if err != nil {
+		return err
+	}
+
+	return s.renderContents(tx, contents)
+}
@@ -0,0 +1,411 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/spaces/params"
+)
+
+// pageURL return the website url of the page, the lang prefix is resolved by the website
+func pageURL(space *models.Space, page *models.Page) string {
+	if page.ID == space.HomepageID {
+		return "/docs/" + space.Key
+	}
+	return "/docs/" + space.Key + "/" + page.Pathname()
+}
+
+// resolvePageLink return the linked page with its space and published content, or gorm.ErrRecordNotFound,
+// the title links match the content title or short title in any language, the linked page is readable by all
+// readers of the target, it is in the space of the target or a public space and out of the restricted subtrees
+func resolvePageLink(tx *gorm.DB, target *renderTarget, link *markdown.PageLink) (*models.Page, error) {
+
+	var (
+		space   *models.Space
+		page    *models.Page
+		content *models.PageContent
+	)
+
+	if link.PageID > 0 {
+		if err := tx.Where("`id` = ?", link.PageID).First(&page).Error; err != nil {
+			return nil, err
+		}
+		err := tx.Where("`page_id` = ? AND `status` = ?", page.ID, models.PageStatusPublished).Order("`id` ASC").First(&content).Error
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Where("`id` = ?", page.SpaceID).First(&space).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		db := tx.Where("`id` = ?", target.SpaceID)
+		if link.SpaceKey != "" {
+			db = tx.Where("`key` = ?", link.SpaceKey)
+		}
+
+		if err := db.First(&space).Error; err != nil {
+			return nil, err
+		}
+
+		err := tx.Where("`space_id` = ? AND `status` = ? AND (`title` = ? OR `short_title` = ?)", space.ID, models.PageStatusPublished, link.Title, link.Title).
+			Order("`page_id` ASC, `id` ASC").
+			First(&content).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tx.Where("`id` = ?", content.PageID).First(&page).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if space.ID != target.SpaceID && !space.IsPublic() {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	restricted, err := pageRestricted(tx, target, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if restricted {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	page.Space, page.Content = space, content
+
+	return page, nil
+}
+
+// containsPattern return the LIKE pattern matching the text anywhere, the wildcards of the text are escaped
+// by `!`, e.g. "`body` LIKE ? ESCAPE '!'"
+func containsPattern(text string) string {
+	return "%" + strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(text) + "%"
+}
+
+// renderLinkers render the contents linking to the pages again, the link urls and titles follow the pages
+func (s *service) renderLinkers(tx *gorm.DB, pageIDs ...int64) error {
+
+	var contents []*models.PageContent
+
+	err := tx.Where("`id` IN (?)", tx.Model(&models.PageLink{}).Select("content_id").Where("`target_page_id` IN ?", pageIDs)).
+		Find(&contents).Error
+	if err != nil {
+		return err
+	}
+
+	return s.renderContents(tx, contents)
+}
+
+// renderMissingLinks render the contents with missing page links again, the links may match the new titles
+func (s *service) renderMissingLinks(tx *gorm.DB, titles ...string) error {
+
+	titles = lo.Uniq(lo.Compact(titles))
+	if len(titles) == 0 {
+		return nil
+	}
+
+	var (
+		conditions = lo.Map(titles, func(string, int) string { return "`body` LIKE ? ESCAPE '!'" })
+		args       = lo.Map(titles, func(title string, _ int) any { return containsPattern(title) })
+		contents   []*models.PageContent
+	)
+
+	err := tx.Where("`html` LIKE ?", "%wiki-link-missing%").
+		Where("("+strings.Join(conditions, " OR ")+")", args...).
+		Find(&contents).Error
+	if err != nil {
+		return err
+	}
+
+	return s.renderContents(tx, contents)
+}
+
+// renderTarget the page content being rendered, the includes resolve in its space, lang and version
+type renderTarget struct {
+	SpaceID  int64
+	PageID   int64 // zero for a new page
+	ParentID int64 // the parent of a new page
+	Lang     string
+	Version  string
+
+	cached     bool                     // the diagrams are looked up in the cache only, in the transactions
+	restricted map[int64][]*models.Page // the restricted pages by space, loaded once per rendering
+	page       *models.Page             // the target page or the parent of a new page, loaded once per rendering
+	located    bool
+}
+
+// position return the target page, or the parent of a new page, nil for a new top level page
+func (target *renderTarget) position(tx *gorm.DB) (*models.Page, error) {
+
+	if target.located {
+		return target.page, nil
+	}
+
+	if id := lo.Ternary(target.PageID > 0, target.PageID, target.ParentID); id > 0 {
+		if err := tx.Where("`id` = ?", id).First(&target.page).Error; err != nil {
+			return nil, err
+		}
+	}
+	target.located = true
+
+	return target.page, nil
+}
+
+// restrictedPages return the pages with restrictions of the space
+func (target *renderTarget) restrictedPages(tx *gorm.DB, spaceID int64) ([]*models.Page, error) {
+
+	if pages, ok := target.restricted[spaceID]; ok {
+		return pages, nil
+	}
+
+	var pages []*models.Page
+
+	err := tx.Where("`id` IN (?)", tx.Model(&models.PageRestriction{}).Select("page_id").Where("`space_id` = ?", spaceID)).
+		Find(&pages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if target.restricted == nil {
+		target.restricted = map[int64][]*models.Page{}
+	}
+	target.restricted[spaceID] = pages
+
+	return pages, nil
+}
+
+// contentTarget return the render target of the saved page content
+func contentTarget(content *models.PageContent) *renderTarget {
+	return &renderTarget{
+		SpaceID: content.SpaceID,
+		PageID:  content.PageID,
+		Lang:    content.Lang,
+		Version: content.Version,
+	}
+}
+
+// rendered markdown of a page content
+type rendered struct {
+	*markdown.Result
+	Links    []int64 // the linked page ids
+	Includes []int64 // the included page ids, the nested and the failed includes too
+}
+
+// parseMarkdown render the markdown of the space page to html and toc, return the linked and included page ids,
+// the internal page links are resolved, the attachment images get the size and srcset and the variables of the
+// space and the version are substituted
+func (s *service) parseMarkdown(tx *gorm.DB, target *renderTarget, body string) (*rendered, error) {
+
+	var (
+		result  = &rendered{}
+		sources = []string{body} // the body and the included bodies
+		images  map[int64]*markdown.Image
+		failure error
+	)
+
+	// the restricted and missing includes are recorded too, they are rendered again when the pages change
+	include := func(include *markdown.Include) (string, error) {
+		result.Includes = append(result.Includes, include.PageID)
+
+		content, err := includedContent(tx, target, include.PageID)
+		if err != nil {
+			return "", err
+		}
+
+		sources = append(sources, content.Body)
+
+		return content.Body, nil
+	}
+
+	// the images are loaded after the includes are expanded
+	image := func(id int64) *markdown.Image {
+		if images == nil && failure == nil {
+			if images, failure = attachmentImages(tx, strings.Join(sources, "\n")); failure != nil {
+				return nil
+			}
+		}
+		return images[id]
+	}
+
+	resolve := func(link *markdown.PageLink) (string, string) {
+		page, err := resolvePageLink(tx, target, link)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) && failure == nil {
+				failure = err
+			}
+			return "", ""
+		}
+
+		result.Links = append(result.Links, page.ID)
+
+		return pageURL(page.Space, page), page.Content.Title
+	}
+
+	var diagram markdown.DiagramRenderer
+	if s.DiagramRenderer != nil {
+		diagram = lo.Ternary(target.cached, s.DiagramRenderer.Cached, s.DiagramRenderer.Render)
+	}
+
+	variables, err := spaceVariables(tx, target.SpaceID, target.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Result, err = markdown.Render(body, markdown.Options{
+		Format:          true,
+		AttachmentImage: image,
+		ResolveLink:     resolve,
+		RenderDiagram:   diagram,
+		ResolveInclude:  include,
+		PageID:          target.PageID,
+		Variables:       variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if failure != nil {
+		return nil, failure
+	}
+
+	result.Links, result.Includes = lo.Uniq(result.Links), lo.Uniq(result.Includes)
+
+	return result, nil
+}
+
+// renderDiagrams render the diagrams of the saved page content out of the transactions, then the content is
+// rendered again in a transaction with the cached diagrams
+func (s *service) renderDiagrams(database *gorm.DB, content *models.PageContent) error {
+	if s.DiagramRenderer == nil {
+		return nil
+	}
+	_, err := s.parseMarkdown(database, contentTarget(content), content.Body)
+	return err
+}
+
+// renderContents render the saved page contents again, the links and the includes are rebuilt, the diagrams
+// not cached yet are rendered by the website script, the transactions do not wait for the diagram renderers
+func (s *service) renderContents(tx *gorm.DB, contents []*models.PageContent) error {
+
+	for _, content := range contents {
+
+		target := contentTarget(content)
+		target.cached = true
+
+		result, err := s.parseMarkdown(tx, target, content.Body)
+		if err != nil {
+			return err
+		}
+		content.HTML, content.TOC = result.HTML, result.TOC
+
+		if err := tx.Model(content).Select("html", "toc").UpdateColumns(content).Error; err != nil {
+			return err
+		}
+
+		if err := saveLinks(tx, content, result.Links); err != nil {
+			return err
+		}
+
+		if err := saveIncludes(tx, content, result.Includes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveLinks replace the links of the page content
+func saveLinks(tx *gorm.DB, content *models.PageContent, targets []int64) error {
+
+	if err := tx.Where("`content_id` = ?", content.ID).Delete(&models.PageLink{}).Error; err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	links := lo.Map(targets, func(target int64, _ int) *models.PageLink {
+		return &models.PageLink{
+			SpaceID:      content.SpaceID,
+			PageID:       content.PageID,
+			ContentID:    content.ID,
+			TargetPageID: target,
+		}
+	})
+
+	return tx.Create(&links).Error
+}
+
+func (s *service) DescribeBacklinks(ctx context.Context, params *params.DescribeBacklinks) ([]*models.Page, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		contents []*models.PageContent
+		pages    = []*models.Page{}
+	)
+
+	// the page contents linking to the page, in the spaces visible to the viewer
+	db := database.Omit("body", "html").
+		Where("`id` IN (?)", database.Model(&models.PageLink{}).Select("content_id").Where("`target_page_id` = ? AND `page_id` <> ?", params.PageID, params.PageID))
+
+	if !params.Viewer.IsUnrestricted() {
+		db = db.Where("`space_id` IN (?)", database.Model(&models.Space{}).Select("id").Where(visibleSpaces(s.Database.DB, params.Viewer)))
+	}
+
+	if err := db.Order("`page_id` ASC, `id` ASC").Find(&contents).Error; err != nil {
+		return nil, err
+	}
+
+	if len(contents) == 0 {
+		return pages, nil
+	}
+
+	// one content per page, the content in the space language is preferred
+	var (
+		spaces  []*models.Space
+		sources []*models.Page
+	)
+
+	spaceIDs := lo.Uniq(lo.Map(contents, func(content *models.PageContent, _ int) int64 { return content.SpaceID }))
+	if err := database.Where("`id` IN ?", spaceIDs).Find(&spaces).Error; err != nil {
+		return nil, err
+	}
+
+	pageIDs := lo.Uniq(lo.Map(contents, func(content *models.PageContent, _ int) int64 { return content.PageID }))
+	if err := database.Where("`id` IN ?", pageIDs).Order("`space_id` ASC, `lft` ASC").Find(&sources).Error; err != nil {
+		return nil, err
+	}
+
+	denied, err := s.deniedPages(ctx, 0, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	spaceIndex := lo.KeyBy(spaces, func(space *models.Space) int64 { return space.ID })
+
+	for _, page := range sources {
+		space, exists := spaceIndex[page.SpaceID]
+		if !exists || inSubtrees(page, denied) {
+			continue
+		}
+
+		for _, content := range contents {
+			if content.PageID == page.ID && (page.Content == nil || content.Lang == space.Lang) {
+				page.Content = content
+			}
+		}
+
+		page.Space = space
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
@@ -0,0 +1,479 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fox-gonic/fox/database"
+	"github.com/fox-gonic/fox/logger"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/linkcheck"
+	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/spaces/params"
+)
+
+var (
+	// ErrLinkCheckIsRunning a link check of the space is not finished
+	ErrLinkCheckIsRunning = errors.New("link check is running")
+)
+
+// LinkCheckTimeout the link check is failed if not finished in the timeout,
+// the running checks interrupted by a restart are stale after the timeout too
+var LinkCheckTimeout = 30 * time.Minute
+
+// LinkCheckConcurrency max link checks running at the same time, the others are pending
+var LinkCheckConcurrency = 2
+
+// websiteLinkRegexp match website page urls, e.g. `/en-US/docs/website`, `/docs/website/12` or `/docs/website/getting-started/install`
+var websiteLinkRegexp = regexp.MustCompile(`^/(?:([A-Za-z]{2,3}(?:-[A-Za-z0-9]+)*)/)?docs/([^/]+)(?:/(.*))?$`)
+
+func (s *service) CreateLinkCheck(ctx context.Context, params *params.CreateLinkCheck) (*models.LinkCheck, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	if err := database.Where("`id` = ?", params.SpaceID).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	// the stale checks interrupted by a restart
+	err := database.Model(&models.LinkCheck{}).
+		Where("`running_id` = ? AND `updated_at` < ?", space.ID, time.Now().Add(-LinkCheckTimeout).Unix()).
+		Updates(map[string]interface{}{
+			"status":      models.LinkCheckStatusFailed,
+			"running_id":  nil,
+			"error":       "link check is interrupted",
+			"finished_at": time.Now().Unix(),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	check := &models.LinkCheck{
+		SpaceID:   space.ID,
+		CreatorID: params.CreatorID,
+		Status:    models.LinkCheckStatusPending,
+		RunningID: &space.ID,
+		External:  params.External && s.LinkChecker != nil,
+		Issues:    []*models.LinkIssue{},
+	}
+
+	// the unique running id rejects the concurrent checks of the space
+	if err := database.Create(check).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrLinkCheckIsRunning
+		}
+		return nil, err
+	}
+
+	// the job outlives the request
+	go s.runLinkCheck(check)
+
+	return check, nil
+}
+
+func (s *service) DescribeLinkChecks(ctx context.Context, params *params.DescribeLinkChecks) (*database.Pagination[*models.LinkCheck], error) {
+
+	var (
+		database   = s.Database.WithContext(ctx)
+		pagination = &params.Pagination
+	)
+
+	// the issues are described by the check detail
+	database = database.Omit("issues").Where("`space_id` = ?", params.SpaceID)
+
+	if err := database.Model(&pagination.Items).Count(&pagination.Total).Error; err != nil {
+		return nil, err
+	}
+
+	database = database.Scopes(pagination.Paginate()).Order("`id` DESC")
+
+	if err := database.Find(&pagination.Items).Error; err != nil {
+		return nil, err
+	}
+
+	return pagination, nil
+}
+
+func (s *service) DescribeLinkCheck(ctx context.Context, params *params.DescribeLinkCheck) (*models.LinkCheck, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		check    *models.LinkCheck
+	)
+
+	err := database.Where("`id` = ? AND `space_id` = ?", params.ID, params.SpaceID).First(&check).Error
+	if err != nil {
+		return nil, err
+	}
+
+	denied, err := s.deniedPages(ctx, params.SpaceID, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(denied) > 0 {
+		var pages []*models.Page
+
+		ids := lo.Uniq(lo.Map(check.Issues, func(issue *models.LinkIssue, _ int) int64 { return issue.PageID }))
+		if err := database.Select("id", "space_id", "lft", "rgt").Where("`id` IN ?", ids).Find(&pages).Error; err != nil {
+			return nil, err
+		}
+
+		hidden := lo.SliceToMap(lo.Filter(pages, func(page *models.Page, _ int) bool { return inSubtrees(page, denied) }),
+			func(page *models.Page) (int64, bool) { return page.ID, true })
+
+		check.Issues = lo.Filter(check.Issues, func(issue *models.LinkIssue, _ int) bool { return !hidden[issue.PageID] })
+	}
+
+	return check, nil
+}
+
+// runLinkCheck run the link check job and save the result
+func (s *service) runLinkCheck(check *models.LinkCheck) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), LinkCheckTimeout)
+	defer cancel()
+
+	database := s.Database.WithContext(ctx)
+
+	// the check is pending until a slot is free
+	select {
+	case s.linkChecks <- struct{}{}:
+		defer func() { <-s.linkChecks }()
+
+		check.Status = models.LinkCheckStatusRunning
+		check.StartedAt = time.Now().Unix()
+
+		if err := database.Select("status", "started_at").Updates(check).Error; err != nil {
+			check.Status = models.LinkCheckStatusFailed
+			check.Error = err.Error()
+		}
+
+	case <-ctx.Done():
+		check.Status = models.LinkCheckStatusFailed
+		check.Error = ctx.Err().Error()
+	}
+
+	if check.Status == models.LinkCheckStatusRunning {
+		if err := s.checkLinks(ctx, check); err != nil {
+			check.Status = models.LinkCheckStatusFailed
+			check.Error = err.Error()
+		} else {
+			check.Status = models.LinkCheckStatusCompleted
+		}
+	}
+
+	check.FinishedAt = time.Now().Unix()
+	check.RunningID = nil
+
+	// the job context may be timed out
+	err := s.Database.Select("status", "running_id", "contents", "links", "issues", "error", "finished_at").Updates(check).Error
+	if err != nil {
+		logger.New("LinkCheck").Errorf("save link check %d failed, err: %+v", check.ID, err)
+	}
+}
+
+// checkLinks check the links of the rendered page contents of the space
+func (s *service) checkLinks(ctx context.Context, check *models.LinkCheck) error {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		contents []*models.PageContent
+		checker  = &linkChecker{
+			tx:          database,
+			external:    check.External,
+			checker:     s.LinkChecker,
+			spaces:      map[string]*models.Space{},
+			pages:       map[string]*models.Page{},
+			anchors:     map[string]map[string]bool{},
+			attachments: map[int64]bool{},
+			urls:        map[string]error{},
+		}
+	)
+
+	return database.Select("id", "space_id", "page_id", "lang", "version", "title", "html").
+		Where("`space_id` = ?", check.SpaceID).
+		Order("`page_id` ASC, `id` ASC").
+		FindInBatches(&contents, 100, func(tx *gorm.DB, _ int) error {
+			for _, content := range contents {
+
+				doc, err := linkcheck.Parse(content.HTML)
+				if err != nil {
+					return err
+				}
+
+				check.Contents++
+
+				for _, link := range doc.Links {
+					kind, message, err := checker.check(ctx, doc, link)
+					if err != nil {
+						return err
+					}
+
+					check.Links++
+
+					if kind != "" {
+						check.Issues = append(check.Issues, &models.LinkIssue{
+							PageID:    content.PageID,
+							ContentID: content.ID,
+							Lang:      content.Lang,
+							Version:   content.Version,
+							Title:     content.Title,
+							Kind:      kind,
+							Link:      link,
+							Message:   message,
+						})
+					}
+				}
+			}
+			return nil
+		}).Error
+}
+
+// linkChecker resolve the links of a space, the resolved spaces, pages, anchors, attachments
+// and external urls are cached in the check
+type linkChecker struct {
+	tx       *gorm.DB
+	external bool
+	checker  linkcheck.Checker
+
+	spaces      map[string]*models.Space   // space key => space, nil if not found
+	pages       map[string]*models.Page    // space id/path => page, nil if not found
+	anchors     map[string]map[string]bool // page id/lang => anchors
+	attachments map[int64]bool
+	urls        map[string]error
+}
+
+// check return the issue kind and message of the broken link, empty kind if the link is fine,
+// or the error of the database
+func (c *linkChecker) check(ctx context.Context, doc *linkcheck.Document, link string) (models.LinkIssueKind, string, error) {
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return models.LinkIssuePage, err.Error(), nil
+	}
+
+	switch {
+	case u.Scheme == "http" || u.Scheme == "https":
+		if !c.external || u.Host == "" {
+			return "", "", nil
+		}
+
+		failure, exists := c.urls[link]
+		if !exists {
+			failure = c.checker.Check(ctx, link)
+			c.urls[link] = failure
+		}
+
+		if failure != nil {
+			return models.LinkIssueExternal, failure.Error(), nil
+		}
+
+		return "", "", nil
+
+	case u.Scheme != "" || u.Host != "":
+		// mailto:, tel: and the protocol relative urls
+		return "", "", nil
+
+	case u.Path == "":
+		if u.Fragment != "" && !doc.Anchors[u.Fragment] {
+			return models.LinkIssueAnchor, fmt.Sprintf("anchor #%s not found", u.Fragment), nil
+		}
+		return "", "", nil
+
+	case strings.HasPrefix(u.Path, markdown.AttachmentPath):
+		return c.checkAttachment(u)
+
+	default:
+		matches := websiteLinkRegexp.FindStringSubmatch(u.Path)
+		if matches == nil {
+			return "", "", nil
+		}
+		return c.checkPage(matches[1], matches[2], strings.Trim(matches[3], "/"), u.Fragment)
+	}
+}
+
+func (c *linkChecker) checkAttachment(u *url.URL) (models.LinkIssueKind, string, error) {
+
+	id, _, _ := strings.Cut(strings.TrimPrefix(u.Path, markdown.AttachmentPath), "/")
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return models.LinkIssueAttachment, "attachment not found", nil
+	}
+
+	exists, cached := c.attachments[n]
+	if !cached {
+		var count int64
+		if err := c.tx.Model(&models.Attachment{}).Where("`id` = ?", n).Count(&count).Error; err != nil {
+			return "", "", err
+		}
+		exists = count > 0
+		c.attachments[n] = exists
+	}
+
+	if !exists {
+		return models.LinkIssueAttachment, "attachment not found", nil
+	}
+
+	return "", "", nil
+}
+
+func (c *linkChecker) checkPage(lang, key, path, fragment string) (models.LinkIssueKind, string, error) {
+
+	space, err := c.space(key)
+	if err != nil {
+		return "", "", err
+	}
+	if space == nil {
+		return models.LinkIssuePage, fmt.Sprintf("space %s not found", key), nil
+	}
+
+	page, err := c.page(space, path)
+	if err != nil {
+		return "", "", err
+	}
+	if page == nil {
+		return models.LinkIssuePage, "page not found", nil
+	}
+
+	if fragment == "" {
+		return "", "", nil
+	}
+
+	if lang == "" {
+		lang = space.Lang
+	}
+
+	anchors, err := c.pageAnchors(page, lang)
+	if err != nil {
+		return "", "", err
+	}
+
+	if !anchors[fragment] {
+		return models.LinkIssueAnchor, fmt.Sprintf("anchor #%s not found", fragment), nil
+	}
+
+	return "", "", nil
+}
+
+// space return the space of the key or its previous key, nil if not found
+func (c *linkChecker) space(key string) (*models.Space, error) {
+
+	if space, exists := c.spaces[key]; exists {
+		return space, nil
+	}
+
+	var space *models.Space
+
+	err := c.tx.Where("`key` = ?", key).First(&space).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var moved *SpaceMovedError
+		if space, err = movedSpace(c.tx, key); errors.As(err, &moved) {
+			err = nil
+		}
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		space = nil
+	case err != nil:
+		return nil, err
+	}
+
+	c.spaces[key] = space
+
+	return space, nil
+}
+
+// page return the page of the website path, the homepage for the empty path, the page id for
+// the numeric path, or the redirected page, nil if not found
+func (c *linkChecker) page(space *models.Space, path string) (*models.Page, error) {
+
+	cacheKey := fmt.Sprintf("%d/%s", space.ID, path)
+	if page, exists := c.pages[cacheKey]; exists {
+		return page, nil
+	}
+
+	var (
+		page *models.Page
+		db   = c.tx.Where("`space_id` = ?", space.ID)
+	)
+
+	if id, err := strconv.ParseInt(path, 10, 64); err == nil {
+		db = db.Where("`id` = ?", id)
+	} else if path == "" {
+		db = db.Where("`id` = ?", space.HomepageID)
+	} else {
+		db = db.Where("`path` = ?", path)
+	}
+
+	err := db.First(&page).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && path != "" {
+		var redirect *models.Redirect
+		if err = c.tx.Where("`space_id` = ? AND `path` = ?", space.ID, path).First(&redirect).Error; err == nil {
+			err = c.tx.Where("`id` = ?", redirect.PageID).First(&page).Error
+		}
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		page = nil
+	case err != nil:
+		return nil, err
+	}
+
+	c.pages[cacheKey] = page
+
+	return page, nil
+}
+
+// pageAnchors return the anchors of the page content in the lang, or the first content if not translated
+func (c *linkChecker) pageAnchors(page *models.Page, lang string) (map[string]bool, error) {
+
+	cacheKey := fmt.Sprintf("%d/%s", page.ID, lang)
+	if anchors, exists := c.anchors[cacheKey]; exists {
+		return anchors, nil
+	}
+
+	var contents []*models.PageContent
+
+	err := c.tx.Select("id", "lang", "html").Where("`page_id` = ?", page.ID).Order("`id` ASC").Find(&contents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	anchors := map[string]bool{}
+
+	if len(contents) > 0 {
+		content := contents[0]
+		for _, c := range contents {
+			if c.Lang == lang {
+				content = c
+				break
+			}
+		}
+
+		doc, err := linkcheck.Parse(content.HTML)
+		if err != nil {
+			return nil, err
+		}
+		anchors = doc.Anchors
+	}
+
+	c.anchors[cacheKey] = anchors
+
+	return anchors, nil
+}
@@ -0,0 +1,209 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fox-gonic/fox/database"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+var (
+	// ErrSpaceLastOwner the last owner of a space can not be removed or demoted
+	ErrSpaceLastOwner = errors.New("space must have at least one owner")
+)
+
+// checkLastOwner return ErrSpaceLastOwner if the member is the only owner of the space
+func checkLastOwner(tx *gorm.DB, member *models.Member) error {
+	if member.Role != models.MemberRoleOwner {
+		return nil
+	}
+
+	var count int64
+	err := tx.Model(&models.Member{}).
+		Where("`space_id` = ? AND `role` = ? AND `account_id` <> ?", member.SpaceID, models.MemberRoleOwner, member.AccountID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrSpaceLastOwner
+	}
+
+	return nil
+}
+
+func (s *service) CreateMember(ctx context.Context, params *params.CreateMember) (*models.Member, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+		member   *models.Member
+	)
+
+	if err := params.Role.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if err := database.Where("`id` = ?", params.SpaceID).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	if space.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
+	err := database.Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Where("`space_id` = ? AND `account_id` = ?", space.ID, params.AccountID).First(&member).Error
+		if err == nil {
+			if params.Role != models.MemberRoleOwner {
+				if err := checkLastOwner(tx, member); err != nil {
+					return err
+				}
+			}
+			member.Role = params.Role
+			return tx.Save(member).Error
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		member = &models.Member{
+			SpaceID:   space.ID,
+			AccountID: params.AccountID,
+			Role:      params.Role,
+		}
+
+		return tx.Create(member).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *service) DescribeMembers(ctx context.Context, params *params.DescribeMembers) (*database.Pagination[*models.Member], error) {
+
+	var (
+		database   = s.Database.WithContext(ctx)
+		pagination = &params.Pagination
+	)
+
+	database = database.Where("`space_id` = ?", params.SpaceID)
+
+	if params.Role != "" {
+		database = database.Where("`role` = ?", params.Role)
+	}
+
+	if err := database.Model(&pagination.Items).Count(&pagination.Total).Error; err != nil {
+		return nil, err
+	}
+
+	database = database.Scopes(pagination.Paginate()).Preload("Account").Order("`id` ASC")
+
+	if err := database.Find(&pagination.Items).Error; err != nil {
+		return nil, err
+	}
+
+	return pagination, nil
+}
+
+func (s *service) DescribeMember(ctx context.Context, params *params.DescribeMember) (*models.Member, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		member   *models.Member
+	)
+
+	err := database.Where("`space_id` = ? AND `account_id` = ?", params.SpaceID, params.AccountID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *service) UpdateMember(ctx context.Context, params *params.UpdateMember) (*models.Member, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+		member   *models.Member
+	)
+
+	if err := params.Role.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if err := database.Where("`id` = ?", params.SpaceID).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	if space.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
+	err := database.Transaction(func(tx *gorm.DB) error {
+
+		err := tx.Where("`space_id` = ? AND `account_id` = ?", params.SpaceID, params.AccountID).First(&member).Error
+		if err != nil {
+			return err
+		}
+
+		if params.Role != models.MemberRoleOwner {
+			if err := checkLastOwner(tx, member); err != nil {
+				return err
+			}
+		}
+
+		member.Role = params.Role
+
+		return tx.Save(member).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *service) DeleteMember(ctx context.Context, params *params.DeleteMember) error {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	if err := database.Where("`id` = ?", params.SpaceID).First(&space).Error; err != nil {
+		return err
+	}
+
+	if space.IsArchived() {
+		return ErrSpaceIsArchived
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+
+		var member *models.Member
+
+		err := tx.Where("`space_id` = ? AND `account_id` = ?", params.SpaceID, params.AccountID).First(&member).Error
+		if err != nil {
+			return err
+		}
+
+		if err := checkLastOwner(tx, member); err != nil {
+			return err
+		}
+
+		return tx.Delete(member).Error
+	})
+}
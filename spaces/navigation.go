@@ -0,0 +1,52 @@
+package spaces
+
+import (
+	"context"
+
+	"github.com/miclle/space/models"
+)
+
+// navigationBatchSize pages fetched per query while looking for the adjacent published page
+const navigationBatchSize = 20
+
+// adjacentPage return the previous or next published page in depth-first tree order,
+// the space homepage and the denied page subtrees are skipped
+func (s *service) adjacentPage(ctx context.Context, space *models.Space, lang string, page *models.Page, denied []*models.Page, next bool) (*models.Page, error) {
+
+	database := s.Database.WithContext(ctx)
+
+	for offset := 0; ; offset += navigationBatchSize {
+
+		var pages []*models.Page
+
+		db := database.Joins("Content", database.Omit("body", "html").Where(&models.PageContent{Lang: lang}))
+
+		if len(space.FallbackLang) > 0 && lang != space.FallbackLang {
+			db = db.Joins("FallbackContent", database.Omit("body", "html").Where(&models.PageContent{Lang: space.FallbackLang}))
+		}
+
+		db = db.Where("`space_pages`.`space_id` = ? AND `space_pages`.`id` <> ?", space.ID, space.HomepageID)
+		db = excludeSubtrees(db, denied)
+
+		if next {
+			db = db.Where("`space_pages`.`lft` > ?", page.Lft).Order("`space_pages`.`lft` ASC")
+		} else {
+			db = db.Where("`space_pages`.`lft` < ?", page.Lft).Order("`space_pages`.`lft` DESC")
+		}
+
+		if err := db.Offset(offset).Limit(navigationBatchSize).Find(&pages).Error; err != nil {
+			return nil, err
+		}
+
+		for _, p := range pages {
+			if p.Content != nil && p.Content.Status == models.PageStatusPublished {
+				p.Space = space
+				return p, nil
+			}
+		}
+
+		if len(pages) < navigationBatchSize {
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package params
+
+import "io"
+
+// CreateAttachment create page attachment params
+type CreateAttachment struct {
+	SpaceID     int64
+	PageID      int64
+	CreatorID   int64
+	Name        string
+	ContentType string // detected from the content if empty
+	Size        int64
+	Reader      io.Reader
+}
+
+// DescribeAttachments describe page attachments params
+type DescribeAttachments struct {
+	SpaceID int64
+	PageID  int64
+}
+
+// OpenAttachment open attachment content params
+type OpenAttachment struct {
+	ID      int64
+	Variant string // resized image variant name, the original content if not exists
+	Viewer  *Viewer
+}
+
+// DeleteAttachment delete page attachment params
+type DeleteAttachment struct {
+	ID      int64
+	SpaceID int64
+	PageID  int64
+}
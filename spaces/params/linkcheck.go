@@ -0,0 +1,27 @@
+package params
+
+import (
+	"github.com/fox-gonic/fox/database"
+
+	"github.com/miclle/space/models"
+)
+
+// CreateLinkCheck create space link check params
+type CreateLinkCheck struct {
+	SpaceID   int64
+	CreatorID int64
+	External  bool // check the external urls with the link checker of the service
+}
+
+// DescribeLinkChecks describe space link checks params
+type DescribeLinkChecks struct {
+	database.Pagination[*models.LinkCheck]
+	SpaceID int64
+}
+
+// DescribeLinkCheck describe space link check params
+type DescribeLinkCheck struct {
+	ID      int64
+	SpaceID int64
+	Viewer  *Viewer // the issues of the restricted pages not readable by the viewer are hidden
+}
@@ -0,0 +1,40 @@
+package params
+
+import (
+	"github.com/fox-gonic/fox/database"
+
+	"github.com/miclle/space/models"
+)
+
+// CreateMember create space member params
+type CreateMember struct {
+	SpaceID   int64
+	AccountID int64
+	Role      models.MemberRole
+}
+
+// DescribeMembers describe space members params
+type DescribeMembers struct {
+	database.Pagination[*models.Member]
+	SpaceID int64
+	Role    models.MemberRole
+}
+
+// DescribeMember describe space member params
+type DescribeMember struct {
+	SpaceID   int64
+	AccountID int64
+}
+
+// UpdateMember update space member params
+type UpdateMember struct {
+	SpaceID   int64
+	AccountID int64
+	Role      models.MemberRole
+}
+
+// DeleteMember delete space member params
+type DeleteMember struct {
+	SpaceID   int64
+	AccountID int64
+}
@@ -7,15 +7,18 @@ import (
 
 // CreatePage create page params
 type CreatePage struct {
-	SpaceID    int64
-	CreatorID  int64
-	ParentID   int64
-	Lang       string
-	Version    string
-	Status     models.PageStatus
-	Title      string
-	ShortTitle string
-	Body       string
+	SpaceID     int64
+	CreatorID   int64
+	ParentID    int64
+	Lang        string
+	Version     string
+	Status      models.PageStatus
+	Title       string
+	ShortTitle  string
+	Slug        string
+	Body        string // the front matter of the body fills the empty params
+	Tags        []string
+	Description string
 }
 
 // DescribePages describe page detail params
@@ -26,30 +29,84 @@ type DescribePages struct {
 	Version  string
 	Depth    int
 	ParentID *int64
+	Viewer   *Viewer // hides the restricted pages not readable by the viewer
+}
+
+// DescribePageTree describe page tree params
+type DescribePageTree struct {
+	SpaceID  int64
+	Lang     string
+	Version  string
+	ParentID int64 // children of the page, zero means the root pages
+	After    int   // cursor returned by the previous items
+	Limit    int
+	Expand   int64   // expand the tree to the page, returns the root pages and the children of its ancestors
+	Viewer   *Viewer // hides the restricted pages not readable by the viewer
 }
 
 // DescribePage describe page detail params
 type DescribePage struct {
-	SpaceID int64
-	PageID  int64
-	Lang    string
-	Version string
+	SpaceID    int64
+	PageID     int64
+	Path       string
+	Lang       string
+	Version    string
+	Navigation bool    // find the previous and next published pages
+	Viewer     *Viewer // returns ErrPageAccessDenied if the page is not readable by the viewer
 }
 
 // UpdatePage update page params
 type UpdatePage struct {
-	ID         int64
-	Lang       *string
-	Version    *string
-	Status     *models.PageStatus
-	Title      *string
-	ShortTitle *string
-	Body       *string
+	ID          int64
+	Lang        *string
+	Version     *string
+	Status      *models.PageStatus
+	Title       *string
+	ShortTitle  *string
+	Slug        *string
+	ParentID    *int64  // move the page under the parent page of the space
+	Body        *string // the front matter of the body fills the empty params
+	Tags        *[]string
+	Description *string
+}
+
+// CopyPage copy page subtree params
+type CopyPage struct {
+	SpaceID        int64
+	PageID         int64
+	TargetSpaceID  int64
+	TargetParentID int64
+	CreatorID      int64
+	Viewer         *Viewer // skips the restricted subtrees not readable by the viewer
+}
+
+// CheckPageTree check page tree integrity params
+type CheckPageTree struct {
+	SpaceID int64 // zero means all spaces
+	Repair  bool  // rebuild the nested set from parent_id
+	DryRun  bool  // report the repair without writing
 }
 
 // Search page params
 type Search struct {
 	database.Pagination[*models.Page]
-	Lang string
-	Q    string
+	Lang   string
+	Q      string
+	Viewer *Viewer // pages of the spaces visible to the viewer
+}
+
+// DescribeBacklinks describe the pages linking to the page params
+type DescribeBacklinks struct {
+	PageID int64
+	Viewer *Viewer // pages of the spaces visible to the viewer and readable by the viewer
+}
+
+// PreviewMarkdown render the page markdown params, the variables and the includes resolve as on save
+type PreviewMarkdown struct {
+	SpaceID  int64
+	PageID   int64 // zero for a new page
+	ParentID int64 // the parent of a new page
+	Lang     string
+	Version  string
+	Body     string
 }
@@ -0,0 +1,41 @@
+package params
+
+import (
+	"github.com/fox-gonic/fox/database"
+
+	"github.com/miclle/space/models"
+)
+
+// CreateRedirect create redirect params
+type CreateRedirect struct {
+	SpaceID int64
+	Path    string
+	PageID  int64
+}
+
+// DescribeRedirects describe redirects params
+type DescribeRedirects struct {
+	database.Pagination[*models.Redirect]
+	SpaceID int64
+	Q       string
+}
+
+// DescribeRedirect describe redirect by path params
+type DescribeRedirect struct {
+	SpaceID int64
+	Path    string
+}
+
+// UpdateRedirect update redirect params
+type UpdateRedirect struct {
+	ID      int64
+	SpaceID int64
+	Path    *string
+	PageID  *int64
+}
+
+// DeleteRedirect delete redirect params
+type DeleteRedirect struct {
+	ID      int64
+	SpaceID int64
+}
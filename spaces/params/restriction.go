@@ -0,0 +1,24 @@
+package params
+
+import "github.com/miclle/space/models"
+
+// CreatePageRestriction create page restriction params, either the account or the role
+type CreatePageRestriction struct {
+	SpaceID   int64
+	PageID    int64
+	AccountID int64
+	Role      models.MemberRole
+}
+
+// DescribePageRestrictions describe page restrictions params
+type DescribePageRestrictions struct {
+	SpaceID int64
+	PageID  int64
+}
+
+// DeletePageRestriction delete page restriction params
+type DeletePageRestriction struct {
+	ID      int64
+	SpaceID int64
+	PageID  int64
+}
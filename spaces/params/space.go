@@ -16,15 +16,21 @@ type CreateSpace struct {
 	Description  string
 	Avatar       string
 	Status       models.SpaceStatus
+	Visibility   models.SpaceVisibility
 	CreatorID    int64
+	FromSpaceID  int64  // copy the page tree, contents of all languages and settings of the space
+	Template     string // or of the packaged template
 }
 
 // DescribeSpaces describe spaces params
 type DescribeSpaces struct {
 	database.Pagination[*models.Space]
-	Q       string
-	Lang    string
-	Version string
+	Q         string
+	Lang      string
+	Version   string
+	Archived  *bool   // nil means all spaces
+	AccountID int64   // spaces the account is a member of, zero means all spaces
+	Viewer    *Viewer // spaces visible to the viewer
 }
 
 // DescribeSpace describe space detail params
@@ -32,6 +38,7 @@ type DescribeSpace struct {
 	Key     string
 	Lang    string
 	Version string
+	Viewer  *Viewer // returns ErrSpaceAccessDenied if the space is not visible to the viewer
 }
 
 // UpdateSpace update space params
@@ -45,4 +52,58 @@ type UpdateSpace struct {
 	Description  *string
 	Avatar       *string
 	Status       models.SpaceStatus
+	Visibility   models.SpaceVisibility
+}
+
+// UpdateSpaceSettings update space website settings params, nil fields are unchanged
+type UpdateSpaceSettings struct {
+	Key         string
+	NavLinks    *[]*models.SpaceNavLink
+	Footer      *string
+	AccentColor *string
+	Logo        *string
+	EditURL     *string
+}
+
+// ArchiveSpace archive space params
+type ArchiveSpace struct {
+	Key string
+}
+
+// RestoreSpace restore archived space params
+type RestoreSpace struct {
+	Key string
+}
+
+// CreateDeleteSpaceToken create delete space confirmation token params
+type CreateDeleteSpaceToken struct {
+	Key string
+}
+
+// DeleteSpace delete space params
+type DeleteSpace struct {
+	Key   string
+	Token string // confirmation token, see CreateDeleteSpaceToken
+}
+
+// RenameSpaceKey change space key params, the previous key is kept as an alias
+type RenameSpaceKey struct {
+	Key    string
+	NewKey string
+}
+
+// TransferSpace transfer space ownership params
+type TransferSpace struct {
+	Key       string
+	AccountID int64 // the new owner
+	OwnerID   int64 // the owner transferring the space, stays as a maintainer
+}
+
+// DescribeSpaceStats describe space statistics params
+type DescribeSpaceStats struct {
+	SpaceID   int64
+	Days      int     // contributors window, default 90 days
+	StaleDays int     // pages not updated in the days are stale, default 180 days
+	Limit     int     // max recently updated pages, contributors and stale pages, default 10
+	Viewer    *Viewer // counts the pages readable by the viewer only
 }
@@ -0,0 +1,28 @@
+package params
+
+// CreateVariable create variable params
+type CreateVariable struct {
+	SpaceID int64
+	Version string
+	Name    string
+	Value   string
+}
+
+// DescribeVariables describe variables params
+type DescribeVariables struct {
+	SpaceID int64
+}
+
+// UpdateVariable update variable params
+type UpdateVariable struct {
+	ID      int64
+	SpaceID int64
+	Name    *string
+	Value   *string
+}
+
+// DeleteVariable delete variable params
+type DeleteVariable struct {
+	ID      int64
+	SpaceID int64
+}
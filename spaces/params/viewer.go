@@ -0,0 +1,20 @@
+package params
+
+// Viewer reader of the spaces and pages, nil viewer is anonymous
+type Viewer struct {
+	AccountID    int64 // zero means anonymous
+	Unrestricted bool  // reads all spaces and pages, e.g. administrators and maintainers
+}
+
+// IsUnrestricted return the viewer reads all spaces and pages
+func (viewer *Viewer) IsUnrestricted() bool {
+	return viewer != nil && viewer.Unrestricted
+}
+
+// Account return the account id of the viewer, zero means anonymous
+func (viewer *Viewer) Account() int64 {
+	if viewer == nil {
+		return 0
+	}
+	return viewer.AccountID
+}
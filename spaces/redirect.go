@@ -0,0 +1,177 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fox-gonic/fox/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+var (
+	// ErrRedirectPathIsInvalid redirect path is invalid
+	ErrRedirectPathIsInvalid = errors.New("redirect path is invalid")
+)
+
+// recordRedirect keep the previous path of the page, the path will redirect to the page
+func recordRedirect(tx *gorm.DB, spaceID int64, path string, pageID int64) error {
+	if path == "" {
+		return nil
+	}
+
+	redirect := &models.Redirect{
+		SpaceID: spaceID,
+		Path:    path,
+		PageID:  pageID,
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "space_id"}, {Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"page_id", "updated_at"}),
+	}).Create(redirect).Error
+}
+
+func (s *service) CreateRedirect(ctx context.Context, params *params.CreateRedirect) (*models.Redirect, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		page     *models.Page
+		path     = strings.Trim(params.Path, "/")
+	)
+
+	if path == "" {
+		return nil, ErrRedirectPathIsInvalid
+	}
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return nil, err
+	}
+
+	// the redirects lead to the pages of the space only
+	if err := database.Where("`id` = ? AND `space_id` = ?", params.PageID, params.SpaceID).First(&page).Error; err != nil {
+		return nil, err
+	}
+
+	redirect := &models.Redirect{
+		SpaceID: params.SpaceID,
+		Path:    path,
+		PageID:  page.ID,
+	}
+
+	if err := database.Create(redirect).Error; err != nil {
+		return nil, err
+	}
+
+	redirect.Page = page
+
+	return redirect, nil
+}
+
+func (s *service) DescribeRedirects(ctx context.Context, params *params.DescribeRedirects) (*database.Pagination[*models.Redirect], error) {
+
+	var (
+		database   = s.Database.WithContext(ctx)
+		pagination = &params.Pagination
+	)
+
+	database = database.Where("`space_id` = ?", params.SpaceID)
+
+	if q := strings.TrimSpace(params.Q); q != "" {
+		database = database.Where("`path` LIKE ?", fmt.Sprintf("%%%s%%", q))
+	}
+
+	if err := database.Model(&pagination.Items).Count(&pagination.Total).Error; err != nil {
+		return nil, err
+	}
+
+	database = database.Scopes(pagination.Paginate()).Order("`id` DESC")
+
+	if err := database.Find(&pagination.Items).Error; err != nil {
+		return nil, err
+	}
+
+	return pagination, nil
+}
+
+func (s *service) DescribeRedirect(ctx context.Context, params *params.DescribeRedirect) (*models.Redirect, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		redirect *models.Redirect
+	)
+
+	err := database.
+		Where("`space_id` = ? AND `path` = ?", params.SpaceID, strings.Trim(params.Path, "/")).
+		Preload("Page.Space").
+		First(&redirect).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if redirect.Page == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return redirect, nil
+}
+
+func (s *service) UpdateRedirect(ctx context.Context, params *params.UpdateRedirect) (*models.Redirect, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		redirect *models.Redirect
+	)
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return nil, err
+	}
+
+	err := database.Where("`id` = ? AND `space_id` = ?", params.ID, params.SpaceID).First(&redirect).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Path != nil {
+		path := strings.Trim(*params.Path, "/")
+		if path == "" {
+			return nil, ErrRedirectPathIsInvalid
+		}
+		redirect.Path = path
+	}
+
+	if params.PageID != nil {
+		if err := database.Where("`id` = ? AND `space_id` = ?", *params.PageID, params.SpaceID).First(&redirect.Page).Error; err != nil {
+			return nil, err
+		}
+		redirect.PageID = redirect.Page.ID
+	}
+
+	err = database.Omit(clause.Associations).Save(redirect).Error
+
+	return redirect, err
+}
+
+func (s *service) DeleteRedirect(ctx context.Context, params *params.DeleteRedirect) error {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		redirect *models.Redirect
+	)
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return err
+	}
+
+	err := database.Where("`id` = ? AND `space_id` = ?", params.ID, params.SpaceID).First(&redirect).Error
+	if err != nil {
+		return err
+	}
+
+	return database.Delete(redirect).Error
+}
@@ -0,0 +1,241 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+var (
+	// ErrPageRestrictionIsInvalid page restriction requires either an account or a role
+	ErrPageRestrictionIsInvalid = errors.New("page restriction requires either an account or a role")
+
+	// ErrPageAccessDenied page is restricted and not readable by the viewer
+	ErrPageAccessDenied = errors.New("page access denied")
+)
+
+// deniedPages return the restricted pages the viewer can not read, their descendants are denied too,
+// zero space id means all spaces
+func (s *service) deniedPages(ctx context.Context, spaceID int64, viewer *params.Viewer) ([]*models.Page, error) {
+	if viewer.IsUnrestricted() {
+		return nil, nil
+	}
+
+	var (
+		database     = s.Database.WithContext(ctx)
+		restrictions []*models.PageRestriction
+		pages        []*models.Page
+	)
+
+	db := database
+	if spaceID > 0 {
+		db = db.Where("`space_id` = ?", spaceID)
+	}
+
+	if err := db.Find(&restrictions).Error; err != nil {
+		return nil, err
+	}
+
+	if len(restrictions) == 0 {
+		return nil, nil
+	}
+
+	// the viewer roles in the spaces
+	roles := map[int64]models.MemberRole{}
+
+	if viewer.Account() > 0 {
+		var members []*models.Member
+
+		if err := database.Where("`account_id` = ?", viewer.Account()).Find(&members).Error; err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			roles[member.SpaceID] = member.Role
+		}
+	}
+
+	var (
+		allowed = map[int64]bool{}
+		ids     []int64
+	)
+
+	for _, restriction := range restrictions {
+		if restriction.Allows(viewer.Account(), roles[restriction.SpaceID]) {
+			allowed[restriction.PageID] = true
+		}
+	}
+
+	for _, restriction := range restrictions {
+		if !allowed[restriction.PageID] {
+			allowed[restriction.PageID] = true
+			ids = append(ids, restriction.PageID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	err := database.Select("id", "space_id", "lft", "rgt").Where("`id` IN ?", ids).Find(&pages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// subtrees return the condition of the pages in the subtrees of the pages
+func subtrees(pages []*models.Page) clause.Expr {
+
+	var (
+		conditions = make([]string, 0, len(pages))
+		vars       = make([]interface{}, 0, len(pages)*3)
+	)
+
+	for _, page := range pages {
+		conditions = append(conditions, "(`space_pages`.`space_id` = ? AND `space_pages`.`lft` >= ? AND `space_pages`.`rgt` <= ?)")
+		vars = append(vars, page.SpaceID, page.Lft, page.Rgt)
+	}
+
+	return gorm.Expr("("+strings.Join(conditions, " OR ")+")", vars...)
+}
+
+// excludeSubtrees hide the pages in the subtrees of the pages
+func excludeSubtrees(db *gorm.DB, pages []*models.Page) *gorm.DB {
+	if len(pages) == 0 {
+		return db
+	}
+	return db.Where("NOT ?", subtrees(pages))
+}
+
+// inSubtrees return the page is in the subtree of one of the pages
+func inSubtrees(page *models.Page, pages []*models.Page) bool {
+	for _, p := range pages {
+		if p.SpaceID == page.SpaceID && p.Lft <= page.Lft && p.Rgt >= page.Rgt {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) CreatePageRestriction(ctx context.Context, params *params.CreatePageRestriction) (*models.PageRestriction, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		page     *models.Page
+	)
+
+	if (params.AccountID > 0) == (params.Role != "") {
+		return nil, ErrPageRestrictionIsInvalid
+	}
+
+	if params.Role != "" {
+		if err := params.Role.IsValid(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return nil, err
+	}
+
+	if err := database.Where("`id` = ? AND `space_id` = ?", params.PageID, params.SpaceID).First(&page).Error; err != nil {
+		return nil, err
+	}
+
+	restriction := &models.PageRestriction{
+		SpaceID:   page.SpaceID,
+		PageID:    page.ID,
+		AccountID: params.AccountID,
+		Role:      params.Role,
+	}
+
+	err := database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(restriction).Error; err != nil {
+			return err
+		}
+		return s.renderSubtreeReferences(tx, page)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restriction, nil
+}
+
+func (s *service) DescribePageRestrictions(ctx context.Context, params *params.DescribePageRestrictions) ([]*models.PageRestriction, error) {
+
+	var (
+		database     = s.Database.WithContext(ctx)
+		restrictions = []*models.PageRestriction{}
+	)
+
+	err := database.
+		Where("`space_id` = ? AND `page_id` = ?", params.SpaceID, params.PageID).
+		Preload("Account").
+		Order("`id` ASC").
+		Find(&restrictions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return restrictions, nil
+}
+
+func (s *service) DeletePageRestriction(ctx context.Context, params *params.DeletePageRestriction) error {
+
+	var (
+		database    = s.Database.WithContext(ctx)
+		restriction *models.PageRestriction
+		page        *models.Page
+	)
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return err
+	}
+
+	err := database.
+		Where("`id` = ? AND `space_id` = ? AND `page_id` = ?", params.ID, params.SpaceID, params.PageID).
+		First(&restriction).Error
+	if err != nil {
+		return err
+	}
+
+	if err := database.Where("`id` = ?", restriction.PageID).First(&page).Error; err != nil {
+		return err
+	}
+
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(restriction).Error; err != nil {
+			return err
+		}
+		return s.renderSubtreeReferences(tx, page)
+	})
+}
+
+// renderSubtreeReferences render the contents including or linking to the pages of the subtree again,
+// the restricted pages are included and linked by the contents of the same subtree only
+func (s *service) renderSubtreeReferences(tx *gorm.DB, page *models.Page) error {
+
+	var ids []int64
+
+	err := tx.Model(&models.Page{}).
+		Where("`space_id` = ? AND `lft` >= ? AND `rgt` <= ?", page.SpaceID, page.Lft, page.Rgt).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	if err := s.renderIncluders(tx, ids...); err != nil {
+		return err
+	}
+
+	return s.renderLinkers(tx, ids...)
+}
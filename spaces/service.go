@@ -2,40 +2,163 @@ package spaces
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fox-gonic/fox/database"
 	"github.com/fox-gonic/fox/database/nestedset"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 
+	"github.com/miclle/space/accounts"
 	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/linkcheck"
 	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/pkg/storage"
 	"github.com/miclle/space/spaces/params"
 )
 
+var (
+	// ErrCopyPageIntoItself page can not be copied into its own subtree
+	ErrCopyPageIntoItself = errors.New("page can not be copied into itself")
+
+	// ErrMovePageIntoItself page can not be moved into its own subtree
+	ErrMovePageIntoItself = errors.New("page can not be moved into itself")
+
+	// ErrMoveHomepage the space homepage can not be moved
+	ErrMoveHomepage = errors.New("space homepage can not be moved")
+
+	// ErrSpaceIsArchived space is archived and read-only
+	ErrSpaceIsArchived = errors.New("space is archived")
+
+	// ErrDeleteSpaceTokenIsInvalid delete space confirmation token is invalid
+	ErrDeleteSpaceTokenIsInvalid = errors.New("delete space token is invalid")
+)
+
 // Service for spaces interface
 type Service interface {
 	CreateSpace(context.Context, *params.CreateSpace) (*models.Space, error)
 	DescribeSpaces(context.Context, *params.DescribeSpaces) (*database.Pagination[*models.Space], error)
 	DescribeSpace(context.Context, *params.DescribeSpace) (*models.Space, error)
 	UpdateSpace(context.Context, *params.UpdateSpace) (*models.Space, error)
+	ArchiveSpace(context.Context, *params.ArchiveSpace) (*models.Space, error)
+	RestoreSpace(context.Context, *params.RestoreSpace) (*models.Space, error)
+	CreateDeleteSpaceToken(context.Context, *params.CreateDeleteSpaceToken) (string, error)
+	DeleteSpace(context.Context, *params.DeleteSpace) error
+	UpdateSpaceSettings(context.Context, *params.UpdateSpaceSettings) (*models.Space, error)
+	DescribeSpaceTemplates(context.Context) ([]*models.SpaceTemplate, error)
+	RenameSpaceKey(context.Context, *params.RenameSpaceKey) (*models.Space, error)
+	TransferSpace(context.Context, *params.TransferSpace) (*models.Space, error)
+	DescribeSpaceStats(context.Context, *params.DescribeSpaceStats) (*models.SpaceStats, error)
 
 	CreatePage(context.Context, *params.CreatePage) (*models.Page, error)
 	DescribePages(context.Context, *params.DescribePages) ([]*models.Page, error)
+	DescribePageTree(context.Context, *params.DescribePageTree) (*models.PageCursor, error)
 	DescribePage(context.Context, *params.DescribePage) (*models.Page, error)
 	UpdatePage(context.Context, *params.UpdatePage) (*models.Page, error)
+	CopyPage(context.Context, *params.CopyPage) (*models.Page, error)
+	DescribeBacklinks(context.Context, *params.DescribeBacklinks) ([]*models.Page, error)
+	PreviewMarkdown(context.Context, *params.PreviewMarkdown) (string, error)
+
+	CreateRedirect(context.Context, *params.CreateRedirect) (*models.Redirect, error)
+	DescribeRedirects(context.Context, *params.DescribeRedirects) (*database.Pagination[*models.Redirect], error)
+	DescribeRedirect(context.Context, *params.DescribeRedirect) (*models.Redirect, error)
+	UpdateRedirect(context.Context, *params.UpdateRedirect) (*models.Redirect, error)
+	DeleteRedirect(context.Context, *params.DeleteRedirect) error
+
+	CreateVariable(context.Context, *params.CreateVariable) (*models.Variable, error)
+	DescribeVariables(context.Context, *params.DescribeVariables) ([]*models.Variable, error)
+	UpdateVariable(context.Context, *params.UpdateVariable) (*models.Variable, error)
+	DeleteVariable(context.Context, *params.DeleteVariable) error
+
+	CreateMember(context.Context, *params.CreateMember) (*models.Member, error)
+	DescribeMembers(context.Context, *params.DescribeMembers) (*database.Pagination[*models.Member], error)
+	DescribeMember(context.Context, *params.DescribeMember) (*models.Member, error)
+	UpdateMember(context.Context, *params.UpdateMember) (*models.Member, error)
+	DeleteMember(context.Context, *params.DeleteMember) error
+
+	CreatePageRestriction(context.Context, *params.CreatePageRestriction) (*models.PageRestriction, error)
+	DescribePageRestrictions(context.Context, *params.DescribePageRestrictions) ([]*models.PageRestriction, error)
+	DeletePageRestriction(context.Context, *params.DeletePageRestriction) error
+
+	CreateAttachment(context.Context, *params.CreateAttachment) (*models.Attachment, error)
+	DescribeAttachments(context.Context, *params.DescribeAttachments) ([]*models.Attachment, error)
+	OpenAttachment(context.Context, *params.OpenAttachment) (*models.Attachment, io.ReadCloser, error)
+	DeleteAttachment(context.Context, *params.DeleteAttachment) error
+
+	CreateLinkCheck(context.Context, *params.CreateLinkCheck) (*models.LinkCheck, error)
+	DescribeLinkChecks(context.Context, *params.DescribeLinkChecks) (*database.Pagination[*models.LinkCheck], error)
+	DescribeLinkCheck(context.Context, *params.DescribeLinkCheck) (*models.LinkCheck, error)
+
+	CheckPageTree(context.Context, *params.CheckPageTree) (*models.TreeReport, error)
 
 	Serach(context.Context, *params.Search) (*database.Pagination[*models.Page], error)
 }
 
+// Option spaces service option
+type Option func(*service)
+
+// WithStorage set the storage backend of the page attachments
+func WithStorage(storage storage.Storage) Option {
+	return func(s *service) {
+		s.Storage = storage
+	}
+}
+
+// WithLinkChecker set the checker of the external urls in the link checks
+func WithLinkChecker(checker linkcheck.Checker) Option {
+	return func(s *service) {
+		s.LinkChecker = checker
+	}
+}
+
+// DiagramRenderer the server side renderer of the diagram code fences, e.g. diagram.Cache
+type DiagramRenderer interface {
+	Render(kind, source string) (string, error) // the diagram is rendered if it is not cached
+	Cached(kind, source string) (string, error) // the cached diagram only, the renderers are not run
+}
+
+// WithDiagramRenderer set the server side renderer of the diagram code fences, the contents rendered again in
+// the transactions use the cached diagrams only, they do not wait for the renderers
+func WithDiagramRenderer(renderer DiagramRenderer) Option {
+	return func(s *service) {
+		s.DiagramRenderer = renderer
+	}
+}
+
+// WithSecret set the secret signing the delete space tokens, e.g. configuration.Secret
+func WithSecret(secret string) Option {
+	return func(s *service) {
+		s.Secret = []byte(secret)
+	}
+}
+
 // NewService return default implement spaces service
-func NewService(database *database.Database) (Service, error) {
+func NewService(database *database.Database, options ...Option) (Service, error) {
 
 	service := &service{
-		Database: database,
+		Database:   database,
+		linkChecks: make(chan struct{}, LinkCheckConcurrency),
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	// without a configured secret the tokens are only valid in this process
+	if len(service.Secret) == 0 {
+		service.Secret = make([]byte, 32)
+		if _, err := rand.Read(service.Secret); err != nil {
+			return nil, err
+		}
 	}
 
 	return service, nil
@@ -44,7 +167,13 @@ func NewService(database *database.Database) (Service, error) {
 var _ Service = &service{}
 
 type service struct {
-	Database *database.Database
+	Database        *database.Database
+	Storage         storage.Storage
+	LinkChecker     linkcheck.Checker
+	DiagramRenderer DiagramRenderer
+	Secret          []byte
+
+	linkChecks chan struct{} // the slots of the running link checks
 }
 
 func (s *service) CreateSpace(ctx context.Context, params *params.CreateSpace) (*models.Space, error) {
@@ -52,23 +181,72 @@ func (s *service) CreateSpace(ctx context.Context, params *params.CreateSpace) (
 	var (
 		database = s.Database.WithContext(ctx)
 		space    *models.Space
+		from     *models.Space
+		pages    []*models.Page
+		contents []*models.PageContent
+		err      error
 	)
 
-	if err := params.Status.IsValid(); err != nil {
+	switch {
+	case params.FromSpaceID > 0 && params.Template != "":
+		return nil, ErrSpaceTemplateIsInvalid
+
+	case params.FromSpaceID > 0:
+		from, pages, contents, err = spacePages(database, params.FromSpaceID)
+
+	case params.Template != "":
+		var manifest *templateManifest
+		if manifest, err = loadTemplate(params.Template); err == nil {
+			from, pages, contents, err = templatePages(manifest, params.Name)
+		}
+	}
+
+	if err != nil {
 		return nil, err
 	}
 
-	err := database.Transaction(func(tx *gorm.DB) error {
+	space = &models.Space{
+		Name:         params.Name,
+		Key:          params.Key,
+		Multilingual: params.Multilingual,
+		Lang:         params.Lang,
+		FallbackLang: params.FallbackLang,
+		Description:  params.Description,
+		Avatar:       params.Avatar,
+		Status:       params.Status,
+		Visibility:   params.Visibility,
+		CreatorID:    params.CreatorID,
+	}
+
+	// the settings not given are copied from the template
+	if from != nil {
+		space.Multilingual = space.Multilingual || from.Multilingual
+		space.Lang = lo.Ternary(space.Lang == "", from.Lang, space.Lang)
+		space.FallbackLang = lo.Ternary(space.FallbackLang == "", from.FallbackLang, space.FallbackLang)
+		space.Description = lo.Ternary(space.Description == "", from.Description, space.Description)
+		space.Avatar = lo.Ternary(space.Avatar == "", from.Avatar, space.Avatar)
+		space.Status = lo.Ternary(space.Status == "", from.Status, space.Status)
+		space.Visibility = lo.Ternary(space.Visibility == "", from.Visibility, space.Visibility)
+		space.Settings = from.Settings
+	}
 
-		space = &models.Space{
-			Name:         params.Name,
-			Key:          params.Key,
-			Lang:         params.Lang,
-			FallbackLang: params.FallbackLang,
-			Description:  params.Description,
-			Avatar:       params.Avatar,
-			Status:       params.Status,
-			CreatorID:    params.CreatorID,
+	if err := space.Status.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if space.Visibility == "" {
+		space.Visibility = models.SpaceVisibilityPublic
+	}
+
+	if err := space.Visibility.IsValid(); err != nil {
+		return nil, err
+	}
+
+	err = database.Transaction(func(tx *gorm.DB) error {
+
+		// the previous keys of the renamed spaces are reserved
+		if err := checkSpaceKey(tx, 0, space.Key); err != nil {
+			return err
 		}
 
 		err := tx.Create(space).Error
@@ -76,30 +254,58 @@ func (s *service) CreateSpace(ctx context.Context, params *params.CreateSpace) (
 			return err
 		}
 
-		page := &models.Page{
-			SpaceID: space.ID,
-		}
+		var page *models.Page
 
-		if err := nestedset.Create(tx, page, nil); err != nil {
-			return err
+		// the variables are copied before the pages are rendered
+		if from != nil && from.ID > 0 {
+			if err := copyVariables(tx, from, space); err != nil {
+				return err
+			}
 		}
 
-		content := &models.PageContent{
-			SpaceID:   space.ID,
-			CreatorID: params.CreatorID,
-			PageID:    page.ID,
-			Lang:      space.Lang,
-			// Version:   space.Version, // TODO(m) space default version
-			Status:     models.PageStatusPublished,
-			Title:      space.Name,
-			ShortTitle: space.Name,
-			Body:       space.Description,
-			HTML:       space.Description,
+		if len(pages) > 0 {
+			copies, err := s.copyPages(tx, from, space, nil, pages, contents, params.CreatorID)
+			if err != nil {
+				return err
+			}
+
+			// the restrictions of the existing space pages
+			if from.ID > 0 {
+				ids := lo.Map(pages, func(page *models.Page, _ int) int64 { return page.ID })
+				if err := copyRestrictions(tx, space, ids, copies); err != nil {
+					return err
+				}
+			}
+
+			page = copies[from.HomepageID]
 		}
 
-		err = tx.Create(content).Error
-		if err != nil {
-			return err
+		if page == nil {
+			page = &models.Page{
+				SpaceID: space.ID,
+			}
+
+			if err := nestedset.Create(tx, page, nil); err != nil {
+				return err
+			}
+
+			content := &models.PageContent{
+				SpaceID:   space.ID,
+				CreatorID: params.CreatorID,
+				PageID:    page.ID,
+				Lang:      space.Lang,
+				// Version:   space.Version, // TODO(m) space default version
+				Status:     models.PageStatusPublished,
+				Title:      space.Name,
+				ShortTitle: space.Name,
+				Body:       space.Description,
+				HTML:       space.Description,
+			}
+
+			err = tx.Create(content).Error
+			if err != nil {
+				return err
+			}
 		}
 
 		err = tx.Model(space).Update("homepage_id", page.ID).Error
@@ -107,6 +313,18 @@ func (s *service) CreateSpace(ctx context.Context, params *params.CreateSpace) (
 			return err
 		}
 
+		// the creator is the space owner
+		if params.CreatorID > 0 {
+			err = tx.Create(&models.Member{
+				SpaceID:   space.ID,
+				AccountID: params.CreatorID,
+				Role:      models.MemberRoleOwner,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
 		space.Homepage = page
 
 		return nil
@@ -131,6 +349,22 @@ func (s *service) DescribeSpaces(ctx context.Context, params *params.DescribeSpa
 		database = database.Where("`spaces`.`name` LIKE ? OR `spaces`.`key` LIKE ?", like, like)
 	}
 
+	if params.AccountID > 0 {
+		database = database.Where(memberSpaces(s.Database.DB, params.AccountID))
+	}
+
+	if !params.Viewer.IsUnrestricted() {
+		database = database.Where(visibleSpaces(s.Database.DB, params.Viewer))
+	}
+
+	if params.Archived != nil {
+		if *params.Archived {
+			database = database.Where("`spaces`.`archived_at` > 0")
+		} else {
+			database = database.Where("`spaces`.`archived_at` = 0")
+		}
+	}
+
 	if err := database.Model(&pagination.Items).Count(&pagination.Total).Error; err != nil {
 		return nil, err
 	}
@@ -166,10 +400,26 @@ func (s *service) DescribeSpace(ctx context.Context, params *params.DescribeSpac
 
 	// find space
 	err := database.Where("`key` = ?", params.Key).First(&space).Error
+
+	// the previous key of a renamed space
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var moved error
+		if space, moved = movedSpace(database, params.Key); space != nil {
+			if err := s.checkSpaceVisible(ctx, space, params.Viewer); err != nil {
+				return nil, err
+			}
+		}
+		return nil, moved
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.checkSpaceVisible(ctx, space, params.Viewer); err != nil {
+		return nil, err
+	}
+
 	lang := params.Lang
 	if lang == "" {
 		lang = space.Lang
@@ -209,6 +459,10 @@ func (s *service) UpdateSpace(ctx context.Context, params *params.UpdateSpace) (
 		return nil, err
 	}
 
+	if space.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
 	if params.Name != nil {
 		space.Name = *params.Name
 	}
@@ -233,49 +487,265 @@ func (s *service) UpdateSpace(ctx context.Context, params *params.UpdateSpace) (
 	if params.Status.IsValid() == nil {
 		space.Status = params.Status
 	}
+	if params.Visibility.IsValid() == nil {
+		space.Visibility = params.Visibility
+	}
 
 	err = database.Save(space).Error
 
 	return space, err
 }
 
-func (s *service) CreatePage(ctx context.Context, params *params.CreatePage) (*models.Page, error) {
+func (s *service) UpdateSpaceSettings(ctx context.Context, params *params.UpdateSpaceSettings) (*models.Space, error) {
 
 	var (
 		database = s.Database.WithContext(ctx)
 		space    *models.Space
-		parent   *models.Page
-		page     *models.Page
-		content  *models.PageContent
 	)
 
-	if err := params.Status.IsValid(); err != nil {
+	err := database.Where("`key` = ?", params.Key).First(&space).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if space.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
+	settings := space.Settings
+
+	if params.NavLinks != nil {
+		settings.NavLinks = *params.NavLinks
+	}
+	if params.Footer != nil {
+		settings.Footer = *params.Footer
+	}
+	if params.AccentColor != nil {
+		settings.AccentColor = *params.AccentColor
+	}
+	if params.Logo != nil {
+		settings.Logo = *params.Logo
+	}
+	if params.EditURL != nil {
+		settings.EditURL = *params.EditURL
+	}
+
+	if err := settings.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if settings.FooterHTML, err = markdown.Parse(settings.Footer); err != nil {
+		return nil, err
+	}
+
+	space.Settings = settings
+
+	err = database.Model(space).Select("settings").Updates(space).Error
+
+	return space, err
+}
+
+func (s *service) ArchiveSpace(ctx context.Context, params *params.ArchiveSpace) (*models.Space, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	err := database.Where("`key` = ?", params.Key).First(&space).Error
+	if err != nil {
 		return nil, err
 	}
 
-	html, err := markdown.Parse(params.Body)
+	if space.IsArchived() {
+		return space, nil
+	}
+
+	err = database.Model(space).Update("archived_at", time.Now().Unix()).Error
+
+	return space, err
+}
+
+func (s *service) RestoreSpace(ctx context.Context, params *params.RestoreSpace) (*models.Space, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	err := database.Where("`key` = ?", params.Key).First(&space).Error
 	if err != nil {
 		return nil, err
 	}
 
-	err = database.Where("`id` = ?", params.SpaceID).First(&space).Error
+	err = database.Model(space).Update("archived_at", 0).Error
+
+	return space, err
+}
+
+func (s *service) CreateDeleteSpaceToken(ctx context.Context, params *params.CreateDeleteSpaceToken) (string, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	err := database.Where("`key` = ?", params.Key).First(&space).Error
+	if err != nil {
+		return "", err
+	}
+
+	// the token is bound to the current space revision
+	t := jwt.NewWithClaims(accounts.SigningMethod, jwt.RegisteredClaims{
+		Subject:   space.Key,
+		ID:        fmt.Sprintf("%d-%d", space.ID, space.UpdatedAt),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(accounts.TokenExpires)),
+	})
+
+	return t.SignedString(s.Secret)
+}
+
+func (s *service) DeleteSpace(ctx context.Context, params *params.DeleteSpace) error {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+	)
+
+	err := database.Where("`key` = ?", params.Key).First(&space).Error
+	if err != nil {
+		return err
+	}
+
+	claims := jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(params.Token, &claims, func(t *jwt.Token) (interface{}, error) {
+		return s.Secret, nil
+	}, jwt.WithValidMethods([]string{accounts.SigningMethod.Alg()}))
+	if err != nil {
+		return ErrDeleteSpaceTokenIsInvalid
+	}
+
+	if claims.Subject != space.Key || claims.ID != fmt.Sprintf("%d-%d", space.ID, space.UpdatedAt) {
+		return ErrDeleteSpaceTokenIsInvalid
+	}
+
+	// soft delete, the space name and key are renamed in the delete callback
+	return database.Delete(space).Error
+}
+
+// checkSpaceWritable return ErrSpaceIsArchived if the space is archived
+func checkSpaceWritable(tx *gorm.DB, spaceID int64) error {
+	var space *models.Space
+	if err := tx.Select("id", "archived_at").Where("`id` = ?", spaceID).First(&space).Error; err != nil {
+		return err
+	}
+	if space.IsArchived() {
+		return ErrSpaceIsArchived
+	}
+	return nil
+}
+
+// PreviewMarkdown return the html of the page markdown, it is rendered in the target of the saved content
+func (s *service) PreviewMarkdown(ctx context.Context, params *params.PreviewMarkdown) (string, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+		target   = &renderTarget{
+			SpaceID: params.SpaceID,
+			Lang:    params.Lang,
+			Version: params.Version,
+		}
+	)
+
+	if err := database.Where("`id` = ?", params.SpaceID).First(&space).Error; err != nil {
+		return "", err
+	}
+
+	if target.Lang == "" {
+		target.Lang = space.Lang
+	}
+
+	if params.PageID > 0 {
+		var page *models.Page
+		if err := database.Where("`id` = ? AND `space_id` = ?", params.PageID, space.ID).First(&page).Error; err != nil {
+			return "", err
+		}
+		target.PageID = page.ID
+	} else if params.ParentID > 0 {
+		var parent *models.Page
+		if err := database.Where("`id` = ? AND `space_id` = ?", params.ParentID, space.ID).First(&parent).Error; err != nil {
+			return "", err
+		}
+		target.ParentID = parent.ID
+	}
+
+	result, err := s.parseMarkdown(database, target, params.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return result.HTML, nil
+}
+
+func (s *service) CreatePage(ctx context.Context, params *params.CreatePage) (*models.Page, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+		parent   *models.Page
+		page     *models.Page
+		content  *models.PageContent
+	)
+
+	err := database.Where("`id` = ?", params.SpaceID).First(&space).Error
 	if err != nil {
 		return nil, err
 	}
 
+	if space.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
 	if params.ParentID > 0 {
 		if err := database.Where("`id` = ?", params.ParentID).Find(&parent).Error; err != nil {
 			return nil, err
 		}
 	}
 
-	err = database.Transaction(func(tx *gorm.DB) error {
+	result, err := s.parseMarkdown(database, &renderTarget{
+		SpaceID:  space.ID,
+		ParentID: params.ParentID,
+		Lang:     space.Lang,
+		Version:  params.Version,
+	}, params.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	applyFrontMatter(params, result.FrontMatter)
+
+	if err := params.Status.IsValid(); err != nil {
+		return nil, err
+	}
+
+	// the concurrent creates may take the same path, the transaction is run again with another slug
+	err = retryPathConflict(database, func(tx *gorm.DB) error {
 
 		page = &models.Page{
 			SpaceID:  space.ID,
 			ParentID: sql.NullInt64{Valid: true, Int64: params.ParentID},
 		}
 
+		slug := params.Slug
+		if slug == "" {
+			slug = params.Title
+		}
+
+		if err := setSlug(tx, page, slug); err != nil {
+			return err
+		}
+
 		if parent != nil {
 			err = nestedset.Create(tx, page, parent)
 		} else {
@@ -287,16 +757,23 @@ func (s *service) CreatePage(ctx context.Context, params *params.CreatePage) (*m
 		}
 
 		content = &models.PageContent{
-			SpaceID:    space.ID,
-			CreatorID:  params.CreatorID,
-			PageID:     page.ID,
-			Lang:       space.Lang,
-			Version:    params.Version,
-			Status:     params.Status,
-			Title:      params.Title,
-			ShortTitle: params.ShortTitle,
-			Body:       params.Body,
-			HTML:       html,
+			SpaceID:     space.ID,
+			CreatorID:   params.CreatorID,
+			PageID:      page.ID,
+			Lang:        space.Lang,
+			Version:     params.Version,
+			Status:      params.Status,
+			Title:       params.Title,
+			ShortTitle:  params.ShortTitle,
+			Body:        params.Body,
+			HTML:        result.HTML,
+			TOC:         result.TOC,
+			Tags:        params.Tags,
+			Description: params.Description,
+		}
+
+		if result.FrontMatter != nil {
+			content.Meta = result.FrontMatter.Extra
 		}
 
 		if len(content.ShortTitle) == 0 {
@@ -311,6 +788,21 @@ func (s *service) CreatePage(ctx context.Context, params *params.CreatePage) (*m
 			return err
 		}
 
+		if err := saveLinks(tx, content, result.Links); err != nil {
+			return err
+		}
+
+		if err := saveIncludes(tx, content, result.Includes); err != nil {
+			return err
+		}
+
+		// the missing links may match the new page
+		if content.Status == models.PageStatusPublished {
+			if err := s.renderMissingLinks(tx, content.Title, content.ShortTitle); err != nil {
+				return err
+			}
+		}
+
 		// TODO(m) add history version record
 		return nil
 	})
@@ -357,7 +849,14 @@ func (s *service) DescribePages(ctx context.Context, params *params.DescribePage
 		db = db.Where("`space_pages`.`parent_id` = ?", *params.ParentID)
 	}
 
-	err := db.Where("`space_pages`.`space_id` = ?", space.ID).Order("`lft` ASC").Find(&pages).Error
+	denied, err := s.deniedPages(ctx, space.ID, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	db = excludeSubtrees(db, denied)
+
+	err = db.Where("`space_pages`.`space_id` = ?", space.ID).Order("`lft` ASC").Find(&pages).Error
 	if err != nil {
 		return nil, err
 	}
@@ -396,17 +895,39 @@ func (s *service) DescribePage(ctx context.Context, params *params.DescribePage)
 		database = database.Joins("FallbackContent", database.Where(&models.PageContent{Lang: space.FallbackLang}))
 	}
 
-	err := database.
-		InstanceSet("query", &models.PageQuery{Lang: lang}).
-		Where("`space_pages`.`space_id` = ? AND `page_id` = ?", space.ID, params.PageID).
-		Find(&page).Error
+	database = database.InstanceSet("query", &models.PageQuery{Lang: lang})
+
+	if params.PageID > 0 {
+		database = database.Where("`space_pages`.`space_id` = ? AND `space_pages`.`id` = ?", space.ID, params.PageID)
+	} else {
+		database = database.Where("`space_pages`.`space_id` = ? AND `space_pages`.`path` = ?", space.ID, params.Path)
+	}
 
+	err := database.First(&page).Error
 	if err != nil {
 		return nil, err
 	}
 
+	denied, err := s.deniedPages(ctx, space.ID, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if inSubtrees(page, denied) {
+		return nil, ErrPageAccessDenied
+	}
+
 	page.Space = space
 
+	if params.Navigation {
+		if page.Previous, err = s.adjacentPage(ctx, space, lang, page, denied, false); err != nil {
+			return nil, err
+		}
+		if page.Next, err = s.adjacentPage(ctx, space, lang, page, denied, true); err != nil {
+			return nil, err
+		}
+	}
+
 	return page, nil
 }
 
@@ -422,8 +943,8 @@ func (s *service) UpdatePage(ctx context.Context, params *params.UpdatePage) (*m
 		return nil, err
 	}
 
-	if err := params.Status.IsValid(); err != nil {
-		return nil, err
+	if page.Space.IsArchived() {
+		return nil, ErrSpaceIsArchived
 	}
 
 	db := database.Where("`page_id` = ?", page.ID)
@@ -440,6 +961,28 @@ func (s *service) UpdatePage(ctx context.Context, params *params.UpdatePage) (*m
 		return nil, err
 	}
 
+	// the front matter fills the params before the changes are applied
+	var result *rendered
+
+	if params.Body != nil {
+		if result, err = s.parseMarkdown(database, contentTarget(page.Content), *params.Body); err != nil {
+			return nil, err
+		}
+		applyUpdateFrontMatter(params, result.FrontMatter)
+	}
+
+	if params.Status != nil {
+		if err := params.Status.IsValid(); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		title      = page.Content.Title
+		shortTitle = page.Content.ShortTitle
+		status     = page.Content.Status
+	)
+
 	if params.Status != nil {
 		page.Content.Status = *params.Status
 	}
@@ -449,20 +992,297 @@ func (s *service) UpdatePage(ctx context.Context, params *params.UpdatePage) (*m
 	if params.ShortTitle != nil {
 		page.Content.ShortTitle = *params.ShortTitle
 	}
+	if params.Tags != nil {
+		page.Content.Tags = *params.Tags
+	}
+	if params.Description != nil {
+		page.Content.Description = *params.Description
+	}
 	if params.Body != nil {
-		html, err := markdown.Parse(*params.Body)
-		if err != nil {
+		page.Content.Body = *params.Body
+		page.Content.HTML = result.HTML
+		page.Content.TOC = result.TOC
+		page.Content.Meta = nil
+		if result.FrontMatter != nil {
+			page.Content.Meta = result.FrontMatter.Extra
+		}
+	}
+
+	// the space homepage is served at the space root and has no slug, the slug is only changed by the edit
+	// of the slug, an empty slug is derived from the title, or by the new title of a page without slug
+	slug := page.Slug
+	if page.ID != page.Space.HomepageID {
+		switch {
+		case params.Slug != nil && strings.TrimSpace(*params.Slug) != "":
+			slug = slugify(*params.Slug)
+		case params.Slug != nil, slug == "" && params.Title != nil && *params.Title != title:
+			slug = slugify(page.Content.Title)
+		}
+	}
+
+	// the new parent in the same space, out of the page subtree
+	var parent *models.Page
+
+	if params.ParentID != nil && *params.ParentID != page.ParentID.Int64 {
+		if page.ID == page.Space.HomepageID {
+			return nil, ErrMoveHomepage
+		}
+
+		if err := database.Where("`id` = ? AND `space_id` = ?", *params.ParentID, page.SpaceID).First(&parent).Error; err != nil {
 			return nil, err
 		}
-		page.Content.Body = *params.Body
-		page.Content.HTML = html
+
+		if parent.Lft >= page.Lft && parent.Rgt <= page.Rgt {
+			return nil, ErrMovePageIntoItself
+		}
 	}
 
-	err = database.Save(page.Content).Error
+	// the links to the page follow the new title and status
+	retitled := page.Content.Title != title || page.Content.ShortTitle != shortTitle || page.Content.Status != status
+
+	origin := *page
+
+	err = retryPathConflict(database, func(tx *gorm.DB) error {
+
+		// the tree position, slug and path of the rolled back attempt
+		*page = origin
+
+		if err := tx.Save(page.Content).Error; err != nil {
+			return err
+		}
+
+		if params.Body != nil {
+			if err := saveLinks(tx, page.Content, result.Links); err != nil {
+				return err
+			}
+
+			if err := saveIncludes(tx, page.Content, result.Includes); err != nil {
+				return err
+			}
+
+			if err := s.renderIncluders(tx, page.ID); err != nil {
+				return err
+			}
+		}
+
+		// the missing links may match the new title
+		if retitled && page.Content.Status == models.PageStatusPublished {
+			if err := s.renderMissingLinks(tx, page.Content.Title, page.Content.ShortTitle); err != nil {
+				return err
+			}
+		}
+
+		if slug == page.Slug && parent == nil {
+			if retitled {
+				return s.renderLinkers(tx, page.ID)
+			}
+			return nil
+		}
+
+		previous := page.Path
+
+		if parent != nil {
+			if err := nestedset.MoveTo(tx, page, parent, nestedset.MoveDirectionInner); err != nil {
+				return err
+			}
+
+			// the nested set values are changed by the move
+			err := tx.Select("id", "parent_id", "lft", "rgt", "depth").Where("`id` = ?", page.ID).First(page).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := setSlug(tx, page, slug); err != nil {
+			return err
+		}
+
+		err := tx.Model(page).UpdateColumns(map[string]interface{}{"slug": page.Slug, "path": page.Path}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := recordRedirect(tx, page.SpaceID, previous, page.ID); err != nil {
+			return err
+		}
+
+		if err := updatePaths(tx, page); err != nil {
+			return err
+		}
+
+		// the links to the pages of the subtree follow the new paths
+		var ids []int64
+
+		err = tx.Model(&models.Page{}).
+			Where("`space_id` = ? AND `lft` >= ? AND `rgt` <= ?", page.SpaceID, page.Lft, page.Rgt).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+
+		return s.renderLinkers(tx, ids...)
+	})
 
 	return page, err
 }
 
+func (s *service) CopyPage(ctx context.Context, params *params.CopyPage) (*models.Page, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		source   *models.Page
+		target   *models.Space
+		parent   *models.Page
+		pages    []*models.Page
+		contents []*models.PageContent
+	)
+
+	err := database.Where("`id` = ? AND `space_id` = ?", params.PageID, params.SpaceID).Preload("Space").First(&source).Error
+	if err != nil {
+		return nil, err
+	}
+
+	targetSpaceID := params.TargetSpaceID
+	if targetSpaceID == 0 {
+		targetSpaceID = source.SpaceID
+	}
+
+	err = database.Where("`id` = ?", targetSpaceID).First(&target).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if target.IsArchived() {
+		return nil, ErrSpaceIsArchived
+	}
+
+	if params.TargetParentID > 0 {
+		err = database.Where("`id` = ? AND `space_id` = ?", params.TargetParentID, target.ID).First(&parent).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.SpaceID == source.SpaceID && parent.Lft >= source.Lft && parent.Rgt <= source.Rgt {
+			return nil, ErrCopyPageIntoItself
+		}
+	}
+
+	denied, err := s.deniedPages(ctx, source.SpaceID, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if inSubtrees(source, denied) {
+		return nil, ErrPageAccessDenied
+	}
+
+	// find the subtree readable by the viewer in nested set order, parents always come before their children
+	err = excludeSubtrees(database, denied).
+		Where("`space_id` = ? AND `lft` >= ? AND `rgt` <= ?", source.SpaceID, source.Lft, source.Rgt).
+		Order("`lft` ASC").
+		Find(&pages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(pages))
+	for _, page := range pages {
+		ids = append(ids, page.ID)
+	}
+
+	// all languages and versions
+	err = database.Where("`page_id` IN ?", ids).Order("`id` ASC").Find(&contents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var root *models.Page
+
+	err = retryPathConflict(database, func(tx *gorm.DB) error {
+
+		copies, err := s.copyPages(tx, source.Space, target, parent, pages, contents, params.CreatorID)
+		if err != nil {
+			return err
+		}
+
+		if err := copyRestrictions(tx, target, ids, copies); err != nil {
+			return err
+		}
+
+		root = copies[source.ID]
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	root.Space = target
+
+	return root, nil
+}
+
+// pageLinkRegexp match website page links, e.g. `/en-US/docs/website/12` or `/docs/website/getting-started/install`
+var pageLinkRegexp = regexp.MustCompile(`(/(?:[A-Za-z]{2,3}(?:-[A-Za-z0-9]+)*/)?docs/)([^/\s()"'#?]+)/([^\s()"'#?]+)`)
+
+// pageSchemeRegexp match page id links, e.g. `[[page:12]]` or `[install](page:12)`
+var pageSchemeRegexp = regexp.MustCompile(`page:[0-9]+\b`)
+
+// includePageRegexp match the page ids of the include directives, e.g. `{{< include page=12 >}}`
+var includePageRegexp = regexp.MustCompile(`(\{\{<\s*include\s[^\n]*?\bpage=)("?)([0-9]+)`)
+
+// rewritePageLinks rewrite links and includes point to the copied pages
+func rewritePageLinks(body string, from, to *models.Space, sources []*models.Page, copies map[int64]*models.Page) string {
+
+	paths := make(map[string]int64, len(sources))
+	for _, page := range sources {
+		if page.Path != "" {
+			paths[page.Path] = page.ID
+		}
+	}
+
+	body = includePageRegexp.ReplaceAllStringFunc(body, func(directive string) string {
+		matches := includePageRegexp.FindStringSubmatch(directive)
+		id, _ := strconv.ParseInt(matches[3], 10, 64)
+		if page, exists := copies[id]; exists {
+			return fmt.Sprintf("%s%s%d", matches[1], matches[2], page.ID)
+		}
+		return directive
+	})
+
+	body = pageSchemeRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		id, _ := strconv.ParseInt(strings.TrimPrefix(link, markdown.PageScheme), 10, 64)
+		if page, exists := copies[id]; exists {
+			return fmt.Sprintf("%s%d", markdown.PageScheme, page.ID)
+		}
+		return link
+	})
+
+	return pageLinkRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		matches := pageLinkRegexp.FindStringSubmatch(link)
+		if matches[2] != from.Key {
+			return link
+		}
+
+		id, err := strconv.ParseInt(matches[3], 10, 64)
+		if err != nil {
+			id = paths[strings.TrimSuffix(matches[3], "/")]
+		}
+
+		page, exists := copies[id]
+		if !exists {
+			return link
+		}
+
+		if err == nil {
+			return fmt.Sprintf("%s%s/%d", matches[1], to.Key, page.ID)
+		}
+
+		return fmt.Sprintf("%s%s/%s", matches[1], to.Key, page.Pathname())
+	})
+}
+
 func (s *service) Serach(ctx context.Context, params *params.Search) (*database.Pagination[*models.Page], error) {
 
 	var (
@@ -478,6 +1298,24 @@ func (s *service) Serach(ctx context.Context, params *params.Search) (*database.
 
 	like := fmt.Sprintf("%%%s%%", q)
 	database = database.Where("`lang` = ? AND (`title` LIKE ? OR `body` LIKE ?)", params.Lang, like, like)
+
+	// the pages of existing spaces visible to the viewer
+	spaces := s.Database.Model(&models.Space{}).Select("id")
+	if !params.Viewer.IsUnrestricted() {
+		spaces = spaces.Where(visibleSpaces(s.Database.DB, params.Viewer))
+	}
+	database = database.Where("`space_id` IN (?)", spaces)
+
+	// and not restricted from the viewer
+	denied, err := s.deniedPages(ctx, 0, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(denied) > 0 {
+		database = database.Where("`page_id` NOT IN (?)", s.Database.Model(&models.Page{}).Select("id").Where(subtrees(denied)))
+	}
+
 	if err := database.Model(&contents).Count(&pagination.Total).Error; err != nil {
 		return nil, err
 	}
@@ -1,20 +1,33 @@
 package spaces
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fox-gonic/fox/database"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 
 	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/linkcheck"
+	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/pkg/storage"
 	"github.com/miclle/space/spaces/params"
 )
 
@@ -38,7 +51,17 @@ func TestMain(m *testing.M) {
 		log.Fatalf("migrate models failed, err: %+v", err)
 	}
 
-	spacer, err = NewService(database)
+	root, err := os.MkdirTemp("", "space_test_attachments_")
+	if err != nil {
+		log.Fatalf("create attachments root failed, err: %+v", err)
+	}
+
+	store, err := storage.NewLocal(&storage.LocalConfig{Root: root})
+	if err != nil {
+		log.Fatalf("storage init failed, err: %+v", err)
+	}
+
+	spacer, err = NewService(database, WithStorage(store))
 	if err != nil {
 		log.Fatalf("new space service failed, err: %+v", err)
 	}
@@ -49,6 +72,9 @@ func TestMain(m *testing.M) {
 		if err := os.Remove(db); err != nil {
 			log.Printf("remove testing db: %s failed, err: %s", db, err.Error())
 		}
+		if err := os.RemoveAll(root); err != nil {
+			log.Printf("remove testing attachments: %s failed, err: %s", root, err.Error())
+		}
 	} else {
 		log.Printf("The test failed and you need to manually delete the test database: %s\n", db)
 	}
@@ -161,3 +187,2000 @@ func TestUpdatePage(t *testing.T) {
 func TestSerach(t *testing.T) {
 	// TODO(m)
 }
+
+func TestCopyPage(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "website"})
+	assert.Nil(err)
+
+	guide, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: space.HomepageID,
+		Status:   models.PageStatusPublished,
+		Title:    "Integration guide",
+		Body:     "# Integration guide",
+	})
+	assert.Nil(err)
+
+	install, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: guide.ID,
+		Status:   models.PageStatusPublished,
+		Title:    "Installation",
+		Body:     fmt.Sprintf("Back to [guide](/en-US/docs/website/%d)", guide.ID),
+	})
+	assert.Nil(err)
+	assert.NotNil(install)
+
+	page, err := spacer.CopyPage(ctx, &params.CopyPage{
+		SpaceID:        space.ID,
+		PageID:         guide.ID,
+		TargetParentID: install.ID,
+	})
+	assert.Equal(ErrCopyPageIntoItself, err)
+	assert.Nil(page)
+
+	sdk, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "SDK",
+		Key:    "sdk",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	page, err = spacer.CopyPage(ctx, &params.CopyPage{
+		SpaceID:        space.ID,
+		PageID:         guide.ID,
+		TargetSpaceID:  sdk.ID,
+		TargetParentID: sdk.HomepageID,
+	})
+	assert.Nil(err)
+	assert.NotNil(page)
+	assert.NotEqual(guide.ID, page.ID)
+	assert.Equal(sdk.ID, page.SpaceID)
+	assert.Equal("Integration guide", page.Content.Title)
+
+	pages, err := spacer.DescribePages(ctx, &params.DescribePages{
+		SpaceID:  sdk.ID,
+		ParentID: &page.ID,
+	})
+	assert.Nil(err)
+	assert.Len(pages, 1)
+
+	copied, err := spacer.DescribePage(ctx, &params.DescribePage{
+		SpaceID: sdk.ID,
+		PageID:  pages[0].ID,
+	})
+	assert.Nil(err)
+	assert.Equal("Installation", copied.Content.Title)
+	assert.Equal(fmt.Sprintf("Back to [guide](/en-US/docs/sdk/%d)", page.ID), copied.Content.Body)
+
+	// the restricted subtrees are not copied for the viewers who can not read them
+	notes, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: guide.ID,
+		Status:   models.PageStatusPublished,
+		Title:    "Internal notes",
+	})
+	assert.Nil(err)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: notes.ID, AccountID: 900})
+	assert.Nil(err)
+
+	_, err = spacer.CopyPage(ctx, &params.CopyPage{
+		SpaceID:       space.ID,
+		PageID:        notes.ID,
+		TargetSpaceID: sdk.ID,
+		Viewer:        &params.Viewer{AccountID: 901},
+	})
+	assert.ErrorIs(err, ErrPageAccessDenied)
+
+	for viewer, titles := range map[int64][]string{901: {"Installation"}, 900: {"Installation", "Internal notes"}} {
+		page, err = spacer.CopyPage(ctx, &params.CopyPage{
+			SpaceID:        space.ID,
+			PageID:         guide.ID,
+			TargetSpaceID:  sdk.ID,
+			TargetParentID: sdk.HomepageID,
+			Viewer:         &params.Viewer{AccountID: viewer},
+		})
+		assert.Nil(err)
+
+		pages, err = spacer.DescribePages(ctx, &params.DescribePages{
+			SpaceID:  sdk.ID,
+			ParentID: &page.ID,
+			Viewer:   &params.Viewer{Unrestricted: true},
+		})
+		assert.Nil(err)
+		assert.ElementsMatch(titles, lo.Map(pages, func(page *models.Page, _ int) string { return page.Content.Title }))
+	}
+}
+
+func TestPageSlug(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "website"})
+	assert.Nil(err)
+
+	started, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: space.HomepageID,
+		Status:   models.PageStatusPublished,
+		Title:    "Getting Started!",
+	})
+	assert.Nil(err)
+	assert.Equal("getting-started", started.Slug)
+	assert.Equal("getting-started", started.Path)
+
+	duplicate, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: space.HomepageID,
+		Status:   models.PageStatusPublished,
+		Title:    "Getting started",
+	})
+	assert.Nil(err)
+	assert.Equal("getting-started-2", duplicate.Path)
+
+	release, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: started.ID,
+		Status:   models.PageStatusPublished,
+		Title:    "2023",
+	})
+	assert.Nil(err)
+	assert.Equal("getting-started/page-2023", release.Path)
+
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{
+		ID:   started.ID,
+		Slug: lo.ToPtr("Quick Start"),
+	})
+	assert.Nil(err)
+
+	page, err := spacer.DescribePage(ctx, &params.DescribePage{
+		SpaceID: space.ID,
+		Path:    "quick-start/page-2023",
+	})
+	assert.Nil(err)
+	assert.Equal(release.ID, page.ID)
+	assert.Equal("quick-start/page-2023", page.Pathname())
+
+	_, err = spacer.DescribePage(ctx, &params.DescribePage{
+		SpaceID: space.ID,
+		Path:    "getting-started/page-2023",
+	})
+	assert.Equal(gorm.ErrRecordNotFound, err)
+
+	// the new title keeps the slug, the empty slug is derived from the title
+	page, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: started.ID, Title: lo.ToPtr("Introduction")})
+	assert.Nil(err)
+	assert.Equal("quick-start", page.Slug)
+
+	page, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: started.ID, Slug: lo.ToPtr(" ")})
+	assert.Nil(err)
+	assert.Equal("introduction", page.Path)
+
+	// the paths are unique in the space
+	err = spacer.(*service).Database.Model(&models.Page{}).Where("`id` = ?", duplicate.ID).UpdateColumn("path", "introduction").Error
+	assert.True(isUniqueViolation(err))
+}
+
+func TestRedirects(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "website"})
+	assert.Nil(err)
+
+	faq, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: space.HomepageID,
+		Status:   models.PageStatusPublished,
+		Title:    "FAQ",
+	})
+	assert.Nil(err)
+
+	billing, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: faq.ID,
+		Status:   models.PageStatusPublished,
+		Title:    "Billing",
+	})
+	assert.Nil(err)
+	assert.Equal("faq/billing", billing.Path)
+
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{
+		ID:   faq.ID,
+		Slug: lo.ToPtr("questions"),
+	})
+	assert.Nil(err)
+
+	redirect, err := spacer.DescribeRedirect(ctx, &params.DescribeRedirect{
+		SpaceID: space.ID,
+		Path:    "/faq/billing",
+	})
+	assert.Nil(err)
+	assert.Equal(billing.ID, redirect.PageID)
+	assert.Equal("questions/billing", redirect.Page.Path)
+	assert.Equal("website", redirect.Page.Space.Key)
+
+	pagination, err := spacer.DescribeRedirects(ctx, &params.DescribeRedirects{
+		SpaceID: space.ID,
+		Q:       "faq",
+	})
+	assert.Nil(err)
+	assert.EqualValues(2, pagination.Total)
+
+	_, err = spacer.CreateRedirect(ctx, &params.CreateRedirect{
+		SpaceID: space.ID,
+		Path:    "/",
+		PageID:  faq.ID,
+	})
+	assert.Equal(ErrRedirectPathIsInvalid, err)
+
+	redirect, err = spacer.CreateRedirect(ctx, &params.CreateRedirect{
+		SpaceID: space.ID,
+		Path:    "/help/",
+		PageID:  faq.ID,
+	})
+	assert.Nil(err)
+	assert.Equal("help", redirect.Path)
+
+	err = spacer.DeleteRedirect(ctx, &params.DeleteRedirect{
+		SpaceID: space.ID,
+		ID:      redirect.ID,
+	})
+	assert.Nil(err)
+
+	_, err = spacer.DescribeRedirect(ctx, &params.DescribeRedirect{
+		SpaceID: space.ID,
+		Path:    "help",
+	})
+	assert.Equal(gorm.ErrRecordNotFound, err)
+
+	// the moved page keeps the previous path
+	support, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: space.HomepageID,
+		Status:   models.PageStatusPublished,
+		Title:    "Support",
+	})
+	assert.Nil(err)
+
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: faq.ID, ParentID: &billing.ID})
+	assert.Equal(ErrMovePageIntoItself, err)
+
+	moved, err := spacer.UpdatePage(ctx, &params.UpdatePage{ID: billing.ID, ParentID: &support.ID})
+	assert.Nil(err)
+	assert.Equal("support/billing", moved.Path)
+	assert.Equal(support.ID, moved.ParentID.Int64)
+
+	redirect, err = spacer.DescribeRedirect(ctx, &params.DescribeRedirect{
+		SpaceID: space.ID,
+		Path:    "questions/billing",
+	})
+	assert.Nil(err)
+	assert.Equal("support/billing", redirect.Page.Path)
+
+	// the pages of other spaces are not the targets
+	other, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:       "Private notes",
+		Key:        "private-notes",
+		Status:     models.SpaceStatusOnline,
+		Visibility: models.SpaceVisibilityPrivate,
+	})
+	assert.Nil(err)
+
+	_, err = spacer.CreateRedirect(ctx, &params.CreateRedirect{
+		SpaceID: space.ID,
+		Path:    "notes",
+		PageID:  other.HomepageID,
+	})
+	assert.Equal(gorm.ErrRecordNotFound, err)
+
+	_, err = spacer.UpdateRedirect(ctx, &params.UpdateRedirect{
+		ID:      redirect.ID,
+		SpaceID: space.ID,
+		PageID:  &other.HomepageID,
+	})
+	assert.Equal(gorm.ErrRecordNotFound, err)
+}
+
+func TestCheckPageTree(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Tree",
+		Key:    "tree",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	parent, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: space.HomepageID,
+		Status:   models.PageStatusPublished,
+		Title:    "Parent",
+	})
+	assert.Nil(err)
+
+	child, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID:  space.ID,
+		ParentID: parent.ID,
+		Status:   models.PageStatusPublished,
+		Title:    "Child",
+	})
+	assert.Nil(err)
+
+	report, err := spacer.CheckPageTree(ctx, &params.CheckPageTree{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Equal(3, report.Pages)
+	assert.Len(report.Issues, 0)
+
+	// corrupt the nested set
+	database := spacer.(*service).Database
+	assert.Nil(database.Model(&models.Page{}).Where("`id` = ?", child.ID).UpdateColumns(map[string]interface{}{"depth": 5, "rgt": 100}).Error)
+
+	report, err = spacer.CheckPageTree(ctx, &params.CheckPageTree{SpaceID: space.ID, Repair: true, DryRun: true})
+	assert.Nil(err)
+	assert.True(report.DryRun)
+	assert.NotEmpty(report.Issues)
+	assert.Equal(1, report.Repaired)
+
+	kinds := lo.Map(report.Issues, func(issue *models.TreeIssue, _ int) models.TreeIssueKind { return issue.Kind })
+	assert.Contains(kinds, models.TreeIssueDepth)
+	assert.Contains(kinds, models.TreeIssueOverlap)
+
+	report, err = spacer.CheckPageTree(ctx, &params.CheckPageTree{SpaceID: space.ID, Repair: true})
+	assert.Nil(err)
+	assert.Equal(1, report.Repaired)
+
+	report, err = spacer.CheckPageTree(ctx, &params.CheckPageTree{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Len(report.Issues, 0)
+
+	pages, err := spacer.DescribePages(ctx, &params.DescribePages{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Len(pages, 1)
+	assert.Len(pages[0].Children, 1)
+	assert.Len(pages[0].Children[0].Children, 1)
+
+	// the parents in other spaces are reported, the pages are not re-rooted
+	forest, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Forest",
+		Key:    "forest",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	assert.Nil(database.Model(&models.Page{}).Where("`id` = ?", child.ID).UpdateColumn("parent_id", forest.HomepageID).Error)
+
+	report, err = spacer.CheckPageTree(ctx, &params.CheckPageTree{SpaceID: space.ID, Repair: true})
+	assert.Nil(err)
+
+	kinds = lo.Map(report.Issues, func(issue *models.TreeIssue, _ int) models.TreeIssueKind { return issue.Kind })
+	assert.Contains(kinds, models.TreeIssueCrossSpace)
+
+	var page *models.Page
+	assert.Nil(database.Where("`id` = ?", child.ID).First(&page).Error)
+	assert.Equal(forest.HomepageID, page.ParentID.Int64)
+	assert.Equal(space.ID, page.SpaceID)
+}
+
+func TestPageNavigation(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Tutorial",
+		Key:    "tutorial",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	create := func(parentID int64, title string, status models.PageStatus) *models.Page {
+		page, err := spacer.CreatePage(ctx, &params.CreatePage{
+			SpaceID:  space.ID,
+			ParentID: parentID,
+			Status:   status,
+			Title:    title,
+		})
+		assert.Nil(err)
+		return page
+	}
+
+	var (
+		first  = create(space.HomepageID, "First", models.PageStatusPublished)
+		draft  = create(first.ID, "Draft", models.PageStatusDraft)
+		second = create(first.ID, "Second", models.PageStatusPublished)
+		third  = create(space.HomepageID, "Third", models.PageStatusPublished)
+	)
+	assert.NotNil(draft)
+
+	page, err := spacer.DescribePage(ctx, &params.DescribePage{
+		SpaceID:    space.ID,
+		PageID:     second.ID,
+		Navigation: true,
+	})
+	assert.Nil(err)
+	assert.Equal(first.ID, page.Previous.ID)
+	assert.Equal("First", page.Previous.Content.Title)
+	assert.Equal(third.ID, page.Next.ID)
+
+	page, err = spacer.DescribePage(ctx, &params.DescribePage{
+		SpaceID:    space.ID,
+		PageID:     first.ID,
+		Navigation: true,
+	})
+	assert.Nil(err)
+	assert.Nil(page.Previous)
+	assert.Equal(second.ID, page.Next.ID)
+
+	page, err = spacer.DescribePage(ctx, &params.DescribePage{
+		SpaceID:    space.ID,
+		PageID:     third.ID,
+		Navigation: true,
+	})
+	assert.Nil(err)
+	assert.Equal(second.ID, page.Previous.ID)
+	assert.Nil(page.Next)
+}
+
+func TestDescribePageTree(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "API Reference",
+		Key:    "api-reference",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	create := func(parentID int64, title string) *models.Page {
+		page, err := spacer.CreatePage(ctx, &params.CreatePage{
+			SpaceID:  space.ID,
+			ParentID: parentID,
+			Status:   models.PageStatusPublished,
+			Title:    title,
+		})
+		assert.Nil(err)
+		return page
+	}
+
+	var (
+		api     = create(0, "API")
+		guide   = create(0, "Guide")
+		objects = create(api.ID, "Objects")
+		bucket  = create(objects.ID, "Bucket")
+	)
+
+	for i := 0; i < 5; i++ {
+		create(api.ID, fmt.Sprintf("Method %d", i))
+	}
+
+	// root pages
+	tree, err := spacer.DescribePageTree(ctx, &params.DescribePageTree{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Len(tree.Items, 3)
+	assert.Equal(space.HomepageID, tree.Items[0].ID)
+	assert.Equal(api.ID, tree.Items[1].ID)
+	assert.Equal(6, tree.Items[1].ChildrenCount)
+	assert.Empty(tree.Items[1].Children)
+	assert.Zero(tree.Next)
+
+	// children pages with cursor
+	var titles []string
+	args := &params.DescribePageTree{SpaceID: space.ID, ParentID: api.ID, Limit: 4}
+	for {
+		tree, err := spacer.DescribePageTree(ctx, args)
+		assert.Nil(err)
+		assert.LessOrEqual(len(tree.Items), 4)
+
+		for _, page := range tree.Items {
+			titles = append(titles, page.Content.Title)
+		}
+
+		if tree.Next == 0 {
+			break
+		}
+		args.After = tree.Next
+	}
+	assert.Equal([]string{"Objects", "Method 0", "Method 1", "Method 2", "Method 3", "Method 4"}, titles)
+
+	// expand to the page, every sibling group is limited and includes the page on the path
+	tree, err = spacer.DescribePageTree(ctx, &params.DescribePageTree{SpaceID: space.ID, Expand: bucket.ID, Limit: 1})
+	assert.Nil(err)
+	assert.Len(tree.Items, 2)
+	assert.Equal(api.ID, tree.Items[1].ID)
+	assert.Len(tree.Items[1].Children, 1)
+	assert.Equal(objects.ID, tree.Items[1].Children[0].ID)
+	assert.Len(tree.Items[1].Children[0].Children, 1)
+	assert.Equal(bucket.ID, tree.Items[1].Children[0].Children[0].ID)
+	assert.Zero(tree.Items[1].Children[0].ChildrenNext)
+
+	// the cursors continue the truncated groups
+	roots, err := spacer.DescribePageTree(ctx, &params.DescribePageTree{SpaceID: space.ID, After: tree.Next})
+	assert.Nil(err)
+	assert.Len(roots.Items, 1)
+	assert.Equal(guide.ID, roots.Items[0].ID)
+
+	children, err := spacer.DescribePageTree(ctx, &params.DescribePageTree{SpaceID: space.ID, ParentID: api.ID, After: tree.Items[1].ChildrenNext})
+	assert.Nil(err)
+	assert.Len(children.Items, 5)
+	assert.Equal("Method 0", children.Items[0].Content.Title)
+}
+
+func TestArchiveAndDeleteSpace(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Legacy",
+		Key:    "legacy",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	// archived space is read-only and can be filtered out
+	space, err = spacer.ArchiveSpace(ctx, &params.ArchiveSpace{Key: "legacy"})
+	assert.Nil(err)
+	assert.True(space.IsArchived())
+
+	_, err = spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Changelog",
+	})
+	assert.ErrorIs(err, ErrSpaceIsArchived)
+
+	_, err = spacer.UpdateSpace(ctx, &params.UpdateSpace{Key: "legacy", Name: lo.ToPtr("Legacy Docs")})
+	assert.ErrorIs(err, ErrSpaceIsArchived)
+
+	_, err = spacer.CreateMember(ctx, &params.CreateMember{SpaceID: space.ID, AccountID: 300, Role: models.MemberRoleViewer})
+	assert.ErrorIs(err, ErrSpaceIsArchived)
+
+	pagination, err := spacer.DescribeSpaces(ctx, &params.DescribeSpaces{Archived: lo.ToPtr(false)})
+	assert.Nil(err)
+	for _, item := range pagination.Items {
+		assert.NotEqual(space.ID, item.ID)
+	}
+
+	space, err = spacer.RestoreSpace(ctx, &params.RestoreSpace{Key: "legacy"})
+	assert.Nil(err)
+	assert.False(space.IsArchived())
+
+	_, err = spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Changelog",
+	})
+	assert.Nil(err)
+
+	// deletion requires the confirmation token of the space
+	err = spacer.DeleteSpace(ctx, &params.DeleteSpace{Key: "legacy", Token: "invalid"})
+	assert.ErrorIs(err, ErrDeleteSpaceTokenIsInvalid)
+
+	token, err := spacer.CreateDeleteSpaceToken(ctx, &params.CreateDeleteSpaceToken{Key: "legacy"})
+	assert.Nil(err)
+
+	other, err := spacer.CreateDeleteSpaceToken(ctx, &params.CreateDeleteSpaceToken{Key: "website"})
+	assert.Nil(err)
+
+	err = spacer.DeleteSpace(ctx, &params.DeleteSpace{Key: "legacy", Token: other})
+	assert.ErrorIs(err, ErrDeleteSpaceTokenIsInvalid)
+
+	// the token can not be forged without the secret
+	claims := jwt.MapClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	assert.Nil(err)
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("__delete_space_token__"))
+	assert.Nil(err)
+
+	err = spacer.DeleteSpace(ctx, &params.DeleteSpace{Key: "legacy", Token: forged})
+	assert.ErrorIs(err, ErrDeleteSpaceTokenIsInvalid)
+
+	err = spacer.DeleteSpace(ctx, &params.DeleteSpace{Key: "legacy", Token: token})
+	assert.Nil(err)
+
+	_, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "legacy"})
+	assert.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	// the name and key are free again
+	_, err = spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Legacy",
+		Key:    "legacy",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+}
+
+func TestSpaceMembers(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:      "Internal",
+		Key:       "internal",
+		Status:    models.SpaceStatusOnline,
+		Lang:      "en-US",
+		CreatorID: 100,
+	})
+	assert.Nil(err)
+
+	// the creator is the owner
+	member, err := spacer.DescribeMember(ctx, &params.DescribeMember{SpaceID: space.ID, AccountID: 100})
+	assert.Nil(err)
+	assert.Equal(models.MemberRoleOwner, member.Role)
+
+	_, err = spacer.DescribeMember(ctx, &params.DescribeMember{SpaceID: space.ID, AccountID: 101})
+	assert.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	_, err = spacer.CreateMember(ctx, &params.CreateMember{SpaceID: space.ID, AccountID: 101, Role: "admin"})
+	assert.ErrorIs(err, models.ErrMemberRoleIsInvalid)
+
+	member, err = spacer.CreateMember(ctx, &params.CreateMember{SpaceID: space.ID, AccountID: 101, Role: models.MemberRoleEditor})
+	assert.Nil(err)
+	assert.True(member.Role.Includes(models.MemberRoleViewer))
+	assert.True(member.Role.Includes(models.MemberRoleEditor))
+	assert.False(member.Role.Includes(models.MemberRoleMaintainer))
+
+	pagination, err := spacer.DescribeMembers(ctx, &params.DescribeMembers{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.EqualValues(2, pagination.Total)
+
+	// the last owner can not be demoted or removed
+	_, err = spacer.UpdateMember(ctx, &params.UpdateMember{SpaceID: space.ID, AccountID: 100, Role: models.MemberRoleViewer})
+	assert.ErrorIs(err, ErrSpaceLastOwner)
+
+	err = spacer.DeleteMember(ctx, &params.DeleteMember{SpaceID: space.ID, AccountID: 100})
+	assert.ErrorIs(err, ErrSpaceLastOwner)
+
+	_, err = spacer.UpdateMember(ctx, &params.UpdateMember{SpaceID: space.ID, AccountID: 101, Role: models.MemberRoleOwner})
+	assert.Nil(err)
+
+	err = spacer.DeleteMember(ctx, &params.DeleteMember{SpaceID: space.ID, AccountID: 100})
+	assert.Nil(err)
+
+	// spaces of the account
+	spaces, err := spacer.DescribeSpaces(ctx, &params.DescribeSpaces{AccountID: 101})
+	assert.Nil(err)
+	assert.Len(spaces.Items, 1)
+	assert.Equal(space.ID, spaces.Items[0].ID)
+
+	spaces, err = spacer.DescribeSpaces(ctx, &params.DescribeSpaces{AccountID: 100})
+	assert.Nil(err)
+	assert.Len(spaces.Items, 0)
+
+	// the creator of a space without members is migrated as the owner, the reads do not write the members
+	err = spacer.(*service).Database.Where("`space_id` = ?", space.ID).Delete(&models.Member{}).Error
+	assert.Nil(err)
+
+	_, err = spacer.DescribeMember(ctx, &params.DescribeMember{SpaceID: space.ID, AccountID: 100})
+	assert.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	spaces, err = spacer.DescribeSpaces(ctx, &params.DescribeSpaces{AccountID: 100})
+	assert.Nil(err)
+	assert.Len(spaces.Items, 0)
+
+	assert.Nil(models.Migrate(spacer.(*service).Database))
+
+	member, err = spacer.DescribeMember(ctx, &params.DescribeMember{SpaceID: space.ID, AccountID: 100})
+	assert.Nil(err)
+	assert.NotZero(member.ID)
+	assert.Equal(models.MemberRoleOwner, member.Role)
+
+	_, err = spacer.UpdateMember(ctx, &params.UpdateMember{SpaceID: space.ID, AccountID: 100, Role: models.MemberRoleViewer})
+	assert.ErrorIs(err, ErrSpaceLastOwner)
+
+	err = spacer.DeleteMember(ctx, &params.DeleteMember{SpaceID: space.ID, AccountID: 100})
+	assert.ErrorIs(err, ErrSpaceLastOwner)
+}
+
+func TestSpaceVisibility(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	_, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:       "Runbooks",
+		Key:        "runbooks",
+		Status:     models.SpaceStatusOnline,
+		Visibility: "secret",
+		Lang:       "en-US",
+	})
+	assert.ErrorIs(err, models.ErrSpaceVisibilityIsInvalid)
+
+	create := func(key string, visibility models.SpaceVisibility) *models.Space {
+		space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+			Name:       key,
+			Key:        key,
+			Status:     models.SpaceStatusOnline,
+			Visibility: visibility,
+			Lang:       "en-US",
+			CreatorID:  200,
+		})
+		assert.Nil(err)
+
+		_, err = spacer.CreatePage(ctx, &params.CreatePage{
+			SpaceID: space.ID,
+			Status:  models.PageStatusPublished,
+			Title:   "Visibility " + key,
+		})
+		assert.Nil(err)
+
+		return space
+	}
+
+	var (
+		public   = create("visibility-public", "")
+		internal = create("visibility-internal", models.SpaceVisibilityInternal)
+		private  = create("visibility-private", models.SpaceVisibilityPrivate)
+	)
+	assert.Equal(models.SpaceVisibilityPublic, public.Visibility)
+
+	_, err = spacer.CreateMember(ctx, &params.CreateMember{SpaceID: private.ID, AccountID: 201, Role: models.MemberRoleViewer})
+	assert.Nil(err)
+
+	visible := func(viewer *params.Viewer) []int64 {
+		pagination, err := spacer.DescribeSpaces(ctx, &params.DescribeSpaces{Q: "visibility-", Viewer: viewer})
+		assert.Nil(err)
+
+		ids := lo.Map(pagination.Items, func(space *models.Space, _ int) int64 { return space.ID })
+
+		result, err := spacer.Serach(ctx, &params.Search{Lang: "en-US", Q: "Visibility visibility-", Viewer: viewer})
+		assert.Nil(err)
+		assert.ElementsMatch(ids, lo.Map(result.Items, func(page *models.Page, _ int) int64 { return page.SpaceID }))
+
+		for _, space := range []*models.Space{public, internal, private} {
+			_, err := spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: space.Key, Viewer: viewer})
+			if lo.Contains(ids, space.ID) {
+				assert.Nil(err)
+			} else {
+				assert.ErrorIs(err, ErrSpaceAccessDenied)
+			}
+		}
+
+		return ids
+	}
+
+	assert.ElementsMatch([]int64{public.ID}, visible(&params.Viewer{}))
+	assert.ElementsMatch([]int64{public.ID, internal.ID}, visible(&params.Viewer{AccountID: 202}))
+	assert.ElementsMatch([]int64{public.ID, internal.ID, private.ID}, visible(&params.Viewer{AccountID: 201}))
+	assert.ElementsMatch([]int64{public.ID, internal.ID, private.ID}, visible(&params.Viewer{Unrestricted: true}))
+
+	// nil viewer is anonymous
+	assert.ElementsMatch([]int64{public.ID}, visible(nil))
+}
+
+func TestPageRestrictions(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:      "Restrictions",
+		Key:       "restrictions",
+		Status:    models.SpaceStatusOnline,
+		Lang:      "en-US",
+		CreatorID: 300,
+	})
+	assert.Nil(err)
+
+	_, err = spacer.CreateMember(ctx, &params.CreateMember{SpaceID: space.ID, AccountID: 301, Role: models.MemberRoleViewer})
+	assert.Nil(err)
+	_, err = spacer.CreateMember(ctx, &params.CreateMember{SpaceID: space.ID, AccountID: 302, Role: models.MemberRoleEditor})
+	assert.Nil(err)
+
+	create := func(title string, parentID int64) *models.Page {
+		page, err := spacer.CreatePage(ctx, &params.CreatePage{
+			SpaceID:  space.ID,
+			ParentID: parentID,
+			Status:   models.PageStatusPublished,
+			Title:    title,
+		})
+		assert.Nil(err)
+		return page
+	}
+
+	var (
+		open     = create("Restricted open", 0)
+		secret   = create("Restricted secret", 0)
+		child    = create("Restricted child", secret.ID)
+		personal = create("Restricted personal", 0)
+	)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: secret.ID})
+	assert.ErrorIs(err, ErrPageRestrictionIsInvalid)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: secret.ID, AccountID: 301, Role: models.MemberRoleEditor})
+	assert.ErrorIs(err, ErrPageRestrictionIsInvalid)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: secret.ID, Role: models.MemberRoleEditor})
+	assert.Nil(err)
+
+	restriction, err := spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: personal.ID, AccountID: 301})
+	assert.Nil(err)
+
+	restrictions, err := spacer.DescribePageRestrictions(ctx, &params.DescribePageRestrictions{SpaceID: space.ID, PageID: personal.ID})
+	assert.Nil(err)
+	assert.Len(restrictions, 1)
+
+	readable := func(viewer *params.Viewer) []int64 {
+		pages, err := spacer.DescribePages(ctx, &params.DescribePages{SpaceID: space.ID, Viewer: viewer})
+		assert.Nil(err)
+
+		var ids []int64
+		var walk func(pages []*models.Page)
+		walk = func(pages []*models.Page) {
+			for _, page := range pages {
+				if page.ID != space.HomepageID {
+					ids = append(ids, page.ID)
+				}
+				walk(page.Children)
+			}
+		}
+		walk(pages)
+
+		for _, page := range []*models.Page{open, secret, child, personal} {
+			_, err := spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: page.ID, Viewer: viewer})
+			if lo.Contains(ids, page.ID) {
+				assert.Nil(err)
+			} else {
+				assert.ErrorIs(err, ErrPageAccessDenied)
+			}
+		}
+
+		result, err := spacer.Serach(ctx, &params.Search{Lang: "en-US", Q: "Restricted", Viewer: viewer})
+		assert.Nil(err)
+		assert.ElementsMatch(ids, lo.Map(result.Items, func(page *models.Page, _ int) int64 { return page.ID }))
+
+		return ids
+	}
+
+	assert.ElementsMatch([]int64{open.ID}, readable(&params.Viewer{}))
+	assert.ElementsMatch([]int64{open.ID, personal.ID}, readable(&params.Viewer{AccountID: 301}))
+	assert.ElementsMatch([]int64{open.ID, secret.ID, child.ID}, readable(&params.Viewer{AccountID: 302}))
+	assert.ElementsMatch([]int64{open.ID, secret.ID, child.ID}, readable(&params.Viewer{AccountID: 300}))
+	assert.ElementsMatch([]int64{open.ID, secret.ID, child.ID, personal.ID}, readable(&params.Viewer{Unrestricted: true}))
+	assert.ElementsMatch([]int64{open.ID}, readable(nil))
+
+	// the navigation skips the restricted pages
+	page, err := spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: open.ID, Navigation: true, Viewer: &params.Viewer{AccountID: 301}})
+	assert.Nil(err)
+	assert.NotNil(page.Next)
+	assert.Equal(personal.ID, page.Next.ID)
+
+	// the copies keep the restrictions
+	copied, err := spacer.CopyPage(ctx, &params.CopyPage{SpaceID: space.ID, PageID: personal.ID, TargetSpaceID: space.ID, Viewer: &params.Viewer{AccountID: 301}})
+	assert.Nil(err)
+
+	_, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: copied.ID, Viewer: &params.Viewer{AccountID: 302}})
+	assert.ErrorIs(err, ErrPageAccessDenied)
+
+	err = spacer.DeletePageRestriction(ctx, &params.DeletePageRestriction{ID: restriction.ID, SpaceID: space.ID, PageID: personal.ID})
+	assert.Nil(err)
+
+	_, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: personal.ID, Viewer: &params.Viewer{}})
+	assert.Nil(err)
+}
+
+func TestSpaceTemplates(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	list, err := spacer.DescribeSpaceTemplates(ctx)
+	assert.Nil(err)
+	assert.Contains(lo.Map(list, func(template *models.SpaceTemplate, _ int) string { return template.Name }), "microservice")
+
+	_, err = spacer.CreateSpace(ctx, &params.CreateSpace{Name: "Unknown", Key: "unknown", Status: models.SpaceStatusOnline, Template: "unknown"})
+	assert.ErrorIs(err, ErrSpaceTemplateNotFound)
+
+	_, err = spacer.CreateSpace(ctx, &params.CreateSpace{Name: "Both", Key: "both", Status: models.SpaceStatusOnline, Template: "microservice", FromSpaceID: 1})
+	assert.ErrorIs(err, ErrSpaceTemplateIsInvalid)
+
+	// packaged template
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:      "Payments",
+		Key:       "payments",
+		Status:    models.SpaceStatusOnline,
+		Template:  "microservice",
+		CreatorID: 400,
+	})
+	assert.Nil(err)
+	assert.Equal("en-US", space.Lang)
+	assert.Equal(models.SpaceVisibilityInternal, space.Visibility)
+
+	pages, err := spacer.DescribePages(ctx, &params.DescribePages{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Len(pages, 6)
+
+	homepage, err := spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: space.HomepageID})
+	assert.Nil(err)
+	assert.Equal("Payments", homepage.Content.Title)
+	assert.Contains(homepage.Content.Body, "/docs/payments/operations")
+
+	runbooks, err := spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, Path: "operations/runbooks"})
+	assert.Nil(err)
+	assert.Equal("Runbooks", runbooks.Content.Title)
+
+	// existing space, all languages are copied
+	err = spacer.(*service).Database.Create(&models.PageContent{
+		SpaceID: space.ID,
+		PageID:  runbooks.ID,
+		Lang:    "zh-CN",
+		Status:  models.PageStatusPublished,
+		Title:   "运行手册",
+	}).Error
+	assert.Nil(err)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: runbooks.ID, Role: models.MemberRoleEditor})
+	assert.Nil(err)
+
+	clone, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:        "Billing",
+		Key:         "billing",
+		CreatorID:   401,
+		FromSpaceID: space.ID,
+	})
+	assert.Nil(err)
+	assert.Equal(space.Status, clone.Status)
+	assert.Equal(space.Visibility, clone.Visibility)
+	assert.NotEqual(space.HomepageID, clone.HomepageID)
+
+	homepage, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: clone.ID, PageID: clone.HomepageID})
+	assert.Nil(err)
+	assert.Contains(homepage.Content.Body, "/docs/billing/operations")
+
+	runbooks, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: clone.ID, Path: "operations/runbooks", Lang: "zh-CN", Viewer: &params.Viewer{Unrestricted: true}})
+	assert.Nil(err)
+	assert.Equal("运行手册", runbooks.Content.Title)
+
+	_, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: clone.ID, PageID: runbooks.ID, Viewer: &params.Viewer{}})
+	assert.ErrorIs(err, ErrPageAccessDenied)
+}
+
+func TestRenameAndTransferSpace(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:      "Platform",
+		Key:       "platform",
+		Status:    models.SpaceStatusOnline,
+		Lang:      "en-US",
+		CreatorID: 500,
+	})
+	assert.Nil(err)
+
+	other, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Infra",
+		Key:    "infra",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	_, err = spacer.RenameSpaceKey(ctx, &params.RenameSpaceKey{Key: space.Key, NewKey: other.Key})
+	assert.ErrorIs(err, ErrSpaceKeyIsTaken)
+
+	_, err = spacer.RenameSpaceKey(ctx, &params.RenameSpaceKey{Key: space.Key, NewKey: "platform/team"})
+	assert.ErrorIs(err, ErrSpaceKeyIsInvalid)
+
+	renamed, err := spacer.RenameSpaceKey(ctx, &params.RenameSpaceKey{Key: space.Key, NewKey: "platform-team"})
+	assert.Nil(err)
+	assert.Equal("platform-team", renamed.Key)
+
+	_, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "platform"})
+	var moved *SpaceMovedError
+	assert.ErrorAs(err, &moved)
+	assert.Equal("platform-team", moved.Key)
+
+	_, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "platform-team"})
+	assert.Nil(err)
+
+	// the previous key is reserved
+	_, err = spacer.CreateSpace(ctx, &params.CreateSpace{Name: "Platform v2", Key: "platform", Status: models.SpaceStatusOnline})
+	assert.ErrorIs(err, ErrSpaceKeyIsTaken)
+
+	_, err = spacer.RenameSpaceKey(ctx, &params.RenameSpaceKey{Key: other.Key, NewKey: "platform"})
+	assert.ErrorIs(err, ErrSpaceKeyIsTaken)
+
+	// renamed back
+	_, err = spacer.RenameSpaceKey(ctx, &params.RenameSpaceKey{Key: "platform-team", NewKey: "platform"})
+	assert.Nil(err)
+
+	_, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "platform-team"})
+	assert.ErrorAs(err, &moved)
+	assert.Equal("platform", moved.Key)
+
+	// the private spaces are not revealed by their previous keys
+	_, err = spacer.UpdateSpace(ctx, &params.UpdateSpace{Key: "platform", Visibility: models.SpaceVisibilityPrivate})
+	assert.Nil(err)
+
+	_, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "platform-team"})
+	assert.ErrorIs(err, ErrSpaceAccessDenied)
+
+	_, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: "platform-team", Viewer: &params.Viewer{AccountID: 500}})
+	assert.ErrorAs(err, &moved)
+
+	location := SpaceMovedLocation(&url.URL{Path: "/api/spaces/platform-team/pages", RawQuery: "lang=en-US"}, "platform-team", "platform")
+	assert.Equal("/api/spaces/platform/pages?lang=en-US", location)
+
+	// ownership
+	transferred, err := spacer.TransferSpace(ctx, &params.TransferSpace{Key: "platform", AccountID: 501, OwnerID: 500})
+	assert.Nil(err)
+	assert.Equal(int64(501), transferred.CreatorID)
+
+	role := func(accountID int64) models.MemberRole {
+		member, err := spacer.DescribeMember(ctx, &params.DescribeMember{SpaceID: space.ID, AccountID: accountID})
+		assert.Nil(err)
+		return member.Role
+	}
+
+	assert.Equal(models.MemberRoleOwner, role(501))
+	assert.Equal(models.MemberRoleMaintainer, role(500))
+
+	// a co-owner transfers the space, the co-owner is demoted instead of the creator
+	_, err = spacer.CreateMember(ctx, &params.CreateMember{SpaceID: space.ID, AccountID: 502, Role: models.MemberRoleOwner})
+	assert.Nil(err)
+
+	_, err = spacer.TransferSpace(ctx, &params.TransferSpace{Key: "platform", AccountID: 503, OwnerID: 502})
+	assert.Nil(err)
+	assert.Equal(models.MemberRoleOwner, role(501))
+	assert.Equal(models.MemberRoleMaintainer, role(502))
+	assert.Equal(models.MemberRoleOwner, role(503))
+
+	// the demoted creator gets the owner role back
+	_, err = spacer.UpdateMember(ctx, &params.UpdateMember{SpaceID: space.ID, AccountID: 501, Role: models.MemberRoleViewer})
+	assert.Nil(err)
+
+	transferred, err = spacer.TransferSpace(ctx, &params.TransferSpace{Key: "platform", AccountID: 501, OwnerID: 503})
+	assert.Nil(err)
+	assert.Equal(int64(501), transferred.CreatorID)
+	assert.Equal(models.MemberRoleOwner, role(501))
+	assert.Equal(models.MemberRoleMaintainer, role(503))
+}
+
+func TestUpdateSpaceSettings(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Branding",
+		Key:    "branding",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	_, err = spacer.UpdateSpaceSettings(ctx, &params.UpdateSpaceSettings{Key: space.Key, AccentColor: lo.ToPtr("red; background: url(x)")})
+	assert.ErrorIs(err, models.ErrSpaceSettingsIsInvalid)
+
+	_, err = spacer.UpdateSpaceSettings(ctx, &params.UpdateSpaceSettings{
+		Key:      space.Key,
+		NavLinks: &[]*models.SpaceNavLink{{Title: "Status", URL: "javascript:alert(1)"}},
+	})
+	assert.ErrorIs(err, models.ErrSpaceSettingsIsInvalid)
+
+	_, err = spacer.UpdateSpaceSettings(ctx, &params.UpdateSpaceSettings{
+		Key:         space.Key,
+		NavLinks:    &[]*models.SpaceNavLink{{Title: "Status", URL: "https://status.example.com"}},
+		Footer:      lo.ToPtr("**Payments** team <script>alert(1)</script>"),
+		AccentColor: lo.ToPtr("#375EAB"),
+		EditURL:     lo.ToPtr("https://github.com/example/docs/edit/main/{lang}/{path}.md"),
+	})
+	assert.Nil(err)
+
+	// partial update keeps the other settings
+	_, err = spacer.UpdateSpaceSettings(ctx, &params.UpdateSpaceSettings{Key: space.Key, Logo: lo.ToPtr("/static/logo.png")})
+	assert.Nil(err)
+
+	space, err = spacer.DescribeSpace(ctx, &params.DescribeSpace{Key: space.Key})
+	assert.Nil(err)
+
+	settings := space.Settings
+	assert.Len(settings.NavLinks, 1)
+	assert.Equal("#375EAB", settings.AccentColor)
+	assert.Equal("/static/logo.png", settings.Logo)
+	assert.Contains(settings.FooterHTML, "<strong>Payments</strong>")
+	assert.NotContains(settings.FooterHTML, "<script>")
+
+	page := &models.Page{ID: 7, Path: "guides/install", Content: &models.PageContent{Lang: "zh-CN"}}
+	assert.Equal("https://github.com/example/docs/edit/main/zh-CN/guides/install.md", settings.EditLink(space, page))
+}
+
+func TestDescribeSpaceStats(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Stats",
+		Key:    "stats",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	create := func(title, body string, status models.PageStatus, creatorID int64) *models.Page {
+		page, err := spacer.CreatePage(ctx, &params.CreatePage{
+			SpaceID:   space.ID,
+			CreatorID: creatorID,
+			Status:    status,
+			Title:     title,
+			Body:      body,
+		})
+		assert.Nil(err)
+		return page
+	}
+
+	var (
+		_     = create("Stats install", "Install the service", models.PageStatusPublished, 600)
+		usage = create("Stats usage", "使用 the service", models.PageStatusPublished, 600)
+		draft = create("Stats draft", "Draft", models.PageStatusDraft, 601)
+	)
+
+	// the draft was last updated a year ago
+	err = spacer.(*service).Database.Model(&models.PageContent{}).
+		Where("`page_id` = ?", draft.ID).
+		UpdateColumn("updated_at", time.Now().AddDate(-1, 0, 0).Unix()).Error
+	assert.Nil(err)
+
+	stats, err := spacer.DescribeSpaceStats(ctx, &params.DescribeSpaceStats{SpaceID: space.ID})
+	assert.Nil(err)
+
+	assert.Equal(int64(4), stats.Pages) // with the homepage
+	assert.Equal(int64(4), stats.Contents)
+	assert.GreaterOrEqual(stats.Words, int64(3+4+1))
+
+	counts := lo.SliceToMap(stats.Statuses, func(count *models.StatsCount) (string, int64) { return count.Name, count.Count })
+	assert.Equal(int64(1), counts[string(models.PageStatusDraft)])
+
+	assert.Len(stats.Langs, 1)
+	assert.Equal("en-US", stats.Langs[0].Name)
+
+	assert.Len(stats.StalePages, 1)
+	assert.Equal(draft.ID, stats.StalePages[0].ID)
+	assert.NotEqual(draft.ID, stats.RecentlyUpdated[0].ID)
+
+	assert.Len(stats.Contributors, 1)
+	assert.Equal(int64(600), stats.Contributors[0].AccountID)
+	assert.Equal(int64(2), stats.Contributors[0].Contents)
+
+	// the pages are listed once, the restricted pages are not counted for the viewers who can not read them
+	err = spacer.(*service).Database.Create(&models.PageContent{
+		SpaceID: space.ID,
+		PageID:  draft.ID,
+		Lang:    "zh-CN",
+		Status:  models.PageStatusDraft,
+		Title:   "草稿",
+	}).Error
+	assert.Nil(err)
+
+	err = spacer.(*service).Database.Model(&models.PageContent{}).
+		Where("`page_id` = ?", draft.ID).
+		UpdateColumn("updated_at", time.Now().AddDate(-1, 0, 0).Unix()).Error
+	assert.Nil(err)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: usage.ID, AccountID: 600})
+	assert.Nil(err)
+
+	stats, err = spacer.DescribeSpaceStats(ctx, &params.DescribeSpaceStats{SpaceID: space.ID, Viewer: &params.Viewer{AccountID: 600}})
+	assert.Nil(err)
+	assert.Equal(int64(5), stats.Contents)
+	assert.Len(stats.StalePages, 1)
+	assert.Len(stats.RecentlyUpdated, 4)
+
+	stats, err = spacer.DescribeSpaceStats(ctx, &params.DescribeSpaceStats{SpaceID: space.ID, Viewer: &params.Viewer{AccountID: 601}})
+	assert.Nil(err)
+	assert.Equal(int64(3), stats.Pages)
+	assert.Equal(int64(4), stats.Contents)
+	assert.Len(stats.RecentlyUpdated, 3)
+	assert.Equal(int64(1), stats.Contributors[0].Contents)
+	for _, page := range stats.RecentlyUpdated {
+		assert.NotEqual(usage.ID, page.ID)
+	}
+
+	assert.Equal(int64(4), countWords("Hello, world 你好"))
+}
+
+func TestAttachments(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:       "Attachments",
+		Key:        "attachments",
+		Status:     models.SpaceStatusOnline,
+		Lang:       "en-US",
+		Visibility: models.SpaceVisibilityPrivate,
+		CreatorID:  700,
+	})
+	assert.Nil(err)
+
+	page, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Attachments page",
+		Body:    "![diagram](attachment:1/diagram.png)",
+	})
+	assert.Nil(err)
+	assert.Contains(page.Content.HTML, `src="/attachments/1/diagram.png"`)
+
+	_, err = spacer.CreateAttachment(ctx, &params.CreateAttachment{
+		SpaceID: space.ID,
+		PageID:  page.ID,
+		Name:    "../",
+		Reader:  strings.NewReader("x"),
+	})
+	assert.ErrorIs(err, ErrAttachmentIsInvalid)
+
+	attachment, err := spacer.CreateAttachment(ctx, &params.CreateAttachment{
+		SpaceID:   space.ID,
+		PageID:    page.ID,
+		CreatorID: 700,
+		Name:      "../notes v1.txt",
+		Reader:    strings.NewReader("release notes"),
+	})
+	assert.Nil(err)
+	assert.Equal("notes v1.txt", attachment.Name)
+	assert.Equal(int64(13), attachment.Size)
+	assert.True(strings.HasPrefix(attachment.ContentType, "text/plain"))
+	assert.Equal(fmt.Sprintf("attachment:%d/notes%%20v1.txt", attachment.ID), attachment.Reference())
+
+	attachments, err := spacer.DescribeAttachments(ctx, &params.DescribeAttachments{SpaceID: space.ID, PageID: page.ID})
+	assert.Nil(err)
+	assert.Len(attachments, 1)
+
+	// private space attachments are not readable by anonymous viewers
+	_, _, err = spacer.OpenAttachment(ctx, &params.OpenAttachment{ID: attachment.ID, Viewer: &params.Viewer{}})
+	assert.ErrorIs(err, ErrSpaceAccessDenied)
+
+	_, reader, err := spacer.OpenAttachment(ctx, &params.OpenAttachment{ID: attachment.ID, Viewer: &params.Viewer{AccountID: 700}})
+	assert.Nil(err)
+	content, err := io.ReadAll(reader)
+	assert.Nil(err)
+	assert.Nil(reader.Close())
+	assert.Equal("release notes", string(content))
+
+	err = spacer.DeleteAttachment(ctx, &params.DeleteAttachment{ID: attachment.ID, SpaceID: space.ID, PageID: page.ID})
+	assert.Nil(err)
+
+	_, _, err = spacer.OpenAttachment(ctx, &params.OpenAttachment{ID: attachment.ID})
+	assert.ErrorIs(err, gorm.ErrRecordNotFound)
+}
+
+func TestImageAttachments(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Images",
+		Key:    "images",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	page, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Screenshots",
+	})
+	assert.Nil(err)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(png.Encode(buf, image.NewNRGBA(image.Rect(0, 0, 2000, 1000))))
+
+	attachment, err := spacer.CreateAttachment(ctx, &params.CreateAttachment{
+		SpaceID: space.ID,
+		PageID:  page.ID,
+		Name:    "screenshot.png",
+		Reader:  buf,
+	})
+	assert.Nil(err)
+	assert.Equal(2000, attachment.Width)
+	assert.Equal(1000, attachment.Height)
+	assert.Equal([]string{"320w", "768w", "1280w", "thumbnail"}, lo.Map(attachment.Variants, func(v *models.AttachmentVariant, _ int) string { return v.Name }))
+
+	_, reader, err := spacer.OpenAttachment(ctx, &params.OpenAttachment{ID: attachment.ID, Variant: "thumbnail"})
+	assert.Nil(err)
+	thumbnail, err := png.DecodeConfig(reader)
+	assert.Nil(err)
+	assert.Nil(reader.Close())
+	assert.Equal(240, thumbnail.Width)
+	assert.Equal(120, thumbnail.Height)
+
+	body := fmt.Sprintf("![screenshot](%s)", attachment.Reference())
+	page, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: page.ID, Body: &body})
+	assert.Nil(err)
+
+	html := page.Content.HTML
+	assert.Contains(html, `width="2000"`)
+	assert.Contains(html, `height="1000"`)
+	assert.Contains(html, fmt.Sprintf(`/attachments/%d/screenshot.png?v=320w 320w`, attachment.ID))
+	assert.NotContains(html, "thumbnail")
+
+	err = spacer.DeleteAttachment(ctx, &params.DeleteAttachment{ID: attachment.ID, SpaceID: space.ID, PageID: page.ID})
+	assert.Nil(err)
+}
+
+func TestWikiLinks(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Wiki",
+		Key:    "wiki",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	other, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Wiki Guide",
+		Key:    "wiki-guide",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	install, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Install",
+	})
+	assert.Nil(err)
+
+	usage, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: other.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Usage",
+	})
+	assert.Nil(err)
+
+	body := fmt.Sprintf("[[Install]], [[wiki-guide:Usage|usage guide]], [setup](page:%d) and [[Missing]]", install.ID)
+
+	page, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Getting Started",
+		Body:    body,
+	})
+	assert.Nil(err)
+
+	html := page.Content.HTML
+	assert.Contains(html, `<a class="wiki-link" href="/docs/wiki/install" rel="nofollow">Install</a>`)
+	assert.Contains(html, `<a class="wiki-link" href="/docs/wiki-guide/usage" rel="nofollow">usage guide</a>`)
+	assert.Contains(html, `<a href="/docs/wiki/install" rel="nofollow">setup</a>`)
+	assert.Contains(html, `<span class="wiki-link-missing">Missing</span>`)
+
+	backlinks, err := spacer.DescribeBacklinks(ctx, &params.DescribeBacklinks{PageID: install.ID})
+	assert.Nil(err)
+	assert.Equal([]int64{page.ID}, lo.Map(backlinks, func(p *models.Page, _ int) int64 { return p.ID }))
+	assert.Equal("Getting Started", backlinks[0].Content.Title)
+
+	backlinks, err = spacer.DescribeBacklinks(ctx, &params.DescribeBacklinks{PageID: usage.ID})
+	assert.Nil(err)
+	assert.Len(backlinks, 1)
+
+	htmlOf := func(id int64) string {
+		var content *models.PageContent
+		assert.Nil(spacer.(*service).Database.Where("`page_id` = ?", id).First(&content).Error)
+		return content.HTML
+	}
+
+	// the links follow the title of the linked page, and the new pages matching the missing links
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: install.ID, Title: lo.ToPtr("Setup"), ShortTitle: lo.ToPtr("Setup")})
+	assert.Nil(err)
+
+	assert.Contains(htmlOf(page.ID), `<span class="wiki-link-missing">Install</span>`)
+	assert.Contains(htmlOf(page.ID), `<a href="/docs/wiki/install" rel="nofollow">setup</a>`)
+
+	missing, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Missing",
+	})
+	assert.Nil(err)
+	assert.Contains(htmlOf(page.ID), `<a class="wiki-link" href="/docs/wiki/missing" rel="nofollow">Missing</a>`)
+
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: missing.ID, Slug: lo.ToPtr("found")})
+	assert.Nil(err)
+	assert.Contains(htmlOf(page.ID), `<a class="wiki-link" href="/docs/wiki/found" rel="nofollow">Missing</a>`)
+
+	// the drafts, the restricted pages and the pages of the private spaces are not linked
+	_, err = spacer.CreatePage(ctx, &params.CreatePage{SpaceID: space.ID, Status: models.PageStatusDraft, Title: "Roadmap"})
+	assert.Nil(err)
+
+	secret, err := spacer.CreatePage(ctx, &params.CreatePage{SpaceID: space.ID, Status: models.PageStatusPublished, Title: "Secret"})
+	assert.Nil(err)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: secret.ID, AccountID: 700})
+	assert.Nil(err)
+
+	_, err = spacer.UpdateSpace(ctx, &params.UpdateSpace{Key: other.Key, Visibility: models.SpaceVisibilityPrivate})
+	assert.Nil(err)
+
+	body = "[[Roadmap]], [[Secret]] and [[wiki-guide:Usage]]"
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: page.ID, Body: &body})
+	assert.Nil(err)
+
+	for _, title := range []string{"Roadmap", "Secret", "Usage"} {
+		assert.Contains(htmlOf(page.ID), `<span class="wiki-link-missing">`+title+`</span>`)
+	}
+
+	// the links are replaced when the body changes
+	body = "[[Missing]]"
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: page.ID, Body: &body})
+	assert.Nil(err)
+
+	backlinks, err = spacer.DescribeBacklinks(ctx, &params.DescribeBacklinks{PageID: install.ID})
+	assert.Nil(err)
+	assert.Empty(backlinks)
+
+}
+
+func TestLinkCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker, err := NewService(spacer.(*service).Database, WithLinkChecker(linkcheck.New(&linkcheck.Config{AllowPrivate: true})))
+	assert.Nil(err)
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Link Check",
+		Key:    "linkcheck",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	_, err = spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Install",
+		Slug:    "install",
+		Body:    "## Requirements\n\n[top](#requirements) and [nowhere](#nowhere)",
+	})
+	assert.Nil(err)
+
+	body := strings.Join([]string{
+		"[install](/docs/linkcheck/install)",
+		"[requirements](/en-US/docs/linkcheck/install#requirements)",
+		"[missing anchor](/docs/linkcheck/install#usage)",
+		"[missing page](/docs/linkcheck/uninstall)",
+		"[missing space](/docs/nowhere/install)",
+		"![missing attachment](/attachments/99999/logo.png)",
+		fmt.Sprintf("[ok](%s/ok) and [gone](%s/gone)", server.URL, server.URL),
+	}, "\n\n")
+
+	started, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Getting Started",
+		Body:    body,
+	})
+	assert.Nil(err)
+
+	wait := func(check *models.LinkCheck) *models.LinkCheck {
+		assert.Eventually(func() bool {
+			check, err = spacer.DescribeLinkCheck(ctx, &params.DescribeLinkCheck{ID: check.ID, SpaceID: space.ID})
+			return err == nil && check.Status.IsFinished()
+		}, 5*time.Second, 10*time.Millisecond)
+		return check
+	}
+
+	// the external urls are not checked without the link checker
+	check, err := spacer.CreateLinkCheck(ctx, &params.CreateLinkCheck{SpaceID: space.ID, External: true})
+	assert.Nil(err)
+	assert.False(check.External)
+
+	check = wait(check)
+	assert.Equal(models.LinkCheckStatusCompleted, check.Status, check.Error)
+
+	issues := lo.Map(check.Issues, func(issue *models.LinkIssue, _ int) string { return string(issue.Kind) + " " + issue.Link })
+	assert.ElementsMatch([]string{
+		"anchor #nowhere",
+		"anchor /docs/linkcheck/install#usage",
+		"page /docs/linkcheck/uninstall",
+		"page /docs/nowhere/install",
+		"attachment /attachments/99999/logo.png",
+	}, issues)
+
+	check, err = checker.CreateLinkCheck(ctx, &params.CreateLinkCheck{SpaceID: space.ID, External: true})
+	assert.Nil(err)
+	assert.True(check.External)
+
+	check = wait(check)
+	assert.Equal(models.LinkCheckStatusCompleted, check.Status, check.Error)
+
+	external := lo.Filter(check.Issues, func(issue *models.LinkIssue, _ int) bool { return issue.Kind == models.LinkIssueExternal })
+	assert.Len(external, 1)
+	assert.Equal(server.URL+"/gone", external[0].Link)
+	assert.Equal("404 Not Found", external[0].Message)
+
+	checks, err := spacer.DescribeLinkChecks(ctx, &params.DescribeLinkChecks{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.EqualValues(2, checks.Total)
+	assert.Equal(check.ID, checks.Items[0].ID)
+
+	// the issues of the restricted pages are hidden from the viewers who can not read them
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: started.ID, AccountID: 800})
+	assert.Nil(err)
+
+	pages := func(check *models.LinkCheck) []int64 {
+		return lo.Uniq(lo.Map(check.Issues, func(issue *models.LinkIssue, _ int) int64 { return issue.PageID }))
+	}
+
+	check, err = spacer.DescribeLinkCheck(ctx, &params.DescribeLinkCheck{ID: check.ID, SpaceID: space.ID, Viewer: &params.Viewer{AccountID: 801}})
+	assert.Nil(err)
+	assert.NotContains(pages(check), started.ID)
+	assert.Len(check.Issues, 1)
+
+	check, err = spacer.DescribeLinkCheck(ctx, &params.DescribeLinkCheck{ID: check.ID, SpaceID: space.ID, Viewer: &params.Viewer{AccountID: 800}})
+	assert.Nil(err)
+	assert.Contains(pages(check), started.ID)
+
+	// one running check per space, the checks interrupted by a restart are stale after the timeout
+	running := &models.LinkCheck{SpaceID: space.ID, Status: models.LinkCheckStatusRunning, RunningID: &space.ID}
+	assert.Nil(spacer.(*service).Database.Create(running).Error)
+
+	_, err = spacer.CreateLinkCheck(ctx, &params.CreateLinkCheck{SpaceID: space.ID})
+	assert.ErrorIs(err, ErrLinkCheckIsRunning)
+
+	err = spacer.(*service).Database.Model(running).UpdateColumn("updated_at", time.Now().Add(-LinkCheckTimeout).Unix()-1).Error
+	assert.Nil(err)
+
+	check, err = spacer.CreateLinkCheck(ctx, &params.CreateLinkCheck{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Equal(models.LinkCheckStatusCompleted, wait(check).Status)
+
+	check, err = spacer.DescribeLinkCheck(ctx, &params.DescribeLinkCheck{ID: running.ID, SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Equal(models.LinkCheckStatusFailed, check.Status)
+}
+
+func TestPageTOC(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Manual",
+		Key:    "manual",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	page, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "API",
+		Body:    "## Spaces\n\n### List `spaces`\n\n#### Pagination\n\n## Pages",
+	})
+	assert.Nil(err)
+
+	page, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: page.ID})
+	assert.Nil(err)
+
+	assert.Equal([]*markdown.Heading{
+		{Level: 2, Text: "Spaces", Anchor: "spaces"},
+		{Level: 3, Text: "List spaces", Anchor: "list-spaces"},
+		{Level: 4, Text: "Pagination", Anchor: "pagination"},
+		{Level: 2, Text: "Pages", Anchor: "pages"},
+	}, page.Content.TOC)
+	assert.Len(page.Content.Outline(), 3)
+
+	data, err := json.Marshal(page)
+	assert.Nil(err)
+	assert.Contains(string(data), `"toc":[{"level":2,"text":"Spaces","anchor":"spaces"}`)
+
+	body := "No headings"
+	page, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: page.ID, Body: &body})
+	assert.Nil(err)
+	assert.Empty(page.Content.TOC)
+}
+
+func TestPageFrontMatter(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Docs as Code",
+		Key:    "docs-as-code",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	body := strings.Join([]string{
+		"---",
+		"title: Install Guide",
+		"short_title: Install",
+		"status: Published",
+		"tags: [setup, linux]",
+		"description: Install the server on linux",
+		"slug: install",
+		"owner: ops",
+		"---",
+		"## Requirements",
+	}, "\n")
+
+	page, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Body:    body,
+	})
+	assert.Nil(err)
+	assert.Equal("Install Guide", page.Content.Title)
+	assert.Equal("Install", page.Content.ShortTitle)
+	assert.Equal(models.PageStatusPublished, page.Content.Status)
+	assert.Equal([]string{"setup", "linux"}, page.Content.Tags)
+	assert.Equal("Install the server on linux", page.Content.Description)
+	assert.Equal(map[string]interface{}{"owner": "ops"}, page.Content.Meta)
+	assert.Equal("install", page.Slug)
+	assert.Equal(body, page.Content.Body)
+	assert.Equal(`<h2 id="requirements">Requirements</h2>`+"\n", page.Content.HTML)
+
+	// the params override the front matter
+	page, err = spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusDraft,
+		Title:   "Setup",
+		Body:    body,
+	})
+	assert.Nil(err)
+	assert.Equal("Setup", page.Content.Title)
+	assert.Equal(models.PageStatusDraft, page.Content.Status)
+
+	// the front matter fills the empty update params
+	body = "---\ntitle: Setup Guide\nstatus: deprecated\n---\nDeprecated"
+	page, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: page.ID, Body: &body})
+	assert.Nil(err)
+	assert.Equal("Setup Guide", page.Content.Title)
+	assert.Equal(models.PageStatusDeprecated, page.Content.Status)
+	assert.Equal([]string{"setup", "linux"}, page.Content.Tags)
+	assert.Empty(page.Content.Meta)
+
+	body = "---\nstatus: unknown\n---\n"
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: page.ID, Body: &body})
+	assert.ErrorIs(err, models.ErrPageStatusIsInvalid)
+
+	body = "---\ntitle: [\n---\n"
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: page.ID, Body: &body})
+	assert.ErrorIs(err, markdown.ErrFrontMatterIsInvalid)
+}
+
+func TestPageIncludes(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Snippets",
+		Key:    "snippets",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	snippet, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Shared",
+		Body:    "## Prerequisites\n\n- Go 1.20\n\n## Other\n\nOther section",
+	})
+	assert.Nil(err)
+
+	// the section under the heading
+	guide, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Guide",
+		Body:    fmt.Sprintf("# Guide\n\n{{< include page=%d section=\"prerequisites\" >}}\n\nRun it.", snippet.ID),
+	})
+	assert.Nil(err)
+	assert.Equal("<h1 id=\"guide\">Guide</h1>\n<ul>\n<li>Go 1.20</li>\n</ul>\n<p>Run it.</p>\n", guide.Content.HTML)
+
+	// the nested includes
+	overview, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Overview",
+		Body:    fmt.Sprintf("{{< include page=%d >}}", guide.ID),
+	})
+	assert.Nil(err)
+	assert.Contains(overview.Content.HTML, "<li>Go 1.20</li>")
+
+	// the pages including the updated page are rendered again, through the nested includes too
+	body := "## Prerequisites\n\n- Go 1.21\n"
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: snippet.ID, Body: &body})
+	assert.Nil(err)
+
+	for _, id := range []int64{guide.ID, overview.ID} {
+		page, err := spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: id})
+		assert.Nil(err)
+		assert.Contains(page.Content.HTML, "<li>Go 1.21</li>")
+		assert.NotContains(page.Content.HTML, "Go 1.20")
+	}
+
+	// cycles, the missing pages and sections are rendered as warnings
+	body = fmt.Sprintf("{{< include page=%d >}}\n\n{{< include page=%d section=\"install\" >}}\n\n{{< include page=999999 >}}", overview.ID, guide.ID)
+	page, err := spacer.UpdatePage(ctx, &params.UpdatePage{ID: snippet.ID, Body: &body})
+	assert.Nil(err)
+	assert.Contains(page.Content.HTML, fmt.Sprintf("page %d is included recursively", snippet.ID))
+	assert.Contains(page.Content.HTML, fmt.Sprintf("section “install” is not found in page %d", guide.ID))
+	assert.Contains(page.Content.HTML, "page 999999 is not found in the space")
+
+	// the restricted pages are included only into the same restricted subtree
+	secret, err := spacer.CreatePage(ctx, &params.CreatePage{SpaceID: space.ID, Status: models.PageStatusPublished, Title: "Secret", Body: "Secret token"})
+	assert.Nil(err)
+
+	_, err = spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: secret.ID, Role: models.MemberRoleEditor})
+	assert.Nil(err)
+
+	leak, err := spacer.CreatePage(ctx, &params.CreatePage{SpaceID: space.ID, Status: models.PageStatusPublished, Title: "Leak", Body: fmt.Sprintf("{{< include page=%d >}}", secret.ID)})
+	assert.Nil(err)
+	assert.Contains(leak.Content.HTML, fmt.Sprintf("page %d is restricted", secret.ID))
+	assert.NotContains(leak.Content.HTML, "Secret token")
+
+	child, err := spacer.CreatePage(ctx, &params.CreatePage{SpaceID: space.ID, ParentID: secret.ID, Status: models.PageStatusPublished, Title: "Child", Body: fmt.Sprintf("{{< include page=%d >}}", secret.ID)})
+	assert.Nil(err)
+	assert.Contains(child.Content.HTML, "Secret token")
+
+	// the pages including the restricted subtree are rendered again on the restriction changes
+	restriction, err := spacer.CreatePageRestriction(ctx, &params.CreatePageRestriction{SpaceID: space.ID, PageID: guide.ID, Role: models.MemberRoleEditor})
+	assert.Nil(err)
+
+	page, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: overview.ID})
+	assert.Nil(err)
+	assert.Contains(page.Content.HTML, fmt.Sprintf("page %d is restricted", guide.ID))
+	assert.NotContains(page.Content.HTML, "<h1 id=\"guide\">Guide</h1>")
+
+	err = spacer.DeletePageRestriction(ctx, &params.DeletePageRestriction{SpaceID: space.ID, PageID: guide.ID, ID: restriction.ID})
+	assert.Nil(err)
+
+	page, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: overview.ID})
+	assert.Nil(err)
+	assert.Contains(page.Content.HTML, "<h1 id=\"guide\">Guide</h1>")
+
+	// the drafts are not included
+	draft, err := spacer.CreatePage(ctx, &params.CreatePage{SpaceID: space.ID, Status: models.PageStatusDraft, Title: "Draft", Body: "Draft token"})
+	assert.Nil(err)
+
+	page, err = spacer.CreatePage(ctx, &params.CreatePage{SpaceID: space.ID, Status: models.PageStatusPublished, Title: "Drafts", Body: fmt.Sprintf("{{< include page=%d >}}", draft.ID)})
+	assert.Nil(err)
+	assert.Contains(page.Content.HTML, fmt.Sprintf("page %d has no content", draft.ID))
+	assert.NotContains(page.Content.HTML, "Draft token")
+}
+
+// diagramRenderer the diagram renderer recording the renders
+type diagramRenderer struct {
+	cached  map[string]string
+	renders []string
+}
+
+func (r *diagramRenderer) Render(kind, source string) (string, error) {
+	if url, err := r.Cached(kind, source); err == nil {
+		return url, nil
+	}
+	r.renders = append(r.renders, source)
+	r.cached[source] = fmt.Sprintf("/diagrams/%d.svg", len(r.renders))
+	return r.cached[source], nil
+}
+
+func (r *diagramRenderer) Cached(kind, source string) (string, error) {
+	if url, ok := r.cached[source]; ok {
+		return url, nil
+	}
+	return "", storage.ErrNotFound
+}
+
+func TestDiagramRenders(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	renderer := &diagramRenderer{cached: map[string]string{}}
+	spacer.(*service).DiagramRenderer = renderer
+	defer func() { spacer.(*service).DiagramRenderer = nil }()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Diagrams",
+		Key:    "diagrams",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	shared, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Shared",
+		Body:    "```dot\ndigraph { a -> b }\n```",
+	})
+	assert.Nil(err)
+	assert.Contains(shared.Content.HTML, "/diagrams/1.svg")
+
+	guide, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Guide",
+		Body:    fmt.Sprintf("{{< include page=%d >}}", shared.ID),
+	})
+	assert.Nil(err)
+	assert.Contains(guide.Content.HTML, "/diagrams/1.svg")
+
+	// the diagrams are rendered before the transaction, the includers use the cached diagrams
+	body := "```dot\ndigraph { a -> c }\n```"
+	_, err = spacer.UpdatePage(ctx, &params.UpdatePage{ID: shared.ID, Body: &body})
+	assert.Nil(err)
+	assert.Equal([]string{"digraph { a -> b }\n", "digraph { a -> c }\n"}, renderer.renders)
+
+	page, err := spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: guide.ID})
+	assert.Nil(err)
+	assert.Contains(page.Content.HTML, "/diagrams/2.svg")
+
+	// the diagrams not cached are rendered by the website script in the transactions
+	renderer.cached = map[string]string{}
+
+	err = spacer.(*service).Database.Transaction(func(tx *gorm.DB) error {
+		return spacer.(*service).renderIncluders(tx, shared.ID)
+	})
+	assert.Nil(err)
+	assert.Len(renderer.renders, 2)
+
+	page, err = spacer.DescribePage(ctx, &params.DescribePage{SpaceID: space.ID, PageID: guide.ID})
+	assert.Nil(err)
+	assert.Contains(page.Content.HTML, "diagram-source")
+}
+
+func TestVariables(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+
+	space, err := spacer.CreateSpace(ctx, &params.CreateSpace{
+		Name:   "Variables",
+		Key:    "variables",
+		Status: models.SpaceStatusOnline,
+		Lang:   "en-US",
+	})
+	assert.Nil(err)
+
+	_, err = spacer.CreateVariable(ctx, &params.CreateVariable{SpaceID: space.ID, Name: "latest-sdk", Value: "1.2.0"})
+	assert.Equal(ErrVariableNameIsInvalid, err)
+
+	_, err = spacer.CreateVariable(ctx, &params.CreateVariable{SpaceID: space.ID, Name: "latest_sdk_version", Value: "1.2.0\n# heading"})
+	assert.Equal(ErrVariableValueIsInvalid, err)
+
+	sdk, err := spacer.CreateVariable(ctx, &params.CreateVariable{SpaceID: space.ID, Name: "latest_sdk_version", Value: "1.2.0"})
+	assert.Nil(err)
+
+	_, err = spacer.CreateVariable(ctx, &params.CreateVariable{SpaceID: space.ID, Name: "product_name", Value: "Space"})
+	assert.Nil(err)
+
+	// the references in the text and the code blocks are substituted
+	install, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Install",
+		Body:    "Install {{ var \"product_name\" }} SDK\n\n```sh\npip install sdk=={{ var \"latest_sdk_version\" }}\n```",
+	})
+	assert.Nil(err)
+	assert.Contains(install.Content.HTML, "Install Space SDK")
+	assert.Contains(install.Content.HTML, "1.2.0")
+
+	guide, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Title:   "Guide",
+		Body:    fmt.Sprintf("{{< include page=%d >}}", install.ID),
+	})
+	assert.Nil(err)
+	assert.Contains(guide.Content.HTML, "1.2.0")
+
+	legacy, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Version: "v1",
+		Title:   "Legacy",
+		Body:    "SDK {{ var \"latest_sdk_version\" }}",
+	})
+	assert.Nil(err)
+	assert.Equal("<p>SDK 1.2.0</p>\n", legacy.Content.HTML)
+
+	describe := func(page *models.Page, version string) string {
+		var content *models.PageContent
+		err := spacer.(*service).Database.Where("`page_id` = ? AND `version` = ?", page.ID, version).First(&content).Error
+		assert.Nil(err)
+		return content.HTML
+	}
+
+	// the variable of the version overrides the variable of the space
+	v1, err := spacer.CreateVariable(ctx, &params.CreateVariable{SpaceID: space.ID, Version: "v1", Name: "latest_sdk_version", Value: "1.0.0"})
+	assert.Nil(err)
+	assert.Equal("<p>SDK 1.0.0</p>\n", describe(legacy, "v1"))
+	assert.Contains(describe(install, ""), "1.2.0")
+
+	// the previews resolve the variables and the includes as on save
+	preview, err := spacer.PreviewMarkdown(ctx, &params.PreviewMarkdown{SpaceID: space.ID, Version: "v1", Body: "SDK {{ var \"latest_sdk_version\" }}"})
+	assert.Nil(err)
+	assert.Equal("<p>SDK 1.0.0</p>\n", preview)
+
+	preview, err = spacer.PreviewMarkdown(ctx, &params.PreviewMarkdown{SpaceID: space.ID, PageID: guide.ID, Body: fmt.Sprintf("{{< include page=%d >}}", install.ID)})
+	assert.Nil(err)
+	assert.Contains(preview, "Install Space SDK")
+
+	// the referencing pages and the pages including them are rendered again
+	value := "1.3.0"
+	_, err = spacer.UpdateVariable(ctx, &params.UpdateVariable{ID: sdk.ID, SpaceID: space.ID, Value: &value})
+	assert.Nil(err)
+	assert.Contains(describe(install, ""), "1.3.0")
+	assert.Contains(describe(guide, ""), "1.3.0")
+	assert.Equal("<p>SDK 1.0.0</p>\n", describe(legacy, "v1"))
+
+	err = spacer.DeleteVariable(ctx, &params.DeleteVariable{ID: v1.ID, SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Equal("<p>SDK 1.3.0</p>\n", describe(legacy, "v1"))
+
+	variables, err := spacer.DescribeVariables(ctx, &params.DescribeVariables{SpaceID: space.ID})
+	assert.Nil(err)
+	assert.Len(variables, 2)
+
+	_, err = spacer.CreateVariable(ctx, &params.CreateVariable{SpaceID: space.ID, Name: "product_name", Value: "Space"})
+	assert.ErrorIs(err, ErrVariableNameIsTaken)
+
+	name := "product_name"
+	_, err = spacer.UpdateVariable(ctx, &params.UpdateVariable{ID: sdk.ID, SpaceID: space.ID, Name: &name})
+	assert.ErrorIs(err, ErrVariableNameIsTaken)
+
+	// the version pages including the default version contents follow the variables of the version
+	upgrade, err := spacer.CreatePage(ctx, &params.CreatePage{
+		SpaceID: space.ID,
+		Status:  models.PageStatusPublished,
+		Version: "v2",
+		Title:   "Upgrade",
+		Body:    fmt.Sprintf("{{< include page=%d >}}", install.ID),
+	})
+	assert.Nil(err)
+	assert.Contains(upgrade.Content.HTML, "1.3.0")
+
+	_, err = spacer.CreateVariable(ctx, &params.CreateVariable{SpaceID: space.ID, Version: "v2", Name: "latest_sdk_version", Value: "2.0.0"})
+	assert.Nil(err)
+	assert.Contains(describe(upgrade, "v2"), "2.0.0")
+	assert.Contains(describe(install, ""), "1.3.0")
+}
@@ -0,0 +1,182 @@
+package spaces
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+)
+
+// slugMaxLength max runes of a page slug
+const slugMaxLength = 128
+
+// pathConflictRetries the retries of the transactions whose page path is taken by a concurrent transaction
+const pathConflictRetries = 3
+
+// slugify convert title to url slug, e.g. `Getting Started` => `getting-started`
+func slugify(title string) string {
+
+	var (
+		builder strings.Builder
+		dash    bool
+	)
+
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+			dash = false
+			continue
+		}
+		if builder.Len() > 0 && !dash {
+			builder.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimRight(builder.String(), "-")
+
+	if utf8.RuneCountInString(slug) > slugMaxLength {
+		slug = strings.TrimRight(string([]rune(slug)[:slugMaxLength]), "-")
+	}
+
+	if slug == "" {
+		return "page"
+	}
+
+	// numeric paths are reserved for page ids
+	if strings.IndexFunc(slug, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		slug = "page-" + slug
+	}
+
+	return slug
+}
+
+// joinPath join parent path and slug to page path
+func joinPath(parent, slug string) string {
+	if parent == "" || slug == "" {
+		return slug
+	}
+	return parent + "/" + slug
+}
+
+// parentPath return the path of the page parent
+func parentPath(tx *gorm.DB, page *models.Page) (string, error) {
+	if !page.ParentID.Valid || page.ParentID.Int64 == 0 {
+		return "", nil
+	}
+
+	var parent *models.Page
+	if err := tx.Select("id", "path").Where("`id` = ?", page.ParentID.Int64).First(&parent).Error; err != nil {
+		return "", err
+	}
+
+	return parent.Path, nil
+}
+
+// uniqueSlug return a slug whose path is unique in the space, e.g. `install`, `install-2`
+func uniqueSlug(tx *gorm.DB, page *models.Page, parent, slug string) (string, error) {
+
+	candidate := slug
+
+	for i := 2; ; i++ {
+		var count int64
+
+		err := tx.Model(&models.Page{}).
+			Where("`space_id` = ? AND `path` = ? AND `id` <> ?", page.SpaceID, joinPath(parent, candidate), page.ID).
+			Count(&count).Error
+		if err != nil {
+			return "", err
+		}
+
+		if count == 0 {
+			return candidate, nil
+		}
+
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
+
+// isUniqueViolation return the error is the violation of a unique index, e.g. the unique page paths
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "unique") || strings.Contains(message, "duplicate")
+}
+
+// retryPathConflict run the transaction again if the unique slug is taken by a concurrent transaction
+// between the check and the insert, the unique index of the page paths rejects the later one
+func retryPathConflict(db *gorm.DB, transaction func(tx *gorm.DB) error) error {
+	for i := 0; ; i++ {
+		err := db.Transaction(transaction)
+		if err == nil || i >= pathConflictRetries || !isUniqueViolation(err) {
+			return err
+		}
+	}
+}
+
+// setSlug set page slug and path, the slug is made unique in the space
+func setSlug(tx *gorm.DB, page *models.Page, slug string) error {
+
+	parent, err := parentPath(tx, page)
+	if err != nil {
+		return err
+	}
+
+	slug, err = uniqueSlug(tx, page, parent, slugify(slug))
+	if err != nil {
+		return err
+	}
+
+	page.Slug = slug
+	page.Path = joinPath(parent, slug)
+
+	return nil
+}
+
+// updatePaths recompute path of the page descendants after the page path changed,
+// the previous paths are kept as redirects
+func updatePaths(tx *gorm.DB, page *models.Page) error {
+
+	var pages []*models.Page
+
+	err := tx.Select("id", "parent_id", "slug", "path").
+		Where("`space_id` = ? AND `lft` > ? AND `rgt` < ?", page.SpaceID, page.Lft, page.Rgt).
+		Order("`lft` ASC").
+		Find(&pages).Error
+	if err != nil {
+		return err
+	}
+
+	paths := map[int64]string{page.ID: page.Path}
+
+	for _, node := range pages {
+		path := ""
+		if node.Slug != "" {
+			path = joinPath(paths[node.ParentID.Int64], node.Slug)
+		}
+		paths[node.ID] = path
+
+		if path == node.Path {
+			continue
+		}
+
+		previous := node.Path
+
+		if err := tx.Model(node).UpdateColumn("path", path).Error; err != nil {
+			return err
+		}
+
+		if err := recordRedirect(tx, page.SpaceID, previous, node.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package spaces
+
+import (
+	"context"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// stats defaults
+const (
+	statsDays      = 90
+	statsStaleDays = 180
+	statsLimit     = 10
+	statsMaxLimit  = 100
+)
+
+// countWords count words of the text, each CJK character counts as a word
+func countWords(text string) int64 {
+
+	var (
+		count  int64
+		inWord bool
+	)
+
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			count++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				count++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+
+	return count
+}
+
+// statsCounts count the space page contents group by the column
+func statsCounts(db *gorm.DB, spaceID int64, column string) ([]*models.StatsCount, error) {
+
+	var counts []*models.StatsCount
+
+	err := db.Model(&models.PageContent{}).
+		Select("`"+column+"` AS `name`, COUNT(*) AS `count`").
+		Where("`space_id` = ?", spaceID).
+		Group(column).
+		Order("`count` DESC, `name` ASC").
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// lastContents return the last updated content of each page of the contents query,
+// the pages are ordered by their last update, e.g. `DESC` for the recently updated pages
+func lastContents(db, query *gorm.DB, order string, limit int) ([]*models.PageContent, error) {
+
+	var rows []struct {
+		PageID        int64
+		LastUpdatedAt int64
+	}
+
+	err := query.Model(&models.PageContent{}).
+		Select("`page_id`, MAX(`updated_at`) AS `last_updated_at`").
+		Group("page_id").
+		Order("`last_updated_at` " + order + ", `page_id` " + order).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	var (
+		ids      = make([]int64, 0, len(rows))
+		contents []*models.PageContent
+	)
+
+	for _, row := range rows {
+		ids = append(ids, row.PageID)
+	}
+
+	if err := db.Omit("body", "html").Where("`page_id` IN ?", ids).Order("`updated_at` DESC, `id` DESC").Find(&contents).Error; err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int64]*models.PageContent, len(rows))
+	for _, content := range contents {
+		if _, exists := latest[content.PageID]; !exists {
+			latest[content.PageID] = content
+		}
+	}
+
+	result := make([]*models.PageContent, 0, len(rows))
+	for _, row := range rows {
+		if content, exists := latest[row.PageID]; exists {
+			result = append(result, content)
+		}
+	}
+
+	return result, nil
+}
+
+// contentPages return the pages of the contents, the page content is the listed content
+func contentPages(db *gorm.DB, contents []*models.PageContent) ([]*models.Page, error) {
+
+	var (
+		ids   = make([]int64, 0, len(contents))
+		pages []*models.Page
+	)
+
+	for _, content := range contents {
+		ids = append(ids, content.PageID)
+	}
+
+	if len(ids) > 0 {
+		if err := db.Where("`id` IN ?", ids).Find(&pages).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	index := make(map[int64]*models.Page, len(pages))
+	for _, page := range pages {
+		index[page.ID] = page
+	}
+
+	result := make([]*models.Page, 0, len(contents))
+	for _, content := range contents {
+		if page, exists := index[content.PageID]; exists {
+			p := *page
+			p.Content = content
+			result = append(result, &p)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *service) DescribeSpaceStats(ctx context.Context, params *params.DescribeSpaceStats) (*models.SpaceStats, error) {
+
+	var (
+		database  = s.Database.WithContext(ctx)
+		now       = time.Now()
+		days      = params.Days
+		staleDays = params.StaleDays
+		limit     = params.Limit
+	)
+
+	if days <= 0 {
+		days = statsDays
+	}
+	if staleDays <= 0 {
+		staleDays = statsStaleDays
+	}
+	if limit <= 0 {
+		limit = statsLimit
+	}
+	if limit > statsMaxLimit {
+		limit = statsMaxLimit
+	}
+
+	stats := &models.SpaceStats{
+		SpaceID:     params.SpaceID,
+		Since:       now.AddDate(0, 0, -days).Unix(),
+		StaleBefore: now.AddDate(0, 0, -staleDays).Unix(),
+	}
+
+	// the restricted pages not readable by the viewer are not counted
+	denied, err := s.deniedPages(ctx, params.SpaceID, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := func() *gorm.DB {
+		if len(denied) == 0 {
+			return database
+		}
+		return database.Where("`page_id` NOT IN (?)", s.Database.Model(&models.Page{}).Select("id").Where(subtrees(denied)))
+	}
+
+	if err := excludeSubtrees(database.Model(&models.Page{}), denied).Where("`space_id` = ?", params.SpaceID).Count(&stats.Pages).Error; err != nil {
+		return nil, err
+	}
+
+	if err := readable().Model(&models.PageContent{}).Where("`space_id` = ?", params.SpaceID).Count(&stats.Contents).Error; err != nil {
+		return nil, err
+	}
+
+	if stats.Statuses, err = statsCounts(readable(), params.SpaceID, "status"); err != nil {
+		return nil, err
+	}
+	if stats.Langs, err = statsCounts(readable(), params.SpaceID, "lang"); err != nil {
+		return nil, err
+	}
+	if stats.Versions, err = statsCounts(readable(), params.SpaceID, "version"); err != nil {
+		return nil, err
+	}
+
+	// words of the rendered contents
+	var batch []*models.PageContent
+
+	err = readable().Select("id", "html").
+		Where("`space_id` = ?", params.SpaceID).
+		FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+			for _, content := range batch {
+				stats.Words += countWords(content.Text())
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// recently updated pages
+	recent, err := lastContents(database, readable().Where("`space_id` = ?", params.SpaceID), "DESC", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.RecentlyUpdated, err = contentPages(database, recent); err != nil {
+		return nil, err
+	}
+
+	// stale pages, none of the page contents is updated since stale before
+	stale, err := lastContents(database, readable().
+		Where("`space_id` = ? AND `updated_at` < ?", params.SpaceID, stats.StaleBefore).
+		Where("`page_id` NOT IN (?)", database.Model(&models.PageContent{}).
+			Select("page_id").
+			Where("`space_id` = ? AND `updated_at` >= ?", params.SpaceID, stats.StaleBefore)), "ASC", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.StalePages, err = contentPages(database, stale); err != nil {
+		return nil, err
+	}
+
+	// top contributors in the window
+	err = readable().Model(&models.PageContent{}).
+		Select("`creator_id` AS `account_id`, COUNT(*) AS `contents`, MAX(`updated_at`) AS `last_updated_at`").
+		Where("`space_id` = ? AND `creator_id` > 0 AND `updated_at` >= ?", params.SpaceID, stats.Since).
+		Group("creator_id").
+		Order("`contents` DESC, `last_updated_at` DESC").
+		Limit(limit).
+		Scan(&stats.Contributors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stats.Contributors) > 0 {
+		var (
+			ids      = make([]int64, 0, len(stats.Contributors))
+			accounts []*models.Account
+		)
+
+		for _, contributor := range stats.Contributors {
+			ids = append(ids, contributor.AccountID)
+		}
+
+		if err := database.Where("`id` IN ?", ids).Find(&accounts).Error; err != nil {
+			return nil, err
+		}
+
+		index := make(map[int64]*models.Account, len(accounts))
+		for _, account := range accounts {
+			index[account.ID] = account
+		}
+
+		for _, contributor := range stats.Contributors {
+			contributor.Account = index[contributor.AccountID]
+		}
+	}
+
+	return stats, nil
+}
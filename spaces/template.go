@@ -0,0 +1,227 @@
+package spaces
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+
+	"github.com/miclle/space/models"
+)
+
+var (
+	// ErrSpaceTemplateNotFound space template does not exist
+	ErrSpaceTemplateNotFound = errors.New("space template not found")
+
+	// ErrSpaceTemplateIsInvalid space is created from either an existing space or a packaged template
+	ErrSpaceTemplateIsInvalid = errors.New("space template is invalid")
+)
+
+// templates packaged space templates, a directory with `template.yaml` manifest and the markdown files per template
+//
+//go:embed templates
+var templates embed.FS
+
+// templateManifest space template manifest
+type templateManifest struct {
+	models.SpaceTemplate `yaml:",inline"`
+
+	Homepage *templatePage   `yaml:"homepage"`
+	Pages    []*templatePage `yaml:"pages"`
+}
+
+// templatePage space template page, the body is read from the markdown file relative to the manifest
+type templatePage struct {
+	Title        string                      `yaml:"title"`
+	ShortTitle   string                      `yaml:"short_title"`
+	Slug         string                      `yaml:"slug"`
+	File         string                      `yaml:"file"`
+	Translations map[string]*templateContent `yaml:"translations"` // contents of the other languages
+	Children     []*templatePage             `yaml:"children"`
+}
+
+// templateContent space template page content of a language
+type templateContent struct {
+	Title      string `yaml:"title"`
+	ShortTitle string `yaml:"short_title"`
+	File       string `yaml:"file"`
+}
+
+// loadTemplate read and parse the packaged template manifest
+func loadTemplate(name string) (*templateManifest, error) {
+
+	if name == "" || strings.Contains(name, "/") || !fs.ValidPath(name) {
+		return nil, ErrSpaceTemplateNotFound
+	}
+
+	data, err := templates.ReadFile(path.Join("templates", name, "template.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrSpaceTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest *templateManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	manifest.Name = name
+
+	return manifest, nil
+}
+
+// templatePages build the template page tree in nested set order, the pages have sequence ids,
+// the links between the template pages are written as the space with the template name, e.g. `/docs/microservice/operations`
+func templatePages(manifest *templateManifest, title string) (*models.Space, []*models.Page, []*models.PageContent, error) {
+
+	var (
+		from = &models.Space{
+			Key:          manifest.Name,
+			Multilingual: manifest.Multilingual,
+			Lang:         manifest.Lang,
+			FallbackLang: manifest.FallbackLang,
+			Visibility:   manifest.Visibility,
+		}
+		pages    []*models.Page
+		contents []*models.PageContent
+		add      func(node *templatePage, parent *models.Page) error
+	)
+
+	read := func(file string) (string, error) {
+		if file == "" {
+			return "", nil
+		}
+		data, err := templates.ReadFile(path.Join("templates", manifest.Name, file))
+		return string(data), err
+	}
+
+	add = func(node *templatePage, parent *models.Page) error {
+
+		page := &models.Page{
+			ID:   int64(len(pages) + 1),
+			Slug: node.Slug,
+		}
+
+		slug := node.Slug
+		if slug == "" {
+			slug = node.Title
+		}
+
+		if parent != nil {
+			page.ParentID.Int64, page.ParentID.Valid = parent.ID, true
+			page.Path = joinPath(parent.Path, slugify(slug))
+		} else if node != manifest.Homepage {
+			page.Path = slugify(slug)
+		}
+
+		pages = append(pages, page)
+
+		body, err := read(node.File)
+		if err != nil {
+			return err
+		}
+
+		content := &models.PageContent{
+			PageID:     page.ID,
+			Lang:       manifest.Lang,
+			Status:     models.PageStatusPublished,
+			Title:      node.Title,
+			ShortTitle: node.ShortTitle,
+			Body:       body,
+		}
+
+		// the homepage is titled with the space name
+		if node == manifest.Homepage && content.Title == "" {
+			content.Title = title
+		}
+
+		contents = append(contents, content)
+
+		// the languages in a stable order
+		langs := make([]string, 0, len(node.Translations))
+		for lang := range node.Translations {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+
+		for _, lang := range langs {
+			translation := node.Translations[lang]
+
+			body, err := read(translation.File)
+			if err != nil {
+				return err
+			}
+
+			contents = append(contents, &models.PageContent{
+				PageID:     page.ID,
+				Lang:       lang,
+				Status:     models.PageStatusPublished,
+				Title:      lo.Ternary(translation.Title == "", content.Title, translation.Title),
+				ShortTitle: translation.ShortTitle,
+				Body:       body,
+			})
+		}
+
+		for _, child := range node.Children {
+			if err := add(child, page); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if manifest.Homepage != nil {
+		if err := add(manifest.Homepage, nil); err != nil {
+			return nil, nil, nil, err
+		}
+		from.HomepageID = pages[0].ID
+	}
+
+	for _, node := range manifest.Pages {
+		if err := add(node, nil); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	for _, content := range contents {
+		if content.ShortTitle == "" {
+			content.ShortTitle = content.Title
+		}
+	}
+
+	return from, pages, contents, nil
+}
+
+func (s *service) DescribeSpaceTemplates(ctx context.Context) ([]*models.SpaceTemplate, error) {
+
+	entries, err := templates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.SpaceTemplate, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manifest, err := loadTemplate(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, &manifest.SpaceTemplate)
+	}
+
+	return list, nil
+}
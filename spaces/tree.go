@@ -0,0 +1,175 @@
+package spaces
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+// page tree limits of a query
+const (
+	pageTreeDefaultLimit = 100
+	pageTreeMaxLimit     = 1000
+)
+
+func (s *service) DescribePageTree(ctx context.Context, params *params.DescribePageTree) (*models.PageCursor, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		space    *models.Space
+		pages    models.Pages
+		cursor   = &models.PageCursor{Items: models.Pages{}}
+	)
+
+	if err := database.Where("`id` = ?", params.SpaceID).First(&space).Error; err != nil {
+		return nil, err
+	}
+
+	lang := params.Lang
+	if lang == "" {
+		lang = space.Lang
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = pageTreeDefaultLimit
+	}
+	if limit > pageTreeMaxLimit {
+		limit = pageTreeMaxLimit
+	}
+
+	db := database.Joins("Content", database.Omit("body", "html").Where(&models.PageContent{Lang: lang}))
+
+	if len(space.FallbackLang) > 0 && lang != space.FallbackLang {
+		db = db.Joins("FallbackContent", database.Omit("body", "html").Where(&models.PageContent{Lang: space.FallbackLang}))
+	}
+
+	denied, err := s.deniedPages(ctx, space.ID, params.Viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	// the base query is reused by the sibling groups
+	db = excludeSubtrees(db.Where("`space_pages`.`space_id` = ?", space.ID), denied).Session(&gorm.Session{})
+
+	if params.Expand > 0 {
+		return expandPageTree(database, db, space, params, limit)
+	}
+
+	if params.ParentID > 0 {
+		db = db.Where("`space_pages`.`parent_id` = ?", params.ParentID)
+	} else {
+		db = db.Where("`space_pages`.`depth` = 0")
+	}
+
+	pages, next, err := pageTreeSiblings(db, params.After, limit, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, page := range pages {
+		page.Space = space
+	}
+
+	cursor.Items, cursor.Next = pages.Build(), next
+
+	return cursor, nil
+}
+
+// expandPageTree return the root pages and the children of every ancestor of the page, every sibling group
+// is limited as the lazy loaded children, the truncated children have the cursor in their parent
+func expandPageTree(database, db *gorm.DB, space *models.Space, params *params.DescribePageTree, limit int) (*models.PageCursor, error) {
+
+	var (
+		page      *models.Page
+		ancestors []*models.Page
+		pages     models.Pages
+		cursor    = &models.PageCursor{Items: models.Pages{}}
+	)
+
+	if err := database.Where("`space_id` = ? AND `id` = ?", space.ID, params.Expand).First(&page).Error; err != nil {
+		return nil, err
+	}
+
+	err := database.Select("id", "lft").
+		Where("`space_id` = ? AND `lft` <= ? AND `rgt` >= ?", space.ID, page.Lft, page.Rgt).
+		Order("`lft` ASC").
+		Find(&ancestors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// the page on the path of every group is included
+	path := func(i int) *models.Page {
+		if i < len(ancestors) {
+			return ancestors[i]
+		}
+		return nil
+	}
+
+	roots, next, err := pageTreeSiblings(db.Where("`space_pages`.`depth` = 0"), params.After, limit, path(0))
+	if err != nil {
+		return nil, err
+	}
+
+	pages, cursor.Next = append(pages, roots...), next
+
+	nexts := make(map[int64]int, len(ancestors))
+
+	for i, ancestor := range ancestors {
+		children, next, err := pageTreeSiblings(db.Where("`space_pages`.`parent_id` = ?", ancestor.ID), 0, limit, path(i+1))
+		if err != nil {
+			return nil, err
+		}
+
+		pages, nexts[ancestor.ID] = append(pages, children...), next
+	}
+
+	for _, page := range pages {
+		page.Space = space
+		page.ChildrenNext = nexts[page.ID]
+	}
+
+	cursor.Items = pages.Build()
+
+	return cursor, nil
+}
+
+// pageTreeSiblings return the sibling pages after the cursor and the cursor of the next siblings, the limit is
+// raised to include the page on the path, at most to the max limit
+func pageTreeSiblings(db *gorm.DB, after, limit int, path *models.Page) (models.Pages, int, error) {
+
+	var pages models.Pages
+
+	// the count and the find share the conditions
+	db = db.Where("`space_pages`.`lft` > ?", after).Session(&gorm.Session{})
+
+	if path != nil && path.Lft > after {
+		var before int64
+		if err := db.Model(&models.Page{}).Where("`space_pages`.`lft` < ?", path.Lft).Count(&before).Error; err != nil {
+			return nil, 0, err
+		}
+
+		if int(before) >= limit {
+			limit = int(before) + 1
+		}
+		if limit > pageTreeMaxLimit {
+			limit = pageTreeMaxLimit
+		}
+	}
+
+	if err := db.Order("`space_pages`.`lft` ASC").Limit(limit + 1).Find(&pages).Error; err != nil {
+		return nil, 0, err
+	}
+
+	next := 0
+	if len(pages) > limit {
+		pages = pages[:limit]
+		next = pages[limit-1].Lft
+	}
+
+	return pages, next, nil
+}
@@ -0,0 +1,271 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fox-gonic/fox/logger"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/pkg/markdown"
+	"github.com/miclle/space/spaces/params"
+)
+
+// MaxVariableValueLength the length limit of the variable values
+const MaxVariableValueLength = 1024
+
+var (
+	// ErrVariableNameIsInvalid variable name is invalid
+	ErrVariableNameIsInvalid = errors.New("variable name is invalid")
+
+	// ErrVariableValueIsInvalid variable value is invalid
+	ErrVariableValueIsInvalid = errors.New("variable value is invalid")
+
+	// ErrVariableNameIsTaken the name is used by another variable of the space and version
+	ErrVariableNameIsTaken = errors.New("variable name is taken")
+)
+
+// checkVariable validate the name and the value of the variable, the values are single lines,
+// so they can not break the markdown blocks around the references
+func checkVariable(variable *models.Variable) error {
+
+	if len(variable.Name) > 64 || !markdown.VariableNameRegexp.MatchString(variable.Name) {
+		return ErrVariableNameIsInvalid
+	}
+
+	if strings.ContainsAny(variable.Value, "\r\n") || utf8.RuneCountInString(variable.Value) > MaxVariableValueLength {
+		return ErrVariableValueIsInvalid
+	}
+
+	return nil
+}
+
+// spaceVariables return the values of the variables in the version of the space,
+// the variables of the version override the variables of the space
+func spaceVariables(tx *gorm.DB, spaceID int64, version string) (map[string]string, error) {
+
+	var variables []*models.Variable
+
+	err := tx.Where("`space_id` = ? AND `version` IN ?", spaceID, lo.Uniq([]string{"", version})).
+		Order("`version` ASC").
+		Find(&variables).Error
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(variables))
+	for _, variable := range variables {
+		values[variable.Name] = variable.Value
+	}
+
+	return values, nil
+}
+
+// renderVariableReferences render the contents referencing the variables again after the variable change is
+// saved, the contents of other versions are skipped for the variables of a version, the contents including the
+// referencing pages are rendered too, the version contents including the default version contents of a page as
+// well, each page is rendered in its own transaction and the failures are logged
+func (s *service) renderVariableReferences(database *gorm.DB, spaceID int64, version string, names ...string) {
+
+	var (
+		log        = logger.New("Variable")
+		candidates []*models.PageContent
+		pages      []int64
+	)
+
+	db := database.Where("`space_id` = ?", spaceID)
+
+	// the version contents include the default version contents of the pages without the version
+	if version != "" {
+		db = db.Where("`version` IN ?", []string{version, ""})
+	}
+
+	var (
+		conditions = lo.Map(names, func(string, int) string { return "`body` LIKE ? ESCAPE '!'" })
+		args       = lo.Map(names, func(name string, _ int) any { return containsPattern("\"" + name + "\"") })
+	)
+
+	db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+
+	if err := db.Find(&candidates).Error; err != nil {
+		log.Errorf("find the references of variables %v failed, err: %+v", names, err)
+		return
+	}
+
+	for _, content := range candidates {
+		if len(lo.Intersect(markdown.Variables(content.Body), names)) == 0 {
+			continue
+		}
+
+		// the default version contents are rendered in the version by the including pages only
+		pages = append(pages, content.PageID)
+
+		if version != "" && content.Version != version {
+			continue
+		}
+
+		if err := s.renderDiagrams(database, content); err != nil {
+			log.Errorf("render the diagrams of page content %d failed, err: %+v", content.ID, err)
+		}
+
+		err := database.Transaction(func(tx *gorm.DB) error {
+			return s.renderContents(tx, []*models.PageContent{content})
+		})
+		if err != nil {
+			log.Errorf("render page content %d failed, err: %+v", content.ID, err)
+		}
+	}
+
+	for _, page := range lo.Uniq(pages) {
+		err := database.Transaction(func(tx *gorm.DB) error {
+			return s.renderIncluders(tx, page)
+		})
+		if err != nil {
+			log.Errorf("render the pages including page %d failed, err: %+v", page, err)
+		}
+	}
+}
+
+func (s *service) CreateVariable(ctx context.Context, params *params.CreateVariable) (*models.Variable, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		variable = &models.Variable{
+			SpaceID: params.SpaceID,
+			Version: strings.TrimSpace(params.Version),
+			Name:    strings.TrimSpace(params.Name),
+			Value:   params.Value,
+		}
+	)
+
+	if err := checkVariable(variable); err != nil {
+		return nil, err
+	}
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return nil, err
+	}
+
+	if err := database.Create(variable).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrVariableNameIsTaken
+		}
+		return nil, err
+	}
+
+	s.renderVariableReferences(database, variable.SpaceID, variable.Version, variable.Name)
+
+	return variable, nil
+}
+
+func (s *service) DescribeVariables(ctx context.Context, params *params.DescribeVariables) ([]*models.Variable, error) {
+
+	var (
+		database  = s.Database.WithContext(ctx)
+		variables = []*models.Variable{}
+	)
+
+	err := database.Where("`space_id` = ?", params.SpaceID).
+		Order("`version` ASC, `name` ASC").
+		Find(&variables).Error
+
+	return variables, err
+}
+
+func (s *service) UpdateVariable(ctx context.Context, params *params.UpdateVariable) (*models.Variable, error) {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		variable *models.Variable
+	)
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return nil, err
+	}
+
+	err := database.Where("`id` = ? AND `space_id` = ?", params.ID, params.SpaceID).First(&variable).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// the references of the previous name are rendered as unknown variables
+	names := []string{variable.Name}
+
+	if params.Name != nil {
+		variable.Name = strings.TrimSpace(*params.Name)
+		names = lo.Uniq(append(names, variable.Name))
+	}
+
+	if params.Value != nil {
+		variable.Value = *params.Value
+	}
+
+	if err := checkVariable(variable); err != nil {
+		return nil, err
+	}
+
+	if err := database.Save(variable).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrVariableNameIsTaken
+		}
+		return nil, err
+	}
+
+	s.renderVariableReferences(database, variable.SpaceID, variable.Version, names...)
+
+	return variable, nil
+}
+
+func (s *service) DeleteVariable(ctx context.Context, params *params.DeleteVariable) error {
+
+	var (
+		database = s.Database.WithContext(ctx)
+		variable *models.Variable
+	)
+
+	if err := checkSpaceWritable(database, params.SpaceID); err != nil {
+		return err
+	}
+
+	err := database.Where("`id` = ? AND `space_id` = ?", params.ID, params.SpaceID).First(&variable).Error
+	if err != nil {
+		return err
+	}
+
+	if err := database.Delete(variable).Error; err != nil {
+		return err
+	}
+
+	s.renderVariableReferences(database, variable.SpaceID, variable.Version, variable.Name)
+
+	return nil
+}
+
+// copyVariables copy the variables of the space into the target space
+func copyVariables(tx *gorm.DB, from, to *models.Space) error {
+
+	var variables []*models.Variable
+
+	if err := tx.Where("`space_id` = ?", from.ID).Find(&variables).Error; err != nil {
+		return err
+	}
+
+	if len(variables) == 0 {
+		return nil
+	}
+
+	copies := lo.Map(variables, func(variable *models.Variable, _ int) *models.Variable {
+		return &models.Variable{
+			SpaceID: to.ID,
+			Version: variable.Version,
+			Name:    variable.Name,
+			Value:   variable.Value,
+		}
+	})
+
+	return tx.Create(&copies).Error
+}
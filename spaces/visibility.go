@@ -0,0 +1,64 @@
+package spaces
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/miclle/space/models"
+	"github.com/miclle/space/spaces/params"
+)
+
+var (
+	// ErrSpaceAccessDenied space is not visible to the viewer
+	ErrSpaceAccessDenied = errors.New("space access denied")
+)
+
+// memberSpaces return the condition of the spaces the account is a member of
+func memberSpaces(db *gorm.DB, accountID int64) clause.Expr {
+	members := db.Model(&models.Member{}).Select("space_id").Where("`account_id` = ?", accountID)
+	return gorm.Expr("`spaces`.`id` IN (?)", members)
+}
+
+// visibleSpaces return the condition of the spaces visible to the viewer
+func visibleSpaces(db *gorm.DB, viewer *params.Viewer) clause.Expr {
+
+	// the spaces created before visibility are public
+	visibilities := []models.SpaceVisibility{"", models.SpaceVisibilityPublic}
+
+	if viewer.Account() == 0 {
+		return gorm.Expr("`spaces`.`visibility` IN ?", visibilities)
+	}
+
+	visibilities = append(visibilities, models.SpaceVisibilityInternal)
+
+	return gorm.Expr("(`spaces`.`visibility` IN ? OR ?)", visibilities, memberSpaces(db, viewer.Account()))
+}
+
+// checkSpaceVisible return ErrSpaceAccessDenied if the space is not visible to the viewer
+func (s *service) checkSpaceVisible(ctx context.Context, space *models.Space, viewer *params.Viewer) error {
+	if viewer.IsUnrestricted() {
+		return nil
+	}
+
+	var (
+		database = s.Database.WithContext(ctx)
+		count    int64
+	)
+
+	err := database.Model(&models.Space{}).
+		Where("`spaces`.`id` = ?", space.ID).
+		Where(visibleSpaces(s.Database.DB, viewer)).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrSpaceAccessDenied
+	}
+
+	return nil
+}
@@ -47,12 +47,15 @@ var Template *template.Template
 
 // PageData template obj
 type PageData struct {
-	Lang   string
-	Title  string
-	Spaces []*models.Space
-	Space  *models.Space
-	Pages  []*models.Page
-	Page   *models.Page
+	Lang      string
+	Title     string
+	Spaces    []*models.Space
+	Space     *models.Space
+	Pages     []*models.Page
+	PagesNext int // cursor of the root pages not in the page tree
+	Page      *models.Page
+	Settings  *models.SpaceSettings // the space navigation and branding
+	EditURL   string                // the "edit on" link of the page
 }
 
 func init() {
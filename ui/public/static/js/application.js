@@ -17,6 +17,120 @@ function initPagetree() {
   offset && $sidebar.scrollTop(offset.top - 100);
 }
 
+// load the children of a page tree node when it is expanded the first time
+function loadPagetreeNodes() {
+  $(document).on("show.bs.collapse", ".pagetree ul[data-src]", function (e) {
+    if (e.target !== this) {
+      return;
+    }
+
+    var $ul = $(this);
+    var src = $ul.data("src");
+    $ul.removeAttr("data-src");
+
+    $.get(src).done(function (html) {
+      $ul.html(html);
+    }).fail(function () {
+      $ul.attr("data-src", src);
+    });
+  });
+
+  $(document).on("click", ".pagetree .pagetree-more a", function (e) {
+    e.preventDefault();
+
+    var $li = $(this).closest("li");
+    $.get($(this).data("src")).done(function (html) {
+      $li.replaceWith(html);
+    });
+  });
+}
+
+// switch the panels of the `:::tabs` groups, the chosen tab title is remembered across the pages
+function initTabs() {
+  var storageKey = "space.tab";
+  var chosen = null;
+  try {
+    chosen = window.localStorage.getItem(storageKey);
+  } catch (e) {}
+
+  $(".page-body .tabs").each(function () {
+    var $tabs = $(this);
+    var $panels = $tabs.children(".tab-panel");
+    if ($panels.length === 0) {
+      return;
+    }
+
+    var $nav = $('<div class="tabs-nav"></div>');
+    $panels.each(function () {
+      var title = $(this).children(".tab-title").text();
+      $("<button type=\"button\"></button>").text(title).appendTo($nav);
+    });
+
+    $tabs.prepend($nav).addClass("tabs-ready");
+
+    var titles = $panels.children(".tab-title").map(function () { return $(this).text(); }).get();
+    selectTab($tabs, Math.max(titles.indexOf(chosen), 0));
+  });
+
+  $(document).on("click", ".page-body .tabs-nav button", function () {
+    var title = $(this).text();
+    try {
+      window.localStorage.setItem(storageKey, title);
+    } catch (e) {}
+
+    // sync the groups with the same tab
+    $(".page-body .tabs").each(function () {
+      var $titles = $(this).children(".tab-panel").children(".tab-title");
+      var index = $titles.map(function () { return $(this).text(); }).get().indexOf(title);
+      if (index >= 0) {
+        selectTab($(this), index);
+      }
+    });
+  });
+}
+
+function selectTab($tabs, index) {
+  $tabs.children(".tabs-nav").children("button").removeClass("active").eq(index).addClass("active");
+  $tabs.children(".tab-panel").removeClass("active").eq(index).addClass("active");
+}
+
+// render the diagrams that are not pre-rendered on the server, the renderers are loaded only if the page has
+// such diagrams, the plantuml diagrams without server side renderer are shown as the source
+var diagramRenderers = {
+  mermaid: function ($sources) {
+    return import("https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.esm.min.mjs").then(function (module) {
+      var mermaid = module.default;
+      mermaid.initialize({ startOnLoad: false, securityLevel: "strict" });
+      return mermaid.run({ nodes: $sources.get() });
+    });
+  },
+  dot: function ($sources) {
+    return import("https://cdn.jsdelivr.net/npm/@viz-js/viz@3/lib/viz-standalone.mjs").then(function (module) {
+      return module.instance().then(function (viz) {
+        $sources.each(function () {
+          $(this).replaceWith(viz.renderSVGElement($(this).text()));
+        });
+      });
+    });
+  }
+};
+
+function renderDiagrams() {
+  $.each(diagramRenderers, function (kind, render) {
+    var $sources = $(".page-body .diagram-" + kind + " > .diagram-source");
+    if ($sources.length === 0) {
+      return;
+    }
+
+    render($sources).catch(function (err) {
+      console.error("render " + kind + " diagrams failed", err);
+    });
+  });
+}
+
 $(function() {
   initPagetree();
+  loadPagetreeNodes();
+  initTabs();
+  renderDiagrams();
 });
\ No newline at end of file
@@ -21,6 +21,10 @@ const NewSpace = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "
 const Space = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/Detail')));
 const SpaceDashboard = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/Detail/dashboard')));
 const EditSpace = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/Edit')));
+const SpaceMembers = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/Members')));
+const SpaceSettings = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/Settings')));
+const SpaceLinkCheck = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/LinkCheck')));
+const SpaceVariables = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/Variables')));
 const Page = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Spaces/Page')));
 const NewPage = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Pages/New')));
 const EditPage = WaitingComponent(React.lazy(() => import(/* webpackChunkName: "spaces" */ 'pages/Pages/Edit')));
@@ -59,6 +63,10 @@ const App = observer(() => {
                   <Route path="spaces/:key" element={<Space />}>
                     <Route index element={<SpaceDashboard />} />
                     <Route path="setting/profile" element={<EditSpace />} />
+                    <Route path="setting/members" element={<SpaceMembers />} />
+                    <Route path="setting/website" element={<SpaceSettings />} />
+                    <Route path="setting/linkcheck" element={<SpaceLinkCheck />} />
+                    <Route path="setting/variables" element={<SpaceVariables />} />
                     <Route path="pages/:page_id" element={<Page />} />
                     <Route path="pages/new" element={<NewPage />} />
                     <Route path="pages/:page_id/edit" element={<EditPage />} />
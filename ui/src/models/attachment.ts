@@ -0,0 +1,25 @@
+export interface IAttachmentVariant {
+  name:         string // e.g. `320w` or `thumbnail`
+  width:        number
+  height:       number
+  content_type: string
+  size:         number
+}
+
+export interface IAttachment {
+  id:           number
+  page_id:      number
+  creator_id:   number
+  name:         string
+  content_type: string
+  size:         number
+  checksum:     string
+  reference:    string // markdown url, e.g. `attachment:42/diagram.png`
+  url:          string
+  created_at:   number
+  updated_at:   number
+
+  width?:    number
+  height?:   number
+  variants?: IAttachmentVariant[]
+}
@@ -1,3 +1,10 @@
 export * from './account';
 export * from './space';
-export * from './page';
\ No newline at end of file
+export * from './page';
+export * from './redirect';
+export * from './member';
+export * from './restriction';
+export * from './stats';
+export * from './attachment';
+export * from './linkcheck';
+export * from './variable';
@@ -0,0 +1,40 @@
+export enum LinkCheckStatus {
+  pending   = 'pending',
+  running   = 'running',
+  completed = 'completed',
+  failed    = 'failed',
+}
+
+export enum LinkIssueKind {
+  page       = 'page',
+  anchor     = 'anchor',
+  attachment = 'attachment',
+  external   = 'external',
+}
+
+export interface ILinkIssue {
+  page_id:    number
+  content_id: number
+  lang:       string
+  version:    string
+  title:      string
+  kind:       LinkIssueKind
+  link:       string
+  message?:   string
+}
+
+export interface ILinkCheck {
+  id:          number
+  space_id:    number
+  creator_id:  number
+  status:      LinkCheckStatus
+  external:    boolean
+  contents:    number
+  links:       number
+  issues?:     ILinkIssue[]
+  error?:      string
+  started_at:  number
+  finished_at: number
+  created_at:  number
+  updated_at:  number
+}
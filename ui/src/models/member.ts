@@ -0,0 +1,18 @@
+import { IAccount } from './account';
+
+export enum MemberRole {
+  viewer     = 'viewer',
+  editor     = 'editor',
+  maintainer = 'maintainer',
+  owner      = 'owner',
+}
+
+export interface IMember {
+  id:         number
+  account_id: number
+  role:       MemberRole
+  created_at: number
+  updated_at: number
+
+  account?: IAccount
+}
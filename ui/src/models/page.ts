@@ -1,3 +1,5 @@
+import { ISpace } from './space';
+
 export enum PageStatus {
   draft      = 'Draft',
   published  = 'Published',
@@ -7,6 +9,8 @@ export enum PageStatus {
 
 export interface IPage {
   id:               number
+  slug:             string
+  path:             string
   lang:             string
   version:          string
   status:           PageStatus
@@ -14,13 +18,26 @@ export interface IPage {
   short_title:      string
   body:             string
   html:             string
+  toc?:             IHeading[]
+  tags?:            string[]
+  description?:     string
+  meta?:            Record<string, unknown>
 
   created_at: number
   updated_at: number
 
   children_count: number
   children:       IPage[]
+  children_next?: number
   parents?:       IPageParent[]
+
+  space?: ISpace
+}
+
+export interface IHeading {
+  level:  number
+  text:   string
+  anchor: string
 }
 
 export interface IPageParent {
@@ -32,3 +49,8 @@ export interface IPageParent {
   title:          string
   short_title:    string
 }
+
+export interface IPageCursor {
+  items: IPage[]
+  next?: number
+}
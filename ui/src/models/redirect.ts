@@ -0,0 +1,11 @@
+import { IPage } from './page';
+
+export interface IRedirect {
+  id:         number
+  path:       string
+  page_id:    number
+  created_at: number
+  updated_at: number
+
+  page?: IPage
+}
@@ -0,0 +1,12 @@
+import { IAccount } from './account';
+import { MemberRole } from './member';
+
+export interface IPageRestriction {
+  id:         number
+  page_id:    number
+  account_id: number
+  role:       MemberRole | ''
+  created_at: number
+
+  account?: IAccount
+}
@@ -3,6 +3,12 @@ export enum SpaceStatus {
   online  = 'Online',
 }
 
+export enum SpaceVisibility {
+  public   = 'public',
+  internal = 'internal',
+  private  = 'private',
+}
+
 export interface ISpace {
   id:          number
   created_at:  number
@@ -17,4 +23,31 @@ export interface ISpace {
   description:   string
   avatar:        string
   status:        SpaceStatus
+  visibility:    SpaceVisibility
+  archived_at:   number
+  settings?:     ISpaceSettings
+}
+
+export interface ISpaceNavLink {
+  title: string
+  url:   string
+}
+
+export interface ISpaceSettings {
+  nav_links:    ISpaceNavLink[]
+  footer:       string
+  footer_html:  string
+  accent_color: string
+  logo:         string
+  edit_url:     string
+}
+
+export interface ISpaceTemplate {
+  name:          string
+  title:         string
+  description:   string
+  multilingual:  boolean
+  lang:          string
+  fallback_lang: string
+  visibility:    SpaceVisibility
 }
@@ -0,0 +1,32 @@
+import { IAccount } from './account';
+import { IPage } from './page';
+
+export interface IStatsCount {
+  name:  string
+  count: number
+}
+
+export interface ISpaceContributor {
+  account_id:      number
+  account?:        IAccount
+  contents:        number
+  last_updated_at: number
+}
+
+export interface ISpaceStats {
+  space_id: number
+  pages:    number
+  contents: number
+  words:    number
+
+  statuses: IStatsCount[] | null
+  langs:    IStatsCount[] | null
+  versions: IStatsCount[] | null
+
+  recently_updated: IPage[]
+  contributors:     ISpaceContributor[] | null
+  stale_pages:      IPage[]
+
+  since:        number
+  stale_before: number
+}
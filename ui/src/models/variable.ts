@@ -0,0 +1,8 @@
+export interface IVariable {
+  id:         number
+  version:    string
+  name:       string
+  value:      string
+  created_at: number
+  updated_at: number
+}
@@ -36,7 +36,7 @@ const EditPage = observer(() => {
   const handleFormFinish = async (values: Page.IUpdatePageArgs) => {
     Page.update(space.key, page_id, values)
       .then((page: IPage) => {
-        client.prefetchQuery(['spaces.pages', space.key, { lang: page.lang }]);
+        client.invalidateQueries(['spaces.tree', space.key]);
         client.setQueryData(['spaces.pages.get', page_id, { lang: page.lang }], page);
         navigate(`/spaces/${space.key}/pages/${page.id}?lang=${page.lang}`);
         notification.success({ message: 'Page updated successfully' });
@@ -105,13 +105,24 @@ const EditPage = observer(() => {
               </Form.Item>
             }
           </Col>
-          <Col span={6} />
+          <Col span={6}>
+            {
+              page.id !== space.homepage_id &&
+              <Form.Item name="slug" label="Slug" initialValue={page.slug}>
+                <Input placeholder="Generated from the title" />
+              </Form.Item>
+            }
+          </Col>
         </Row>
 
         <Form.Item name="body" rules={[{ required: true }]} initialValue={page.body}>
           <CodeEditor
             lang="markdown"
-            preview={(value) => Markdown.preview(value)}
+            preview={(value) => Markdown.preview(space.key, value, {
+              page_id: page.id,
+              lang: form.getFieldValue('lang') || page.lang,
+              version: page.version,
+            })}
           />
         </Form.Item>
 
@@ -45,7 +45,7 @@ const NewPage = observer(() => {
 
     Page.create(space.key, values)
       .then((page: IPage) => {
-        client.prefetchQuery(['spaces.pages', space.key, { lang: page.lang }]);
+        client.invalidateQueries(['spaces.tree', space.key]);
         navigate(`/spaces/${space.key}/pages/${page.id}?lang=${page.lang}`);
         notification.success({ message: 'Page created successfully' });
       })
@@ -110,7 +110,11 @@ const NewPage = observer(() => {
         <Form.Item name="body" rules={[{ required: true }]}>
           <CodeEditor
             lang="markdown"
-            preview={(value) => Markdown.preview(value)}
+            preview={(value) => Markdown.preview(space.key, value, {
+              parent_id: parentPage?.id,
+              lang: form.getFieldValue('lang'),
+              version: form.getFieldValue('version'),
+            })}
           />
         </Form.Item>
 
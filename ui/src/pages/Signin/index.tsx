@@ -17,6 +17,17 @@ const Signin = observer(() => {
     Account.signin(values.login, values.password)
       .then(() => {
         notification.success({ message: '登录成功' });
+
+        // back to the gated website page, same origin only, browsers resolve `/\evil.com` to another host
+        const redirect = new URLSearchParams(window.location.search).get('redirect');
+        if (redirect && redirect.startsWith('/') && !redirect.includes('\\')) {
+          const url = new URL(redirect, window.location.origin);
+          if (url.origin === window.location.origin) {
+            window.location.href = url.pathname + url.search + url.hash;
+            return;
+          }
+        }
+
         navigate('/');
       })
       .catch((resp: AxiosResponse<IErrorMessage>) => {
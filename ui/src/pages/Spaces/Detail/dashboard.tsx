@@ -1,12 +1,33 @@
 import { observer } from "mobx-react-lite";
+import { Link } from "react-router-dom";
+import { useQuery } from "@tanstack/react-query";
+import dayjs from "dayjs";
+import { Card, Col, List, Row, Skeleton, Statistic, Tag } from "antd";
 import { PageHeader } from '@ant-design/pro-components';
 
+import { IPage, ISpaceStats, IStatsCount } from "models";
+import { Space } from "services";
+
 import { useSpaceContext } from "./store";
 
+const Counts = ({ title, counts }: { title: string, counts: IStatsCount[] | null }) => (
+  <Card size="small" title={title}>
+    {(counts || []).map((count) => <Tag key={count.name}>{count.name || '-'}: {count.count}</Tag>)}
+  </Card>
+)
+
 const Dashboard = observer(() => {
   const store = useSpaceContext();
   const { space } = store;
 
+  const { data: stats, isLoading } = useQuery<ISpaceStats>(['spaces.stats', space.key], () => Space.stats(space.key))
+
+  const renderPage = (page: IPage) => (
+    <List.Item extra={dayjs.unix(page.updated_at).format('YYYY-MM-DD')}>
+      <Link to={`/spaces/${space.key}/pages/${page.id}`}>{page.title}</Link> <Tag>{page.lang}</Tag>
+    </List.Item>
+  )
+
   return (
     <>
       <PageHeader
@@ -14,10 +35,51 @@ const Dashboard = observer(() => {
         title={space.name}
       />
 
-      <h1>Space Dashboard</h1>
-      <p>TODO</p>
+      <Skeleton loading={isLoading} active>
+        {stats &&
+          <>
+            <Row gutter={16}>
+              <Col span={8}><Card size="small"><Statistic title="Pages" value={stats.pages} /></Card></Col>
+              <Col span={8}><Card size="small"><Statistic title="Translations & Versions" value={stats.contents} /></Card></Col>
+              <Col span={8}><Card size="small"><Statistic title="Words" value={stats.words} /></Card></Col>
+            </Row>
+
+            <Row gutter={16} style={{ marginTop: 16 }}>
+              <Col span={8}><Counts title="Status" counts={stats.statuses} /></Col>
+              <Col span={8}><Counts title="Language" counts={stats.langs} /></Col>
+              <Col span={8}><Counts title="Version" counts={stats.versions} /></Col>
+            </Row>
+
+            <Row gutter={16} style={{ marginTop: 16 }}>
+              <Col span={8}>
+                <Card size="small" title="Recently Updated">
+                  <List size="small" dataSource={stats.recently_updated} renderItem={renderPage} />
+                </Card>
+              </Col>
+              <Col span={8}>
+                <Card size="small" title={`Top Contributors since ${dayjs.unix(stats.since).format('YYYY-MM-DD')}`}>
+                  <List
+                    size="small"
+                    dataSource={stats.contributors || []}
+                    renderItem={(contributor) => (
+                      <List.Item extra={contributor.contents}>
+                        {contributor.account?.name || contributor.account?.login || contributor.account_id}
+                      </List.Item>
+                    )}
+                  />
+                </Card>
+              </Col>
+              <Col span={8}>
+                <Card size="small" title={`Not Updated since ${dayjs.unix(stats.stale_before).format('YYYY-MM-DD')}`}>
+                  <List size="small" dataSource={stats.stale_pages} renderItem={renderPage} />
+                </Card>
+              </Col>
+            </Row>
+          </>
+        }
+      </Skeleton>
     </>
   );
 })
 
-export default Dashboard
\ No newline at end of file
+export default Dashboard
@@ -6,15 +6,53 @@ import { StringParam, useQueryParams, withDefault } from "use-query-params";
 import classNames from "classnames";
 import { Avatar, Empty, Layout, Menu, Select, Skeleton, Tree } from "antd";
 import { ItemType } from "antd/es/menu/hooks/useItems";
-import { AiOutlinePlusSquare, AiOutlineSetting } from "react-icons/ai";
+import { AiOutlineDisconnect, AiOutlineFunction, AiOutlineGlobal, AiOutlinePlusSquare, AiOutlineSetting, AiOutlineTeam } from "react-icons/ai";
 import { MdKeyboardArrowDown } from "react-icons/md";
 import { BsBoxSeam } from "react-icons/bs";
 
-import { IPage, ISpace } from "models";
+import { IPage, IPageCursor, ISpace } from "models";
 import { Page } from "services";
 
 import { SpaceStore, SpaceContext } from "./store";
 
+interface ITreeNode extends Omit<IPage, 'children'> {
+  isLeaf:    boolean
+  children?: ITreeNode[]
+  more?:     { parent_id: number, after: number }
+}
+
+// toTreeNodes convert pages to tree nodes, a `more` node loads the next pages of the parent
+function toTreeNodes(pages: IPage[], parentID: number, next?: number): ITreeNode[] {
+  const nodes: ITreeNode[] = pages.map((page) => ({
+    ...page,
+    isLeaf: page.children_count === 0,
+    children: page.children ? toTreeNodes(page.children, page.id, page.children_next) : undefined,
+  }))
+
+  if (next) {
+    nodes.push({ id: `more-${parentID}-${next}`, isLeaf: true, more: { parent_id: parentID, after: next } } as any)
+  }
+
+  return nodes
+}
+
+// setChildren replace the children of the parent node, zero parent id means the root nodes
+function setChildren(nodes: ITreeNode[], parentID: number, update: (children: ITreeNode[]) => ITreeNode[]): ITreeNode[] {
+  if (parentID === 0) {
+    return update(nodes)
+  }
+
+  return nodes.map((node) => {
+    if (node.id === parentID) {
+      return { ...node, children: update(node.children || []) }
+    }
+    if (node.children) {
+      return { ...node, children: setChildren(node.children, parentID, update) }
+    }
+    return node
+  })
+}
+
 const Spaces = observer(() => {
 
   const { key, page_id } = useParams() as { key: string, page_id: string };
@@ -25,6 +63,7 @@ const Spaces = observer(() => {
 
   const [menuItems, setMenuItems] = useState<ItemType[]>([]);
   const [menuSelectedKeys, setMenuSelectedKeys] = useState<string[]>([]);
+  const [treeData, setTreeData] = useState<ITreeNode[]>([]);
 
 
   const [query, setQuery] = useQueryParams({
@@ -37,14 +76,33 @@ const Spaces = observer(() => {
     enabled: key !== ''
   })
 
+  // load the root pages and the pages on the path to the current page, other nodes are loaded on expand
   const {
-    data: pages,
     isLoading: pageTreeIsLoading,
-  } = useQuery<IPage[]>(['spaces.pages', key, query], () => Page.list(key, query), {
+  } = useQuery<IPageCursor>(['spaces.tree', key, query], () => Page.tree(key, { ...query, expand: page_id }), {
     enabled: key !== '',
-    initialData: [],
+    onSuccess: (tree) => setTreeData(toTreeNodes(tree.items, 0, tree.next)),
   })
 
+  const loadChildren = async (node: ITreeNode) => {
+    if (node.children) {
+      return
+    }
+
+    const tree = await Page.tree(key, { ...query, parent_id: node.id })
+    setTreeData((nodes) => setChildren(nodes, node.id, () => toTreeNodes(tree.items, node.id, tree.next)))
+  }
+
+  const loadMore = async (node: ITreeNode) => {
+    const { parent_id, after } = node.more!
+
+    const tree = await Page.tree(key, { ...query, parent_id, after })
+    setTreeData((nodes) => setChildren(nodes, parent_id, (children) => [
+      ...children.filter((child) => child.id !== node.id),
+      ...toTreeNodes(tree.items, parent_id, tree.next),
+    ]))
+  }
+
   useEffect(() => {
     const items: ItemType[] = []
 
@@ -55,6 +113,26 @@ const Spaces = observer(() => {
         icon: <AiOutlineSetting />,
         label: <Link to={`/spaces/${space.key}/setting/profile`}>Space Settings</Link>
       },
+      {
+        key: `/spaces/${space.key}/setting/members`,
+        icon: <AiOutlineTeam />,
+        label: <Link to={`/spaces/${space.key}/setting/members`}>Members</Link>
+      },
+      {
+        key: `/spaces/${space.key}/setting/website`,
+        icon: <AiOutlineGlobal />,
+        label: <Link to={`/spaces/${space.key}/setting/website`}>Website</Link>
+      },
+      {
+        key: `/spaces/${space.key}/setting/linkcheck`,
+        icon: <AiOutlineDisconnect />,
+        label: <Link to={`/spaces/${space.key}/setting/linkcheck`}>Link Check</Link>
+      },
+      {
+        key: `/spaces/${space.key}/setting/variables`,
+        icon: <AiOutlineFunction />,
+        label: <Link to={`/spaces/${space.key}/setting/variables`}>Variables</Link>
+      },
       { type: 'divider' },
       {
         key: `/spaces/${space.key}/pages/new`,
@@ -130,7 +208,8 @@ const Spaces = observer(() => {
                   className="pagetree"
                   showLine={true}
                   fieldNames={{ title: 'title', key: 'id' }}
-                  treeData={pages as any}
+                  treeData={treeData as any}
+                  loadData={(node: any) => loadChildren(node)}
                   selectable={false}
                   expandedKeys={store.expandedKeys}
                   onExpand={(expandedKeys) => store.setExpandedKeys(expandedKeys)}
@@ -140,7 +219,9 @@ const Spaces = observer(() => {
                     </span>
                   }
                   titleRender={(node: any) =>
-                    <Link to={`/spaces/${space.key}/pages/${node.id}`} className={classNames({ current: `${node.id}` === page_id })}>{node.short_title}</Link>
+                    node.more
+                      ? <a onClick={() => loadMore(node)}>More…</a>
+                      : <Link to={`/spaces/${space.key}/pages/${node.id}`} className={classNames({ current: `${node.id}` === page_id })}>{node.short_title}</Link>
                   }
                 />
               </>
@@ -2,7 +2,7 @@ import React from "react";
 import { makeAutoObservable, runInAction } from "mobx";
 import { union } from "lodash";
 
-import { IPage, ISpace } from "models";
+import { IPage, ISpace, ISpaceSettings } from "models";
 import { Space } from "services";
 
 export class SpaceStore {
@@ -25,6 +25,22 @@ export class SpaceStore {
     return info
   }
 
+  async archive(key: string) {
+    const info = await Space.archive(key)
+    runInAction(() => {
+      this.space = info
+    })
+    return info
+  }
+
+  async restore(key: string) {
+    const info = await Space.restore(key)
+    runInAction(() => {
+      this.space = info
+    })
+    return info
+  }
+
   async update(key: string, data: Partial<ISpace>) {
     const info = await Space.update(key, data)
     runInAction(() => {
@@ -33,6 +49,14 @@ export class SpaceStore {
     return info
   }
 
+  async updateSettings(key: string, data: Partial<ISpaceSettings>) {
+    const info = await Space.updateSettings(key, data)
+    runInAction(() => {
+      this.space = info
+    })
+    return info
+  }
+
   setCurrentPage(page: IPage) {
     runInAction(() => {
       this.currentPage = page
@@ -1,12 +1,12 @@
 import { useState } from "react";
 import { observer } from "mobx-react-lite";
-import { Link, useParams } from "react-router-dom";
+import { Link, useNavigate, useParams } from "react-router-dom";
 import { map } from "lodash";
-import { Button, Form, Input, notification, Radio, Select, Space as AntSpace, Switch } from "antd";
+import { Button, Divider, Form, Input, Modal, notification, Popconfirm, Radio, Select, Space as AntSpace, Switch, Typography } from "antd";
 import { PageHeader } from '@ant-design/pro-components';
 
 import { ISpace } from 'models';
-import { AxiosResponse, IErrorMessage } from "services";
+import { AxiosResponse, IErrorMessage, Space } from "services";
 
 import { useSpaceContext } from "../Detail/store";
 
@@ -15,9 +15,14 @@ const EditSpace = observer(() => {
   const { space } = store;
 
   const { key } = useParams() as { key: string };
+  const navigate = useNavigate();
 
   const [form] = Form.useForm();
   const [multilingual, setMultilingual] = useState(space.multilingual);
+  const [cloneOpen, setCloneOpen] = useState(false);
+  const [cloneForm] = Form.useForm();
+  const [newKey, setNewKey] = useState('');
+  const [login, setLogin] = useState('');
 
   const handleFormFinish = async (values: any) => {
 
@@ -36,6 +41,80 @@ const EditSpace = observer(() => {
       })
   }
 
+  const handleArchive = () => {
+    const archived = space.archived_at > 0;
+
+    (archived ? store.restore(key) : store.archive(key))
+      .then(() => {
+        notification.success({ message: archived ? 'Space restored.' : 'Space archived.' });
+      })
+      .catch((resp: AxiosResponse<IErrorMessage>) => {
+        notification.error({
+          key: 'archive-space-error',
+          message: archived ? 'Restore space failure.' : 'Archive space failure.',
+          description: map(resp.data.message, (value, key) => value).join('\n')
+        });
+      })
+  }
+
+  const handleClone = (values: { name: string, key: string }) => {
+    Space.clone(key, values)
+      .then((clone: ISpace) => {
+        setCloneOpen(false);
+        notification.success({ message: 'Space cloned.' });
+        navigate(`/spaces/${clone.key}`);
+      })
+      .catch((resp: AxiosResponse<IErrorMessage>) => {
+        notification.error({
+          key: 'clone-space-error',
+          message: 'Clone space failure.',
+          description: map(resp.data.message, (value, key) => value).join('\n')
+        });
+      })
+  }
+
+  const failure = (message: string) => (resp: AxiosResponse<IErrorMessage>) => {
+    notification.error({
+      key: 'space-settings-error',
+      message,
+      description: map(resp.data.message, (value, key) => value).join('\n')
+    });
+  }
+
+  const handleRename = () => {
+    Space.rename(key, { key: newKey })
+      .then((space: ISpace) => {
+        notification.success({ message: 'Space key changed, the previous key redirects to the space.' });
+        navigate(`/spaces/${space.key}/setting/profile`);
+      })
+      .catch(failure('Change space key failure.'))
+  }
+
+  const handleTransfer = () => {
+    Space.transfer(key, { login })
+      .then(() => {
+        setLogin('');
+        notification.success({ message: 'Space ownership transferred.' });
+      })
+      .catch(failure('Transfer space failure.'))
+  }
+
+  const handleDelete = async () => {
+    try {
+      const { token } = await Space.createDeleteToken(key)
+      await Space.remove(key, token)
+      notification.success({ message: 'Space deleted.' });
+      navigate('/spaces');
+    } catch (error) {
+      const resp = error as AxiosResponse<IErrorMessage>
+      notification.error({
+        key: 'delete-space-error',
+        message: 'Delete space failure.',
+        description: map(resp?.data.message, (value, key) => value).join('\n')
+      });
+    }
+  }
+
   return (
     <>
       <PageHeader
@@ -99,6 +178,14 @@ const EditSpace = observer(() => {
           </Radio.Group>
         </Form.Item>
 
+        <Form.Item name="visibility" label="Visibility" initialValue={space.visibility || 'public'} extra="Who can read the space on the website">
+          <Radio.Group>
+            <Radio value="public">Public</Radio>
+            <Radio value="internal">Internal</Radio>
+            <Radio value="private">Private</Radio>
+          </Radio.Group>
+        </Form.Item>
+
         <Form.Item wrapperCol={{ offset: 4, span: 18 }}>
           <AntSpace>
             <Button type="primary" htmlType="submit">Submit</Button>
@@ -106,6 +193,83 @@ const EditSpace = observer(() => {
           </AntSpace>
         </Form.Item>
       </Form>
+
+      <Divider orientation="left">Clone</Divider>
+
+      <AntSpace direction="vertical" style={{ marginLeft: 24 }}>
+        <Typography.Text type="secondary">
+          Create a new space with the pages, translations and settings of this space.
+        </Typography.Text>
+        <Button onClick={() => setCloneOpen(true)}>Clone Space</Button>
+      </AntSpace>
+
+      <Modal
+        title="Clone Space"
+        open={cloneOpen}
+        destroyOnClose
+        onOk={() => cloneForm.submit()}
+        onCancel={() => setCloneOpen(false)}
+      >
+        <Form form={cloneForm} preserve={false} layout="vertical" onFinish={handleClone}>
+          <Form.Item name="name" label="Space Name" rules={[{ required: true }]}>
+            <Input />
+          </Form.Item>
+          <Form.Item name="key" label="Space Key" rules={[{ required: true }]}>
+            <Input />
+          </Form.Item>
+        </Form>
+      </Modal>
+
+      <Divider orientation="left">Danger Zone</Divider>
+
+      <AntSpace direction="vertical" style={{ marginLeft: 24 }}>
+        <Typography.Text type="secondary">
+          Changing the key keeps the previous key as a redirect to the space.
+        </Typography.Text>
+        <AntSpace>
+          <Input placeholder="New space key" value={newKey} onChange={(e) => setNewKey(e.target.value)} style={{ width: 240 }} />
+          <Popconfirm
+            title={`Change the space key to "${newKey}"?`}
+            disabled={!newKey}
+            onConfirm={handleRename}
+          >
+            <Button disabled={!newKey}>Change Key</Button>
+          </Popconfirm>
+        </AntSpace>
+
+        <Typography.Text type="secondary">
+          The new owner gets the owner role, the previous owner stays as a maintainer.
+        </Typography.Text>
+        <AntSpace>
+          <Input placeholder="Account login" value={login} onChange={(e) => setLogin(e.target.value)} style={{ width: 240 }} />
+          <Popconfirm
+            title={`Transfer the space to "${login}"?`}
+            disabled={!login}
+            onConfirm={handleTransfer}
+          >
+            <Button disabled={!login}>Transfer Ownership</Button>
+          </Popconfirm>
+        </AntSpace>
+
+        <Typography.Text type="secondary">
+          Archived spaces are read-only and hidden from the website.
+        </Typography.Text>
+        <AntSpace>
+          <Popconfirm
+            title={space.archived_at > 0 ? 'Restore this space?' : 'Archive this space?'}
+            onConfirm={handleArchive}
+          >
+            <Button>{space.archived_at > 0 ? 'Restore Space' : 'Archive Space'}</Button>
+          </Popconfirm>
+          <Popconfirm
+            title={`Delete the space "${space.name}"? This cannot be undone.`}
+            okButtonProps={{ danger: true }}
+            onConfirm={handleDelete}
+          >
+            <Button danger>Delete Space</Button>
+          </Popconfirm>
+        </AntSpace>
+      </AntSpace>
     </>
   );
 })
@@ -0,0 +1,134 @@
+import { useState } from "react";
+import { observer } from "mobx-react-lite";
+import { Link } from "react-router-dom";
+import { useQuery } from "@tanstack/react-query";
+import { map } from "lodash";
+import dayjs from "dayjs";
+import { Alert, Button, Checkbox, notification, Select, Space, Table, Tag } from "antd";
+import { ColumnsType } from "antd/es/table";
+import { PageHeader } from '@ant-design/pro-components';
+
+import { ILinkCheck, ILinkIssue, LinkCheckStatus } from "models";
+import { AxiosResponse, IErrorMessage, IPagination, LinkCheck, PaginationDefault } from "services";
+
+import { useSpaceContext } from "../Detail/store";
+
+const finished = (check?: ILinkCheck) => check?.status === LinkCheckStatus.completed || check?.status === LinkCheckStatus.failed;
+
+const LinkChecks = observer(() => {
+  const { space } = useSpaceContext();
+
+  const [external, setExternal] = useState(false);
+  const [checkID, setCheckID] = useState<number>();
+
+  const { data: pagination, refetch } = useQuery<IPagination<ILinkCheck>>(['spaces.linkcheck', space.key], () => LinkCheck.list(space.key, { page_size: 20 }), {
+    initialData: PaginationDefault,
+    onSuccess(data) {
+      if (!checkID && data.items?.length) {
+        setCheckID(data.items[0].id);
+      }
+    },
+  })
+
+  const { data: check, isLoading } = useQuery<ILinkCheck>(['spaces.linkcheck.get', space.key, checkID], () => LinkCheck.get(space.key, checkID!), {
+    enabled: !!checkID,
+    refetchInterval: (data) => finished(data) ? false : 2000,
+    onSuccess(data) {
+      if (finished(data)) {
+        refetch();
+      }
+    },
+  })
+
+  const handleCheck = () => {
+    LinkCheck.create(space.key, { external })
+      .then((check) => {
+        setCheckID(check.id);
+        refetch();
+      })
+      .catch((resp: AxiosResponse<IErrorMessage>) => {
+        notification.error({
+          key: 'space-linkcheck-error',
+          message: 'Check links failure.',
+          description: map(resp.data.message, (value, key) => value).join('\n')
+        });
+      })
+  }
+
+  const columns: ColumnsType<ILinkIssue> = [
+    {
+      title: 'Page',
+      key: 'page',
+      render: (issue: ILinkIssue) => <>
+        <Link to={`/spaces/${space.key}/pages/${issue.page_id}?lang=${issue.lang}`}>{issue.title}</Link> <Tag>{issue.lang}</Tag>
+      </>
+    },
+    {
+      title: 'Kind',
+      dataIndex: 'kind',
+      width: 120,
+      render: (kind: string) => <Tag color="red">{kind}</Tag>
+    },
+    {
+      title: 'Link',
+      dataIndex: 'link',
+      ellipsis: true,
+    },
+    {
+      title: 'Message',
+      dataIndex: 'message',
+      width: 240,
+    },
+  ];
+
+  return (
+    <>
+      <PageHeader
+        ghost={false}
+        breadcrumb={{
+          items: [
+            { title: <Link to={`/spaces/${space.key}`}>Space</Link> },
+            { title: 'Link Check' },
+          ]
+        }}
+        extra={
+          <Space>
+            <Select
+              style={{ width: 240 }}
+              value={checkID}
+              placeholder="Previous checks"
+              options={pagination?.items?.map((check) => ({
+                value: check.id,
+                label: `${dayjs.unix(check.created_at).format('YYYY-MM-DD HH:mm')} ${check.status}`,
+              }))}
+              onChange={setCheckID}
+            />
+            <Checkbox checked={external} onChange={(e) => setExternal(e.target.checked)}>External URLs</Checkbox>
+            <Button type="primary" loading={!!check && !finished(check)} onClick={handleCheck}>Check Links</Button>
+          </Space>
+        }
+      />
+
+      {check?.error && <Alert type="error" message={check.error} style={{ marginBottom: 16 }} />}
+
+      {
+        check && finished(check) &&
+        <Alert
+          type={check.issues?.length ? 'warning' : 'success'}
+          message={`${check.contents} page contents, ${check.links} links checked, ${check.issues?.length || 0} broken links found`}
+          style={{ marginBottom: 16 }}
+        />
+      }
+
+      <Table<ILinkIssue>
+        rowKey={(issue) => `${issue.content_id}-${issue.link}`}
+        loading={isLoading && !!checkID}
+        columns={columns}
+        dataSource={check?.issues || []}
+        pagination={{ pageSize: 50 }}
+      />
+    </>
+  );
+})
+
+export default LinkChecks
@@ -0,0 +1,115 @@
+import { observer } from "mobx-react-lite";
+import { Link } from "react-router-dom";
+import { useQuery } from "@tanstack/react-query";
+import { map } from "lodash";
+import { Avatar, Button, Form, Input, notification, Popconfirm, Select, Table } from "antd";
+import { ColumnsType } from "antd/es/table";
+import { PageHeader } from '@ant-design/pro-components';
+
+import { IMember, MemberRole } from "models";
+import { AxiosResponse, IErrorMessage, IPagination, Member, PaginationDefault } from "services";
+
+import { useSpaceContext } from "../Detail/store";
+
+const roles = [MemberRole.viewer, MemberRole.editor, MemberRole.maintainer, MemberRole.owner];
+
+const Members = observer(() => {
+  const { space } = useSpaceContext();
+
+  const [form] = Form.useForm();
+
+  const {
+    isLoading,
+    data: pagination,
+    refetch,
+  } = useQuery<IPagination<IMember>>(['spaces.members', space.key], () => Member.list(space.key, { page_size: 1000 }), {
+    initialData: PaginationDefault,
+  })
+
+  const failure = (message: string) => (resp: AxiosResponse<IErrorMessage>) => {
+    notification.error({
+      key: 'space-member-error',
+      message,
+      description: map(resp.data.message, (value, key) => value).join('\n')
+    });
+  }
+
+  const handleFormFinish = (values: { login: string, role: MemberRole }) => {
+    Member.create(space.key, values)
+      .then(() => {
+        form.resetFields();
+        refetch();
+      })
+      .catch(failure('Add member failure.'))
+  }
+
+  const columns: ColumnsType<IMember> = [
+    {
+      title: 'Account',
+      key: 'account',
+      render: (member: IMember) => <>
+        <Avatar size={24} src={member.account?.avatar} style={{ marginRight: 8 }} />
+        {member.account?.name || member.account?.login}
+      </>
+    },
+    {
+      title: 'Role',
+      dataIndex: 'role',
+      width: 200,
+      render: (role: MemberRole, member: IMember) =>
+        <Select
+          value={role}
+          style={{ width: 160 }}
+          options={roles.map((role) => ({ value: role, label: role }))}
+          onChange={(role) => Member.update(space.key, member.account_id, { role }).then(() => refetch()).catch(failure('Update member failure.'))}
+        />
+    },
+    {
+      key: 'actions',
+      width: 100,
+      render: (member: IMember) =>
+        <Popconfirm
+          title="Remove this member?"
+          onConfirm={() => Member.remove(space.key, member.account_id).then(() => refetch()).catch(failure('Remove member failure.'))}
+        >
+          <Button type="link" danger>Remove</Button>
+        </Popconfirm>
+    },
+  ];
+
+  return (
+    <>
+      <PageHeader
+        ghost={false}
+        breadcrumb={{
+          items: [
+            { title: <Link to={`/spaces/${space.key}`}>Space</Link> },
+            { title: 'Members' },
+          ]
+        }}
+      />
+
+      <Form form={form} layout="inline" style={{ marginBottom: 16 }} onFinish={handleFormFinish}>
+        <Form.Item name="login" rules={[{ required: true }]}>
+          <Input placeholder="Account login" />
+        </Form.Item>
+        <Form.Item name="role" initialValue={MemberRole.editor}>
+          <Select style={{ width: 160 }} options={roles.map((role) => ({ value: role, label: role }))} />
+        </Form.Item>
+        <Form.Item>
+          <Button type="primary" htmlType="submit">Add Member</Button>
+        </Form.Item>
+      </Form>
+
+      <Table<IMember>
+        rowKey="id"
+        loading={isLoading}
+        columns={columns}
+        dataSource={pagination?.items}
+        pagination={false}
+      />
+    </>
+  );
+})
+
+export default Members
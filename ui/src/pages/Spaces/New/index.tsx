@@ -1,11 +1,12 @@
 import React, { useState } from 'react';
 import { observer } from 'mobx-react-lite';
 import { Link, useNavigate } from 'react-router-dom';
+import { useQuery } from '@tanstack/react-query';
 import { map } from 'lodash';
 import { Button, Form, Input, Layout, notification, Radio, Select, Space as AntSpace, Switch } from 'antd';
 import { PageHeader } from '@ant-design/pro-components';
 
-import { ISpace } from 'models';
+import { ISpace, ISpaceTemplate } from 'models';
 import { AxiosResponse, Space, IErrorMessage } from "services";
 
 const NewSpace = observer(() => {
@@ -14,7 +15,24 @@ const NewSpace = observer(() => {
   const [form] = Form.useForm();
   const [multilingual, setMultilingual] = useState(false);
 
-  const handleFormFinish = async (values: Partial<ISpace>) => {
+  const { data: templates } = useQuery<ISpaceTemplate[]>(['spaces.templates'], () => Space.templates(), {
+    initialData: [],
+  })
+
+  const handleTemplateChange = (name?: string) => {
+    const template = templates?.find((template) => template.name === name);
+    if (template) {
+      setMultilingual(template.multilingual);
+      form.setFieldsValue({
+        multilingual:  template.multilingual,
+        lang:          template.lang || undefined,
+        fallback_lang: template.fallback_lang || undefined,
+        visibility:    template.visibility || undefined,
+      });
+    }
+  }
+
+  const handleFormFinish = async (values: Partial<ISpace> & { template?: string }) => {
     Space.create(values)
       .then((space: ISpace) => {
         navigate(`/spaces/${space.key}`);
@@ -44,7 +62,7 @@ const NewSpace = observer(() => {
           }}
         />
 
-        <Form<Partial<ISpace>>
+        <Form<Partial<ISpace> & { template?: string }>
           name="space-form"
           form={form}
           preserve={false}
@@ -70,6 +88,20 @@ const NewSpace = observer(() => {
             <Input />
           </Form.Item>
 
+          <Form.Item
+            name="template"
+            label="Template"
+            extra="Start with the page tree and settings of a packaged template."
+          >
+            <Select
+              allowClear
+              placeholder="Blank space"
+              style={{ width: 320 }}
+              onChange={handleTemplateChange}
+              options={templates?.map((template) => ({ value: template.name, label: template.title || template.name, title: template.description }))}
+            />
+          </Form.Item>
+
           <Form.Item
             name="description"
             label="Description"
@@ -102,6 +134,14 @@ const NewSpace = observer(() => {
             </Radio.Group>
           </Form.Item>
 
+          <Form.Item name="visibility" label="Visibility" initialValue="public" extra="Who can read the space on the website">
+            <Radio.Group>
+              <Radio value="public">Public</Radio>
+              <Radio value="internal">Internal</Radio>
+              <Radio value="private">Private</Radio>
+            </Radio.Group>
+          </Form.Item>
+
           <Form.Item wrapperCol={{ offset: 4, span: 18 }}>
             <AntSpace>
               <Button type="primary" htmlType="submit">Submit</Button>
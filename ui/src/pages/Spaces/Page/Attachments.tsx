@@ -0,0 +1,119 @@
+import { useQuery } from "@tanstack/react-query";
+import { map } from "lodash";
+import { Button, notification, Popconfirm, Table, Typography, Upload } from "antd";
+import { ColumnsType } from "antd/es/table";
+
+import { IAttachment } from "models";
+import { Attachment, AxiosResponse, IErrorMessage } from "services";
+
+interface AttachmentsProps {
+  spaceKey: string
+  pageID:   number
+}
+
+// formatSize human readable file size
+function formatSize(size: number): string {
+  if (size < 1024) {
+    return `${size} B`
+  }
+  if (size < 1024 * 1024) {
+    return `${(size / 1024).toFixed(1)} KB`
+  }
+  return `${(size / 1024 / 1024).toFixed(1)} MB`
+}
+
+// Attachments page attachment files, referenced in markdown with the `attachment:` urls
+const Attachments = ({ spaceKey, pageID }: AttachmentsProps) => {
+
+  const {
+    isLoading,
+    data: attachments,
+    refetch,
+  } = useQuery<IAttachment[]>(['spaces.pages.attachments', spaceKey, pageID], () => Attachment.list(spaceKey, pageID), {
+    initialData: [],
+  })
+
+  const failure = (message: string) => (resp: AxiosResponse<IErrorMessage>) => {
+    notification.error({
+      key: 'page-attachment-error',
+      message,
+      description: map(resp.data.message, (value, key) => value).join('\n')
+    });
+  }
+
+  const handleUpload = (file: File) => {
+    Attachment.upload(spaceKey, pageID, file)
+      .then(() => refetch())
+      .catch(failure('Upload attachment failure.'))
+
+    // uploaded by the service instead of the Upload component
+    return false
+  }
+
+  const columns: ColumnsType<IAttachment> = [
+    {
+      key: 'thumbnail',
+      width: 64,
+      render: (attachment: IAttachment) => attachment.variants?.some((variant) => variant.name === 'thumbnail')
+        ? <img src={`${attachment.url}?v=thumbnail`} alt={attachment.name} style={{ maxWidth: 48, maxHeight: 48 }} />
+        : null
+    },
+    {
+      title: 'Name',
+      key: 'name',
+      render: (attachment: IAttachment) => <a href={attachment.url} target="_blank" rel="noreferrer">{attachment.name}</a>
+    },
+    {
+      title: 'Size',
+      key: 'size',
+      width: 120,
+      render: (attachment: IAttachment) => <>
+        {formatSize(attachment.size)}
+        {attachment.width ? <Typography.Text type="secondary"><br />{attachment.width} × {attachment.height}</Typography.Text> : null}
+      </>
+    },
+    {
+      title: 'Markdown',
+      key: 'reference',
+      render: (attachment: IAttachment) =>
+        <Typography.Text code copyable>
+          {attachment.content_type.startsWith('image/')
+            ? `![${attachment.name}](${attachment.reference})`
+            : `[${attachment.name}](${attachment.reference})`}
+        </Typography.Text>
+    },
+    {
+      key: 'actions',
+      width: 100,
+      render: (attachment: IAttachment) =>
+        <Popconfirm
+          title="Delete this attachment?"
+          onConfirm={() => Attachment.remove(spaceKey, pageID, attachment.id).then(() => refetch()).catch(failure('Delete attachment failure.'))}
+        >
+          <Button type="link" danger>Delete</Button>
+        </Popconfirm>
+    },
+  ];
+
+  return (
+    <>
+      <Typography.Paragraph type="secondary">
+        Reference the attachments in the page markdown with the copied links.
+      </Typography.Paragraph>
+
+      <Upload beforeUpload={handleUpload} showUploadList={false} multiple>
+        <Button style={{ marginBottom: 16 }}>Upload</Button>
+      </Upload>
+
+      <Table<IAttachment>
+        rowKey="id"
+        loading={isLoading}
+        columns={columns}
+        dataSource={attachments}
+        pagination={false}
+      />
+    </>
+  );
+}
+
+export default Attachments
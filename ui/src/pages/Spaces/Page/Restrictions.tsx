@@ -0,0 +1,99 @@
+import { useQuery } from "@tanstack/react-query";
+import { map } from "lodash";
+import { Avatar, Button, Form, Input, notification, Popconfirm, Select, Table, Typography } from "antd";
+import { ColumnsType } from "antd/es/table";
+
+import { IPageRestriction, MemberRole } from "models";
+import { AxiosResponse, IErrorMessage, Restriction } from "services";
+
+const roles = [MemberRole.viewer, MemberRole.editor, MemberRole.maintainer, MemberRole.owner];
+
+interface RestrictionsProps {
+  spaceKey: string
+  pageID:   number
+}
+
+// Restrictions page read restrictions, inherited by the descendant pages
+const Restrictions = ({ spaceKey, pageID }: RestrictionsProps) => {
+
+  const [form] = Form.useForm();
+
+  const {
+    isLoading,
+    data: restrictions,
+    refetch,
+  } = useQuery<IPageRestriction[]>(['spaces.pages.restrictions', spaceKey, pageID], () => Restriction.list(spaceKey, pageID), {
+    initialData: [],
+  })
+
+  const failure = (message: string) => (resp: AxiosResponse<IErrorMessage>) => {
+    notification.error({
+      key: 'page-restriction-error',
+      message,
+      description: map(resp.data.message, (value, key) => value).join('\n')
+    });
+  }
+
+  const handleFormFinish = (values: { login?: string, role?: MemberRole }) => {
+    Restriction.create(spaceKey, pageID, values.login ? { login: values.login } : { role: values.role })
+      .then(() => {
+        form.resetFields();
+        refetch();
+      })
+      .catch(failure('Add restriction failure.'))
+  }
+
+  const columns: ColumnsType<IPageRestriction> = [
+    {
+      title: 'Readable by',
+      key: 'subject',
+      render: (restriction: IPageRestriction) => restriction.account_id > 0
+        ? <>
+          <Avatar size={24} src={restriction.account?.avatar} style={{ marginRight: 8 }} />
+          {restriction.account?.name || restriction.account?.login}
+        </>
+        : <>Members with role <Typography.Text code>{restriction.role}</Typography.Text> or above</>
+    },
+    {
+      key: 'actions',
+      width: 100,
+      render: (restriction: IPageRestriction) =>
+        <Popconfirm
+          title="Remove this restriction?"
+          onConfirm={() => Restriction.remove(spaceKey, pageID, restriction.id).then(() => refetch()).catch(failure('Remove restriction failure.'))}
+        >
+          <Button type="link" danger>Remove</Button>
+        </Popconfirm>
+    },
+  ];
+
+  return (
+    <>
+      <Typography.Paragraph type="secondary">
+        Restricted pages and their descendants are readable only by the accounts and roles below.
+      </Typography.Paragraph>
+
+      <Form form={form} layout="inline" style={{ marginBottom: 16 }} onFinish={handleFormFinish}>
+        <Form.Item name="login">
+          <Input placeholder="Account login" />
+        </Form.Item>
+        <Form.Item name="role">
+          <Select allowClear placeholder="or role" style={{ width: 160 }} options={roles.map((role) => ({ value: role, label: role }))} />
+        </Form.Item>
+        <Form.Item>
+          <Button type="primary" htmlType="submit">Add</Button>
+        </Form.Item>
+      </Form>
+
+      <Table<IPageRestriction>
+        rowKey="id"
+        loading={isLoading}
+        columns={columns}
+        dataSource={restrictions}
+        pagination={false}
+      />
+    </>
+  );
+}
+
+export default Restrictions
@@ -1,15 +1,18 @@
+import { useState } from "react";
 import { observer } from "mobx-react-lite";
 import { Link, useParams } from "react-router-dom";
 import { useQuery } from "@tanstack/react-query";
 import { StringParam, useQueryParams, withDefault } from "use-query-params";
 import dayjs from "dayjs";
-import { Breadcrumb, Button, Col, Empty, Row, Select, Skeleton, Space, Tag, Typography } from "antd";
+import { Breadcrumb, Button, Col, Divider, Empty, List, Modal, Row, Select, Skeleton, Space, Tag, Typography } from "antd";
 import { PageHeader } from "@ant-design/pro-components";
 
 import { IPage } from 'models';
 import { Page } from "services";
 
 import { useSpaceContext } from "../Detail/store";
+import Restrictions from "./Restrictions";
+import Attachments from "./Attachments";
 import { AiOutlineEdit, AiOutlineFileAdd } from "react-icons/ai";
 
 const PageDetail = observer(() => {
@@ -19,6 +22,9 @@ const PageDetail = observer(() => {
 
   const { page_id } = useParams() as { page_id: string };
 
+  const [restrictionsOpen, setRestrictionsOpen] = useState(false);
+  const [attachmentsOpen, setAttachmentsOpen] = useState(false);
+
   const [query] = useQueryParams({
     lang: withDefault(StringParam, space.lang),
   });
@@ -33,6 +39,10 @@ const PageDetail = observer(() => {
     },
   })
 
+  const { data: backlinks } = useQuery<IPage[]>(['spaces.pages.backlinks', page?.id], () => Page.backlinks(space.key, page!.id), {
+    enabled: !!page,
+  })
+
   if (isLoading) {
     return <Skeleton active />;
   }
@@ -56,6 +66,8 @@ const PageDetail = observer(() => {
         <Col>
           <Space>
             <Link to={`/spaces/${space.key}/pages/${page.id}/edit`}><Button>Edit</Button></Link>
+            <Button onClick={() => setAttachmentsOpen(true)}>Attachments</Button>
+            <Button onClick={() => setRestrictionsOpen(true)}>Restrictions</Button>
 
             {
               space.multilingual &&
@@ -85,6 +97,45 @@ const PageDetail = observer(() => {
       </PageHeader>
 
       <div className="page-content" dangerouslySetInnerHTML={{ __html: page.html || '' }} />
+
+      {
+        backlinks && backlinks.length > 0 &&
+        <>
+          <Divider orientation="left">Linked from</Divider>
+          <List
+            size="small"
+            dataSource={backlinks}
+            renderItem={(backlink) => (
+              <List.Item>
+                <Link to={`/spaces/${backlink.space?.key}/pages/${backlink.id}`}>{backlink.title}</Link>
+                {backlink.space?.key !== space.key && <Tag style={{ marginLeft: 8 }}>{backlink.space?.name}</Tag>}
+              </List.Item>
+            )}
+          />
+        </>
+      }
+
+      <Modal
+        title="Restrictions"
+        open={restrictionsOpen}
+        footer={null}
+        width={720}
+        destroyOnClose
+        onCancel={() => setRestrictionsOpen(false)}
+      >
+        <Restrictions spaceKey={space.key} pageID={page.id} />
+      </Modal>
+
+      <Modal
+        title="Attachments"
+        open={attachmentsOpen}
+        footer={null}
+        width={720}
+        destroyOnClose
+        onCancel={() => setAttachmentsOpen(false)}
+      >
+        <Attachments spaceKey={space.key} pageID={page.id} />
+      </Modal>
     </>
   );
 })
@@ -0,0 +1,110 @@
+import { observer } from "mobx-react-lite";
+import { Link, useParams } from "react-router-dom";
+import { map } from "lodash";
+import { Button, Form, Input, notification, Space as AntSpace } from "antd";
+import { PageHeader } from '@ant-design/pro-components';
+import { AiOutlineMinusCircle, AiOutlinePlus } from "react-icons/ai";
+
+import { ISpaceSettings } from 'models';
+import { AxiosResponse, IErrorMessage } from "services";
+
+import { useSpaceContext } from "../Detail/store";
+
+const SpaceSettings = observer(() => {
+  const store = useSpaceContext();
+  const { space } = store;
+  const settings = space.settings;
+
+  const { key } = useParams() as { key: string };
+
+  const [form] = Form.useForm();
+
+  const handleFormFinish = (values: Partial<ISpaceSettings>) => {
+    store.updateSettings(key, { ...values, nav_links: values.nav_links || [] })
+      .then(() => {
+        notification.success({ message: 'Update website settings success.' });
+      })
+      .catch((resp: AxiosResponse<IErrorMessage>) => {
+        notification.error({
+          key: 'update-space-settings-error',
+          message: 'Update website settings failure.',
+          description: map(resp.data.message, (value, key) => value).join('\n')
+        });
+      })
+  }
+
+  return (
+    <>
+      <PageHeader
+        ghost={false}
+        breadcrumb={{
+          items: [
+            { title: <Link to={`/spaces/${space.key}`}>Space</Link> },
+            { title: 'Website' },
+          ]
+        }}
+      />
+
+      <Form<Partial<ISpaceSettings>>
+        name="space-settings-form"
+        form={form}
+        preserve={false}
+        layout="horizontal"
+        labelCol={{ span: 4 }}
+        wrapperCol={{ span: 18 }}
+        onFinish={handleFormFinish}
+      >
+        <Form.Item name="logo" label="Logo" initialValue={settings?.logo} extra="Image url shown in the website header">
+          <Input placeholder="https://example.com/logo.png" />
+        </Form.Item>
+
+        <Form.Item name="accent_color" label="Accent Color" initialValue={settings?.accent_color} extra="Hex color, e.g. #0069ff">
+          <Input style={{ width: 200 }} placeholder="#0069ff" />
+        </Form.Item>
+
+        <Form.Item label="Navigation Links">
+          <Form.List name="nav_links" initialValue={settings?.nav_links || []}>
+            {(fields, { add, remove }) => (
+              <>
+                {fields.map(({ key, name, ...field }) => (
+                  <AntSpace key={key} align="baseline">
+                    <Form.Item {...field} name={[name, 'title']} rules={[{ required: true }]}>
+                      <Input placeholder="Title" />
+                    </Form.Item>
+                    <Form.Item {...field} name={[name, 'url']} rules={[{ required: true }]}>
+                      <Input placeholder="https://example.com or /docs/other" style={{ width: 320 }} />
+                    </Form.Item>
+                    <AiOutlineMinusCircle onClick={() => remove(name)} />
+                  </AntSpace>
+                ))}
+                <Button type="dashed" onClick={() => add()} icon={<AiOutlinePlus />}>Add Link</Button>
+              </>
+            )}
+          </Form.List>
+        </Form.Item>
+
+        <Form.Item name="footer" label="Footer" initialValue={settings?.footer} extra="Markdown rendered at the bottom of the website pages">
+          <Input.TextArea autoSize={{ minRows: 3 }} />
+        </Form.Item>
+
+        <Form.Item
+          name="edit_url"
+          label="Edit Link"
+          initialValue={settings?.edit_url}
+          extra="Placeholders: {space}, {lang}, {id} and {path}, e.g. https://github.com/org/repo/edit/main/docs/{path}.md"
+        >
+          <Input />
+        </Form.Item>
+
+        <Form.Item wrapperCol={{ offset: 4, span: 18 }}>
+          <AntSpace>
+            <Button type="primary" htmlType="submit">Submit</Button>
+            <Link to={`/spaces/${space.key}`}><Button>Cancel</Button></Link>
+          </AntSpace>
+        </Form.Item>
+      </Form>
+    </>
+  )
+})
+
+export default SpaceSettings;
@@ -0,0 +1,132 @@
+import { observer } from "mobx-react-lite";
+import { Link } from "react-router-dom";
+import { useQuery } from "@tanstack/react-query";
+import { map } from "lodash";
+import { Alert, Button, Form, Input, notification, Popconfirm, Table, Tag, Typography } from "antd";
+import { ColumnsType } from "antd/es/table";
+import { PageHeader } from '@ant-design/pro-components';
+
+import { IVariable } from "models";
+import { AxiosResponse, IErrorMessage, Variable } from "services";
+
+import { useSpaceContext } from "../Detail/store";
+
+const Variables = observer(() => {
+  const { space } = useSpaceContext();
+
+  const [form] = Form.useForm();
+
+  const {
+    isLoading,
+    data: variables,
+    refetch,
+  } = useQuery<IVariable[]>(['spaces.variables', space.key], () => Variable.list(space.key), {
+    initialData: [],
+  })
+
+  const failure = (message: string) => (resp: AxiosResponse<IErrorMessage>) => {
+    notification.error({
+      key: 'space-variable-error',
+      message,
+      description: map(resp.data.message, (value, key) => value).join('\n')
+    });
+  }
+
+  const handleFormFinish = (values: Pick<IVariable, 'version' | 'name' | 'value'>) => {
+    Variable.create(space.key, { ...values, version: values.version || '' })
+      .then(() => {
+        form.resetFields();
+        refetch();
+      })
+      .catch(failure('Add variable failure.'))
+  }
+
+  const handleValueChange = (variable: IVariable, value: string) => {
+    if (value === variable.value) {
+      return;
+    }
+    Variable.update(space.key, variable.id, { value }).then(() => refetch()).catch(failure('Update variable failure.'))
+  }
+
+  const columns: ColumnsType<IVariable> = [
+    {
+      title: 'Name',
+      dataIndex: 'name',
+      width: 240,
+      render: (name: string) => <Typography.Text code copyable={{ text: `{{ var "${name}" }}` }}>{name}</Typography.Text>
+    },
+    {
+      title: 'Version',
+      dataIndex: 'version',
+      width: 140,
+      render: (version: string) => version ? <Tag>{version}</Tag> : <Tag color="blue">All versions</Tag>
+    },
+    {
+      title: 'Value',
+      key: 'value',
+      render: (variable: IVariable) =>
+        <Input
+          key={`${variable.id}-${variable.updated_at}`}
+          defaultValue={variable.value}
+          onBlur={(e) => handleValueChange(variable, e.target.value)}
+          onPressEnter={(e) => handleValueChange(variable, e.currentTarget.value)}
+        />
+    },
+    {
+      key: 'actions',
+      width: 100,
+      render: (variable: IVariable) =>
+        <Popconfirm
+          title="Remove this variable?"
+          onConfirm={() => Variable.remove(space.key, variable.id).then(() => refetch()).catch(failure('Remove variable failure.'))}
+        >
+          <Button type="link" danger>Remove</Button>
+        </Popconfirm>
+    },
+  ];
+
+  return (
+    <>
+      <PageHeader
+        ghost={false}
+        breadcrumb={{
+          items: [
+            { title: <Link to={`/spaces/${space.key}`}>Space</Link> },
+            { title: 'Variables' },
+          ]
+        }}
+      />
+
+      <Alert
+        type="info"
+        style={{ marginBottom: 16 }}
+        message={<>Reference the variables in the page bodies as <Typography.Text code>{'{{ var "name" }}'}</Typography.Text>, the variables of a version override the variables of all versions, the pages are rendered again when a variable changes.</>}
+      />
+
+      <Form form={form} layout="inline" style={{ marginBottom: 16 }} onFinish={handleFormFinish}>
+        <Form.Item name="name" rules={[{ required: true, pattern: /^[A-Za-z_][A-Za-z0-9_]*$/, message: 'Letters, digits and underscores' }]}>
+          <Input placeholder="Name, e.g. latest_sdk_version" style={{ width: 260 }} />
+        </Form.Item>
+        <Form.Item name="version">
+          <Input placeholder="Version, empty for all" style={{ width: 180 }} />
+        </Form.Item>
+        <Form.Item name="value" rules={[{ required: true }]}>
+          <Input placeholder="Value" style={{ width: 300 }} />
+        </Form.Item>
+        <Form.Item>
+          <Button type="primary" htmlType="submit">Add Variable</Button>
+        </Form.Item>
+      </Form>
+
+      <Table<IVariable>
+        rowKey="id"
+        loading={isLoading}
+        columns={columns}
+        dataSource={variables}
+        pagination={false}
+      />
+    </>
+  );
+})
+
+export default Variables
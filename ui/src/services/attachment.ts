@@ -0,0 +1,17 @@
+import { DELETE, GET, POST } from './lib/http';
+
+import { IAttachment } from 'models';
+
+export function list(spaceKey: string, pageID: number): Promise<IAttachment[]> {
+  return GET(`/spaces/${spaceKey}/pages/${pageID}/attachments`)
+}
+
+export function upload(spaceKey: string, pageID: number, file: File): Promise<IAttachment> {
+  const data = new FormData()
+  data.append('file', file)
+  return POST(`/spaces/${spaceKey}/pages/${pageID}/attachments`, data)
+}
+
+export function remove(spaceKey: string, pageID: number, id: number): Promise<void> {
+  return DELETE(`/spaces/${spaceKey}/pages/${pageID}/attachments/${id}`)
+}
@@ -38,3 +38,9 @@ export * as Account from './account';
 export * as Space from './space';
 export * as Page from './page';
 export * as Markdown from './markdown';
+export * as Redirect from './redirect';
+export * as Member from './member';
+export * as Restriction from './restriction';
+export * as Attachment from './attachment';
+export * as LinkCheck from './linkcheck';
+export * as Variable from './variable';
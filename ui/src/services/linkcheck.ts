@@ -0,0 +1,16 @@
+import { GET, POST } from './lib/http';
+import { IPagination, IPaginationQuery } from './pagination';
+
+import { ILinkCheck } from 'models';
+
+export function create(spaceKey: string, args: { external: boolean }): Promise<ILinkCheck> {
+  return POST(`/spaces/${spaceKey}/linkcheck`, args)
+}
+
+export function list(spaceKey: string, params?: IPaginationQuery): Promise<IPagination<ILinkCheck>> {
+  return GET(`/spaces/${spaceKey}/linkcheck`, { params })
+}
+
+export function get(spaceKey: string, id: number): Promise<ILinkCheck> {
+  return GET(`/spaces/${spaceKey}/linkcheck/${id}`)
+}
@@ -1,5 +1,13 @@
 import { POST } from "./lib/http";
 
-export function preview(content: string): Promise<string> {
-  return POST(`/markdown/preview`, { content })
+export interface IPreviewArgs {
+  page_id?:   number
+  parent_id?: number
+  lang?:      string
+  version?:   string
+}
+
+// the variables and the includes of the space resolve as on save
+export function preview(spaceKey: string, content: string, args?: IPreviewArgs): Promise<string> {
+  return POST(`/spaces/${spaceKey}/markdown/preview`, { content, ...args })
 }
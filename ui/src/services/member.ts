@@ -0,0 +1,25 @@
+import { DELETE, GET, PATCH, POST } from './lib/http';
+import { Nullish } from './lib/types';
+import { IPagination, IPaginationQuery } from './pagination';
+
+import { IMember, MemberRole } from 'models';
+
+export interface IListMembersArgs extends IPaginationQuery {
+  role?: MemberRole | Nullish
+}
+
+export function list(spaceKey: string, params?: IListMembersArgs): Promise<IPagination<IMember>> {
+  return GET(`/spaces/${spaceKey}/members`, { params })
+}
+
+export function create(spaceKey: string, args: { login: string, role: MemberRole }): Promise<IMember> {
+  return POST(`/spaces/${spaceKey}/members`, args)
+}
+
+export function update(spaceKey: string, accountID: number, args: { role: MemberRole }): Promise<IMember> {
+  return PATCH(`/spaces/${spaceKey}/members/${accountID}`, args)
+}
+
+export function remove(spaceKey: string, accountID: number): Promise<void> {
+  return DELETE(`/spaces/${spaceKey}/members/${accountID}`)
+}
@@ -1,7 +1,7 @@
 import { GET, PATCH, POST } from './lib/http';
 import { Nullish } from './lib/types';
 
-import { IPage, PageStatus } from 'models';
+import { IPage, IPageCursor, PageStatus } from 'models';
 
 export interface ICreatePageArgs {
   parent_id?:        number
@@ -10,6 +10,7 @@ export interface ICreatePageArgs {
   version:           string
   status:            PageStatus
   short_title?:      string
+  slug?:             string
   body:              string
 }
 
@@ -26,6 +27,19 @@ export function list(spaceKey: string, params?: IGetPageParams): Promise<IPage[]
   return GET(`/spaces/${spaceKey}/pages`, { params })
 }
 
+export interface IPageTreeParams {
+  lang?:      string | Nullish
+  version?:   string | Nullish
+  parent_id?: number | Nullish
+  after?:     number | Nullish
+  limit?:     number | Nullish
+  expand?:    number | string | Nullish
+}
+
+export function tree(spaceKey: string, params?: IPageTreeParams): Promise<IPageCursor> {
+  return GET(`/spaces/${spaceKey}/tree`, { params })
+}
+
 export function get(spaceKey: string, id: string, params?: IGetPageParams): Promise<IPage> {
   return GET(`/spaces/${spaceKey}/pages/${id}`, { params })
 }
@@ -35,3 +49,16 @@ export type IUpdatePageArgs = ICreatePageArgs;
 export function update(spaceKey: string, id: string, args: IUpdatePageArgs): Promise<IPage> {
   return PATCH(`/spaces/${spaceKey}/pages/${id}`, args)
 }
+
+export interface ICopyPageArgs {
+  space_key?: string
+  parent_id?: number
+}
+
+export function copy(spaceKey: string, id: string, args: ICopyPageArgs): Promise<IPage> {
+  return POST(`/spaces/${spaceKey}/pages/${id}/copy`, args)
+}
+
+export function backlinks(spaceKey: string, id: number): Promise<IPage[]> {
+  return GET(`/spaces/${spaceKey}/pages/${id}/backlinks`)
+}
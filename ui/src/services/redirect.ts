@@ -0,0 +1,25 @@
+import { DELETE, GET, PATCH, POST } from './lib/http';
+import { Nullish } from './lib/types';
+import { IPagination, IPaginationQuery } from './pagination';
+
+import { IRedirect } from 'models';
+
+export interface IListRedirectsArgs extends IPaginationQuery {
+  q?: string | Nullish
+}
+
+export function list(spaceKey: string, params?: IListRedirectsArgs): Promise<IPagination<IRedirect>> {
+  return GET(`/spaces/${spaceKey}/redirects`, { params })
+}
+
+export function create(spaceKey: string, args: Pick<IRedirect, 'path' | 'page_id'>): Promise<IRedirect> {
+  return POST(`/spaces/${spaceKey}/redirects`, args)
+}
+
+export function update(spaceKey: string, id: number, args: Partial<Pick<IRedirect, 'path' | 'page_id'>>): Promise<IRedirect> {
+  return PATCH(`/spaces/${spaceKey}/redirects/${id}`, args)
+}
+
+export function remove(spaceKey: string, id: number): Promise<void> {
+  return DELETE(`/spaces/${spaceKey}/redirects/${id}`)
+}
@@ -0,0 +1,15 @@
+import { DELETE, GET, POST } from './lib/http';
+
+import { IPageRestriction, MemberRole } from 'models';
+
+export function list(spaceKey: string, pageID: number): Promise<IPageRestriction[]> {
+  return GET(`/spaces/${spaceKey}/pages/${pageID}/restrictions`)
+}
+
+export function create(spaceKey: string, pageID: number, args: { login?: string, role?: MemberRole }): Promise<IPageRestriction> {
+  return POST(`/spaces/${spaceKey}/pages/${pageID}/restrictions`, args)
+}
+
+export function remove(spaceKey: string, pageID: number, id: number): Promise<void> {
+  return DELETE(`/spaces/${spaceKey}/pages/${pageID}/restrictions/${id}`)
+}
@@ -1,8 +1,8 @@
-import { GET, PATCH, POST } from './lib/http';
+import { DELETE, GET, PATCH, POST } from './lib/http';
 import { Nullish } from './lib/types';
 import { IPagination, IPaginationQuery } from './pagination';
 
-import { ISpace } from 'models';
+import { ISpace, ISpaceSettings, ISpaceStats, ISpaceTemplate } from 'models';
 
 export interface IListSpacesArgs extends IPaginationQuery {
   q?: string | Nullish
@@ -12,10 +12,18 @@ export function list(params?: IListSpacesArgs): Promise<IPagination<ISpace>> {
   return GET('/spaces', { params })
 }
 
-export function create(args: Partial<ISpace>): Promise<ISpace> {
+export function create(args: Partial<ISpace> & { template?: string }): Promise<ISpace> {
   return POST('/spaces', args)
 }
 
+export function templates(): Promise<ISpaceTemplate[]> {
+  return GET('/spaces/templates')
+}
+
+export function clone(key: string, args: { name: string, key: string }): Promise<ISpace> {
+  return POST(`/spaces/${key}/clone`, args)
+}
+
 export function get(key: string): Promise<ISpace> {
   return GET(`/spaces/${key}`)
 }
@@ -23,3 +31,41 @@ export function get(key: string): Promise<ISpace> {
 export function update(key: string, args: Partial<ISpace>): Promise<ISpace> {
   return PATCH(`/spaces/${key}`, args)
 }
+
+export function updateSettings(key: string, args: Partial<ISpaceSettings>): Promise<ISpace> {
+  return PATCH(`/spaces/${key}/settings`, args)
+}
+
+export interface ISpaceStatsArgs {
+  days?:       number
+  stale_days?: number
+  limit?:      number
+}
+
+export function stats(key: string, params?: ISpaceStatsArgs): Promise<ISpaceStats> {
+  return GET(`/spaces/${key}/stats`, { params })
+}
+
+export function archive(key: string): Promise<ISpace> {
+  return POST(`/spaces/${key}/archive`)
+}
+
+export function restore(key: string): Promise<ISpace> {
+  return POST(`/spaces/${key}/restore`)
+}
+
+export function rename(key: string, args: { key: string }): Promise<ISpace> {
+  return POST(`/spaces/${key}/rename`, args)
+}
+
+export function transfer(key: string, args: { login: string }): Promise<ISpace> {
+  return POST(`/spaces/${key}/transfer`, args)
+}
+
+export function createDeleteToken(key: string): Promise<{ token: string }> {
+  return POST(`/spaces/${key}/delete_token`)
+}
+
+export function remove(key: string, token: string): Promise<void> {
+  return DELETE(`/spaces/${key}`, { params: { token } })
+}
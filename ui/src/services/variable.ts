@@ -0,0 +1,19 @@
+import { DELETE, GET, PATCH, POST } from './lib/http';
+
+import { IVariable } from 'models';
+
+export function list(spaceKey: string): Promise<IVariable[]> {
+  return GET(`/spaces/${spaceKey}/variables`)
+}
+
+export function create(spaceKey: string, args: Pick<IVariable, 'version' | 'name' | 'value'>): Promise<IVariable> {
+  return POST(`/spaces/${spaceKey}/variables`, args)
+}
+
+export function update(spaceKey: string, id: number, args: Partial<Pick<IVariable, 'name' | 'value'>>): Promise<IVariable> {
+  return PATCH(`/spaces/${spaceKey}/variables/${id}`, args)
+}
+
+export function remove(spaceKey: string, id: number): Promise<void> {
+  return DELETE(`/spaces/${spaceKey}/variables/${id}`)
+}